@@ -5,12 +5,11 @@ import (
 	"strconv"
 
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"go.uber.org/zap"
 )
 
 func UnlockBatchUpdateRequest(ctx context.Context, lockingService common.IDistributedLockingService, locks []common.Lock) {
-	for _, lock := range locks {
-		lockingService.Release(ctx, lock)
-	}
+	lockingService.ReleaseMany(ctx, &locks)
 }
 
 func LockBatchUpdateRequest(
@@ -20,47 +19,17 @@ func LockBatchUpdateRequest(
 	skuList []string,
 	getKey func(string) string,
 ) ([]common.Lock, error) {
-	idLocks, lockErrForIds := lockBatchesByIds(ctx, lockingService, ids, getKey)
-	if lockErrForIds != nil {
-		return idLocks, lockErrForIds
+	keys := make([]string, 0, len(ids)+len(skuList))
+	for _, id := range ids {
+		keys = append(keys, getKey(strconv.Itoa(id)))
 	}
-	skuLocks, lockErrForSkus := lockBatchesBySkus(ctx, lockingService, skuList, getKey)
-	idLocks = append(idLocks, skuLocks...)
-	return idLocks, lockErrForSkus
-}
-
-func lockBatchesBySkus(
-	ctx context.Context,
-	lockingService common.IDistributedLockingService,
-	skus []string,
-	getKey func(string) string,
-) ([]common.Lock, error) {
-	locks := make([]common.Lock, len(skus))
-	for i, sku := range skus {
-		lockKey := getKey(sku)
-		lock, err := lockingService.Acquire(ctx, lockKey)
-		if err != nil {
-			return locks, common.NewBadRequestFromMessage("Failed to acquire lock for sku: " + sku)
-		}
-		locks[i] = lock
+	for _, sku := range skuList {
+		keys = append(keys, getKey(sku))
 	}
-	return locks, nil
-}
-
-func lockBatchesByIds(
-	ctx context.Context,
-	lockingService common.IDistributedLockingService,
-	ids []int,
-	getKey func(string) string,
-) ([]common.Lock, error) {
-	locks := make([]common.Lock, len(ids))
-	for i, id := range ids {
-		lockKey := getKey(strconv.Itoa(id))
-		lock, err := lockingService.Acquire(ctx, lockKey)
-		if err != nil {
-			return locks, common.NewBadRequestFromMessage("Failed to acquire lock for id: " + strconv.Itoa(id))
-		}
-		locks[i] = lock
+	locks, err := lockingService.AcquireMany(ctx, keys)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Warn("failed to acquire batch locks", zap.Strings("keys", keys), zap.Error(err))
+		return []common.Lock{}, common.NewBadRequestFromMessage("Failed to acquire locks for batch update")
 	}
 	return locks, nil
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/migrations"
+	"github.com/nayefradwi/zanobia_inventory_manager/user"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+// commands are run as: main [env] <command> [args...]
+// available commands: migrate, seed, create-admin, reset-password
+func runCommand(args []string) {
+	defer common.CleanUp()
+	switch args[0] {
+	case "migrate":
+		runMigrateCommand(args[1:])
+	case "seed":
+		runSeedCommand()
+	case "create-admin":
+		runCreateAdminCommand(args[1:])
+	case "reset-password":
+		runResetPasswordCommand(args[1:])
+	default:
+		common.GetLogger().Fatal("unknown command", zap.String("command", args[0]))
+	}
+}
+
+func runMigrateCommand(args []string) {
+	if len(args) != 1 {
+		common.GetLogger().Fatal("usage: migrate up|down|status")
+	}
+	ctx := context.Background()
+	dbPool := common.ConnectDatabasePool(ctx, RegisteredApiConfig.DbConnectionUrl)
+	defer dbPool.Close()
+	migrator, err := migrations.NewMigrator(dbPool)
+	if err != nil {
+		common.GetLogger().Fatal("failed to load migrations", zap.Error(err))
+	}
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		err = printMigrationStatus(ctx, migrator)
+	default:
+		common.GetLogger().Fatal("usage: migrate up|down|status")
+	}
+	if err != nil {
+		common.GetLogger().Fatal("migration failed", zap.Error(err))
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d %-40s %s\n", status.Version, status.Name, appliedAt)
+	}
+	return nil
+}
+
+func setUpCommandServices() *ServiceProvider {
+	common.SetSecret(RegisteredApiConfig.Secret)
+	provider := &ServiceProvider{}
+	provider.initiate(RegisteredApiConfig)
+	return provider
+}
+
+// runSeedCommand can be run repeatedly, existing records are left untouched
+func runSeedCommand() {
+	provider := setUpCommandServices()
+	defer cleanUp()
+	ctx := context.Background()
+	services := provider.services
+	if err := services.permissionService.InitiateInitialPermissions(ctx); err != nil {
+		common.GetLogger().Fatal("failed to seed permissions", zap.Error(err))
+	}
+	if err := services.unitService.InitiateAll(ctx); err != nil {
+		common.GetLogger().Fatal("failed to seed units", zap.Error(err))
+	}
+	if err := services.transactionService.InitiateAllReasons(ctx); err != nil {
+		common.GetLogger().Fatal("failed to seed transaction reasons", zap.Error(err))
+	}
+	if RegisteredApiConfig.InitialSysAdminEmail == "" {
+		common.GetLogger().Info("no initial system admin configured, skipping")
+		return
+	}
+	if err := services.userService.InitiateSystemAdmin(ctx); err != nil {
+		common.GetLogger().Fatal("failed to seed system admin", zap.Error(err))
+	}
+}
+
+func runCreateAdminCommand(args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email of the admin")
+	password := flags.String("password", "", "password of the admin, read from stdin when empty")
+	firstName := flags.String("first-name", "System", "first name of the admin")
+	lastName := flags.String("last-name", "Admin", "last name of the admin")
+	flags.Parse(args)
+	if *email == "" {
+		common.GetLogger().Fatal("usage: create-admin -email <email> [-password <password>] [-first-name <name>] [-last-name <name>]")
+	}
+	input := user.UserInput{
+		Email:     *email,
+		Password:  readPasswordIfEmpty(*password),
+		FirstName: *firstName,
+		LastName:  *lastName,
+	}
+	provider := setUpCommandServices()
+	defer cleanUp()
+	if err := provider.services.userService.CreateSystemAdmin(context.Background(), input); err != nil {
+		common.GetLogger().Fatal("failed to create admin", zap.Error(err))
+	}
+	common.GetLogger().Info("admin created", zap.String("email", *email))
+}
+
+func runResetPasswordCommand(args []string) {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "new password, read from stdin when empty")
+	flags.Parse(args)
+	if *email == "" {
+		common.GetLogger().Fatal("usage: reset-password -email <email> [-password <password>]")
+	}
+	newPassword := readPasswordIfEmpty(*password)
+	provider := setUpCommandServices()
+	defer cleanUp()
+	if err := provider.services.userService.ResetPassword(context.Background(), *email, newPassword); err != nil {
+		common.GetLogger().Fatal("failed to reset password", zap.Error(err))
+	}
+	common.GetLogger().Info("password reset", zap.String("email", *email))
+}
+
+// readPasswordIfEmpty keeps passwords out of the shell history and process list
+func readPasswordIfEmpty(password string) string {
+	if password != "" {
+		return password
+	}
+	fd := int(os.Stdin.Fd())
+	// piped input is not echoed so it is read as a plain line
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			common.GetLogger().Fatal("failed to read password", zap.Error(err))
+		}
+		return strings.TrimRight(line, "\r\n")
+	}
+	fmt.Print("password: ")
+	input, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		common.GetLogger().Fatal("failed to read password", zap.Error(err))
+	}
+	return string(input)
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ApiKeyHeader    = "X-Api-Key"
+	ApiKeyTokenType = "api_key"
+	apiKeyPrefix    = "zim"
+)
+
+// IApiKeyAuthenticator resolves an api key to the id of the service account owning it
+type IApiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, prefix, secret string) (int, error)
+}
+
+var apiKeyAuthenticator IApiKeyAuthenticator
+
+func SetApiKeyAuthenticator(authenticator IApiKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// GenerateApiKey returns the full key handed out once, its public lookup prefix and the secret part to be hashed
+func GenerateApiKey() (key, prefix, secret string, err error) {
+	prefix, err = randomHex(6)
+	if err != nil {
+		return "", "", "", err
+	}
+	secret, err = randomHex(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	return apiKeyPrefix + "_" + prefix + "_" + secret, prefix, secret, nil
+}
+
+func ParseApiKey(key string) (prefix, secret string, ok bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// authenticateApiKey returns claims shaped like the ones of an access token so the user middleware works for both
+func authenticateApiKey(ctx context.Context, key string) (map[string]interface{}, bool) {
+	prefix, secret, ok := ParseApiKey(key)
+	if !ok || apiKeyAuthenticator == nil {
+		return nil, false
+	}
+	userId, err := apiKeyAuthenticator.Authenticate(ctx, prefix, secret)
+	if err != nil || userId == 0 {
+		LoggerFromCtx(ctx).Warn("failed to authenticate api key", zap.String("prefix", prefix), zap.Error(err))
+		return nil, false
+	}
+	return map[string]interface{}{
+		userIdClaim:    float64(userId),
+		tokenTypeClaim: ApiKeyTokenType,
+	}, true
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeApiKeyAuthenticator struct {
+	prefix, secret string
+}
+
+func (a fakeApiKeyAuthenticator) Authenticate(ctx context.Context, prefix, secret string) (int, error) {
+	if prefix != a.prefix || secret != a.secret {
+		return 0, NewUnAuthorizedError("Invalid api key")
+	}
+	return 9, nil
+}
+
+func TestGenerateApiKey_ParsesBack(t *testing.T) {
+	key, prefix, secret, err := GenerateApiKey()
+	assert.NoError(t, err)
+	parsedPrefix, parsedSecret, ok := ParseApiKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secret, parsedSecret)
+
+	_, _, ok = ParseApiKey("Bearer something")
+	assert.False(t, ok)
+}
+
+func TestAuthenticationHeaderMiddleware_ApiKey(t *testing.T) {
+	key, prefix, secret, _ := GenerateApiKey()
+	SetApiKeyAuthenticator(fakeApiKeyAuthenticator{prefix: prefix, secret: secret})
+	defer SetApiKeyAuthenticator(nil)
+	var claims map[string]interface{}
+	handler := AuthenticationHeaderMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaimsFromContext(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(ApiKeyHeader, key)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, float64(9), claims["id"])
+	assert.Equal(t, ApiKeyTokenType, claims["type"])
+
+	claims = nil
+	recorder := httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(ApiKeyHeader, "zim_"+prefix+"_wrong")
+	handler.ServeHTTP(recorder, request)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
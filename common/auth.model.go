@@ -1,16 +1,37 @@
 package common
 
 import (
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+	tokenTypeClaim   = "type"
+	tokenIdClaim     = "jti"
+	userIdClaim      = "id"
+)
+
 type Token struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
+type TokenInfo struct {
+	Id        string
+	UserId    int
+	Type      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type TokenOptions struct {
 	AccessTokenExpiry  time.Time
 	RefreshTokenExpiry time.Time
@@ -32,23 +53,61 @@ func DefaultTokenOptions() *TokenOptions {
 func generateSignedTokenString(claims map[string]interface{}, secret string, options *TokenOptions) (string, error) {
 	setIssuedAtClaim(claims)
 	setExpiryDate(claims, options.AccessTokenExpiry)
+	if err := setTokenIdClaim(claims); err != nil {
+		return "", err
+	}
+	claims[tokenTypeClaim] = AccessTokenType
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(claims))
 	return token.SignedString([]byte(secret))
 }
 
-func generateRefreshTokenString(secret string, options *TokenOptions) (string, error) {
+// refresh tokens only carry the user id, the rest of the claims are reloaded on refresh
+func generateRefreshTokenString(secret string, options *TokenOptions, userId interface{}) (string, error) {
 	claims := make(map[string]interface{})
 	setIssuedAtClaim(claims)
 	setExpiryDate(claims, options.RefreshTokenExpiry)
+	if err := setTokenIdClaim(claims); err != nil {
+		return "", err
+	}
+	claims[userIdClaim] = userId
+	claims[tokenTypeClaim] = RefreshTokenType
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(claims))
 	return token.SignedString([]byte(secret))
 }
 
+// iat keeps milliseconds so revoking all tokens of a user does not reject logins later in the same second
 func setIssuedAtClaim(claims map[string]interface{}) {
-	createdAt := &jwt.NumericDate{Time: time.Now()}
-	claims["iat"] = createdAt
+	claims["iat"] = float64(time.Now().UnixMilli()) / 1000
 }
 
 func setExpiryDate(claims map[string]interface{}, expiry time.Time) {
 	claims["exp"] = &jwt.NumericDate{Time: expiry}
 }
+
+func setTokenIdClaim(claims map[string]interface{}) error {
+	id, err := GenerateUuid()
+	if err != nil {
+		return err
+	}
+	claims[tokenIdClaim] = id
+	return nil
+}
+
+// GetTokenInfo reads the claims of a decoded token, ok is false when any of them is missing
+func GetTokenInfo(claims map[string]interface{}) (TokenInfo, bool) {
+	id, idOk := claims[tokenIdClaim].(string)
+	tokenType, typeOk := claims[tokenTypeClaim].(string)
+	userId, userIdOk := claims[userIdClaim].(float64)
+	issuedAt, issuedAtOk := claims["iat"].(float64)
+	expiresAt, expiresAtOk := claims["exp"].(float64)
+	if !idOk || !typeOk || !userIdOk || !issuedAtOk || !expiresAtOk {
+		return TokenInfo{}, false
+	}
+	return TokenInfo{
+		Id:        id,
+		UserId:    int(userId),
+		Type:      tokenType,
+		IssuedAt:  time.UnixMilli(int64(math.Round(issuedAt * 1000))),
+		ExpiresAt: time.Unix(int64(expiresAt), 0),
+	}, true
+}
@@ -23,6 +23,16 @@ func SetSecret(envSecret string) {
 
 func AuthenticationHeaderMiddleware(f http.Handler) http.Handler {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey := r.Header.Get(ApiKeyHeader); apiKey != "" {
+			claims, ok := authenticateApiKey(r.Context(), apiKey)
+			if !ok {
+				WriteResponseFromError(w, NewUnAuthorizedError("Invalid api key"))
+				return
+			}
+			ctx := context.WithValue(r.Context(), ClaimsKey{}, claims)
+			f.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
 		authHeader := r.Header.Get("Authorization")
 		token := getIfTokenExists(authHeader)
 		if len(token) <= 0 {
@@ -34,6 +44,11 @@ func AuthenticationHeaderMiddleware(f http.Handler) http.Handler {
 			WriteResponseFromError(w, NewUnAuthorizedError("Invalid token"))
 			return
 		}
+		tokenInfo, ok := GetTokenInfo(claims)
+		if !ok || tokenInfo.Type != AccessTokenType || isTokenRevoked(r.Context(), tokenInfo) {
+			WriteResponseFromError(w, NewUnAuthorizedError("Invalid token"))
+			return
+		}
 		ctx := context.WithValue(r.Context(), ClaimsKey{}, claims)
 		f.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -54,7 +69,7 @@ func GenerateAccessToken(claims map[string]interface{}) (Token, error) {
 	if err != nil {
 		return Token{}, err
 	}
-	refreshToken, err := generateRefreshTokenString(secret, options)
+	refreshToken, err := generateRefreshTokenString(secret, options, claims[userIdClaim])
 	return Token{AccessToken: tokenString, RefreshToken: refreshToken}, err
 }
 
@@ -66,10 +81,22 @@ func GenerateAccessTokenWithOptions(claims map[string]interface{}, options *Toke
 	if err != nil {
 		return Token{}, err
 	}
-	refreshToken, err := generateRefreshTokenString(secret, options)
+	refreshToken, err := generateRefreshTokenString(secret, options, claims[userIdClaim])
 	return Token{AccessToken: tokenString, RefreshToken: refreshToken}, err
 }
 
+func DecodeRefreshToken(tokenString string) (TokenInfo, error) {
+	claims, err := DecodeAccessToken(tokenString, secret)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	tokenInfo, ok := GetTokenInfo(claims)
+	if !ok || tokenInfo.Type != RefreshTokenType {
+		return TokenInfo{}, NewUnAuthorizedError("Invalid refresh token")
+	}
+	return tokenInfo, nil
+}
+
 func DecodeAccessToken(tokenString string, secret string) (map[string]interface{}, error) {
 	if isVerified, token := verifyToken(tokenString, secret); isVerified {
 		claims := parseToken(token)
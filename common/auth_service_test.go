@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAccessToken_RefreshTokenIsBoundToUser(t *testing.T) {
+	SetSecret("test-secret")
+	token, err := GenerateAccessToken(map[string]interface{}{"id": float64(5)})
+	assert.NoError(t, err)
+
+	claims, err := DecodeAccessToken(token.AccessToken, "test-secret")
+	assert.NoError(t, err)
+	accessTokenInfo, ok := GetTokenInfo(claims)
+	assert.True(t, ok)
+	assert.Equal(t, AccessTokenType, accessTokenInfo.Type)
+	assert.Equal(t, 5, accessTokenInfo.UserId)
+
+	refreshTokenInfo, err := DecodeRefreshToken(token.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, refreshTokenInfo.UserId)
+	assert.NotEqual(t, accessTokenInfo.Id, refreshTokenInfo.Id)
+
+	_, err = DecodeRefreshToken(token.AccessToken)
+	assert.Error(t, err) // access tokens can't be used to refresh
+}
+
+func TestGetRevokedBefore_KeepsLaterLoginsOfTheSameSecond(t *testing.T) {
+	revokedAt := time.Date(2024, 1, 1, 10, 0, 0, 700*int(time.Millisecond), time.UTC)
+	revokedBefore := getRevokedBefore(revokedAt)
+	issuedAt := func(at time.Time) time.Time {
+		info, ok := GetTokenInfo(map[string]interface{}{
+			tokenIdClaim:   "id",
+			tokenTypeClaim: AccessTokenType,
+			userIdClaim:    float64(5),
+			"iat":          float64(at.UnixMilli()) / 1000,
+			"exp":          float64(at.Add(time.Hour).Unix()),
+		})
+		assert.True(t, ok)
+		return info.IssuedAt
+	}
+	assert.True(t, revokedBefore.After(issuedAt(revokedAt.Add(-time.Millisecond))))
+	assert.True(t, revokedBefore.After(issuedAt(revokedAt)))
+	assert.False(t, revokedBefore.After(issuedAt(revokedAt.Add(time.Millisecond))))
+	assert.False(t, revokedBefore.After(issuedAt(revokedAt.Add(200*time.Millisecond))))
+}
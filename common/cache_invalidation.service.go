@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type ICacheInvalidationService interface {
+	Publish(ctx context.Context, channel string) error
+	Subscribe(ctx context.Context, channel string, onInvalidate func(ctx context.Context))
+}
+
+// LocalCacheInvalidationService is used when there is no redis, so only a single instance is expected
+type LocalCacheInvalidationService struct{}
+
+func (LocalCacheInvalidationService) Publish(ctx context.Context, channel string) error { return nil }
+
+func (LocalCacheInvalidationService) Subscribe(ctx context.Context, channel string, onInvalidate func(ctx context.Context)) {
+}
+
+type RedisCacheInvalidationService struct {
+	client     *redis.Client
+	instanceId string
+}
+
+func NewRedisCacheInvalidationService(client *redis.Client) *RedisCacheInvalidationService {
+	instanceId, err := GenerateUuid()
+	if err != nil {
+		GetLogger().Panic("failed to generate cache invalidation instance id", zap.Error(err))
+	}
+	return &RedisCacheInvalidationService{
+		client:     client,
+		instanceId: instanceId,
+	}
+}
+
+func (s *RedisCacheInvalidationService) Publish(ctx context.Context, channel string) error {
+	err := s.client.Publish(ctx, channel, s.instanceId).Err()
+	if err != nil {
+		LoggerFromCtx(ctx).Error("failed to publish cache invalidation", zap.String("channel", channel), zap.Error(err))
+		return NewInternalServerError()
+	}
+	return nil
+}
+
+// Subscribe runs onInvalidate every time another instance publishes on the channel,
+// messages published by this instance are skipped since its cache is already up to date.
+func (s *RedisCacheInvalidationService) Subscribe(ctx context.Context, channel string, onInvalidate func(ctx context.Context)) {
+	pubsub := s.client.Subscribe(ctx, channel)
+	messages := pubsub.Channel()
+	go func() {
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				if message.Payload == s.instanceId {
+					continue
+				}
+				GetLogger().Info("received cache invalidation", zap.String("channel", channel))
+				onInvalidate(ctx)
+			}
+		}
+	}()
+}
@@ -21,9 +21,25 @@ func getEnvArgument() string {
 	}
 	return PROD
 }
+
+// GetCommandArgs returns the arguments after the optional environment argument
+func GetCommandArgs() []string {
+	if len(os.Args) < 2 {
+		return []string{}
+	}
+	if isEnvName(os.Args[1]) {
+		return os.Args[2:]
+	}
+	return os.Args[1:]
+}
+
+func isEnvName(env string) bool {
+	return env == PROD || env == DEV || env == STAGING
+}
+
 func isAlreadyLoaded() bool {
 	env := os.Getenv("ENV")
-	isLoaded := env == PROD || env == DEV || env == STAGING
+	isLoaded := isEnvName(env)
 	if isLoaded {
 		setEnv(env)
 		GetLogger().Info("Environment already loaded: " + env)
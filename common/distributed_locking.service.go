@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,26 +15,95 @@ const (
 	DefaultTimeout = 5 * time.Second
 )
 
+// the fencing counter is compared against rows in postgres, so redis must persist it
+const (
+	fencingTokenKey   = "locks:fencing-token"
+	lockRetryInterval = 100 * time.Millisecond
+)
+
 type IDistributedLockingService interface {
 	Acquire(ctx context.Context, name string) (Lock, error)
 	CustomeDurationAcquire(ctx context.Context, name string, expiresAt, timeout time.Duration) (Lock, error)
+	AcquireMany(ctx context.Context, names []string) ([]Lock, error)
 	Release(ctx context.Context, lock Lock) error
 	ReleaseMany(ctx context.Context, locks *[]Lock)
 	RunWithLock(ctx context.Context, name string, f func() error) error
+	// SeedFencingToken moves the counter up to at least the given token, every backend keeps
+	// its own counter so it has to be seeded on startup and whenever the backend is switched
+	SeedFencingToken(ctx context.Context, fencingToken int64) error
 }
 
+// Lock is owned by whoever holds its Token, FencingToken only ever grows
+// across all locks so writes guarded by a lock can reject stale holders.
 type Lock struct {
-	Name      string
-	ExpiresAt time.Duration
+	Name         string
+	ExpiresAt    time.Duration
+	Token        string
+	FencingToken int64
+	stopRenewal  context.CancelFunc
 }
 
+func GetFencingToken(locks []Lock) int64 {
+	var fencingToken int64
+	for _, lock := range locks {
+		if lock.FencingToken > fencingToken {
+			fencingToken = lock.FencingToken
+		}
+	}
+	return fencingToken
+}
+
+var acquireLockScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// acquireManyLocksScript expects the fencing token key last, it sets either all locks or none
+var acquireManyLocksScript = redis.NewScript(`
+for i = 1, #KEYS - 1 do
+	if redis.call("EXISTS", KEYS[i]) == 1 then
+		return 0
+	end
+end
+for i = 1, #KEYS - 1 do
+	redis.call("SET", KEYS[i], ARGV[1], "PX", ARGV[2])
+end
+return redis.call("INCR", KEYS[#KEYS])
+`)
+
+var seedFencingTokenScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+if current < tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 type RedisLockService struct {
 	client *redis.Client
+	// renewalInterval defaults to a third of the lock duration
+	renewalInterval time.Duration
 }
 
 func CreateNewRedisLockService(client *redis.Client) IDistributedLockingService {
 	return &RedisLockService{
-		client,
+		client: client,
 	}
 }
 
@@ -43,71 +113,139 @@ func (s *RedisLockService) Acquire(ctx context.Context, name string) (Lock, erro
 
 func (s *RedisLockService) CustomeDurationAcquire(ctx context.Context, name string, expiresAt, timeout time.Duration) (Lock, error) {
 	GetLogger().Debug("Acquiring lock", zap.String("name", name))
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	token, err := GenerateUuid()
+	if err != nil {
+		return Lock{}, errors.New("failed to generate lock token")
+	}
+	acquireCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	ch := make(chan Lock)
-	go s.acquire(ctx, ch, name, expiresAt)
-	select {
-	case <-ctx.Done():
-		return Lock{}, errors.New("failed to Acquire lock")
-	case lock := <-ch:
-		return s.handleLockResult(lock)
+	for {
+		fencingToken, err := s.tryAcquireLock(acquireCtx, name, token, expiresAt)
+		if err == nil {
+			lock := Lock{Name: name, ExpiresAt: expiresAt, Token: token, FencingToken: fencingToken}
+			s.startRenewal(ctx, &lock)
+			return lock, nil
+		}
+		select {
+		case <-acquireCtx.Done():
+			return Lock{}, errors.New("failed to Acquire lock")
+		case <-time.After(lockRetryInterval):
+			continue
+		}
 	}
 }
 
-func (s *RedisLockService) acquire(ctx context.Context, ch chan Lock, name string, expiresAt time.Duration) {
-	defer ctx.Done()
-	err := s.waitForLock(ctx, name)
-	if err != nil {
-		GetLogger().Error("failed to wait for lock", zap.Error(err))
-		ch <- Lock{}
-		return
+func (s *RedisLockService) AcquireMany(ctx context.Context, names []string) ([]Lock, error) {
+	names = SortedLockNames(names)
+	if len(names) == 0 {
+		return []Lock{}, nil
 	}
-	_, lockErr := s.client.SetEx(ctx, name, name, expiresAt).Result()
-	if lockErr != nil {
-		GetLogger().Error("failed to set lock", zap.Error(lockErr))
-		ch <- Lock{}
+	GetLogger().Debug("Acquiring locks", zap.Strings("names", names))
+	token, err := GenerateUuid()
+	if err != nil {
+		return nil, errors.New("failed to generate lock token")
 	}
-	ch <- Lock{Name: name, ExpiresAt: expiresAt}
-}
-
-func (s *RedisLockService) waitForLock(ctx context.Context, name string) error {
+	acquireCtx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	keys := append(append([]string{}, names...), fencingTokenKey)
 	for {
-		err := s.tryAcquireLock(ctx, name)
-		if err == nil {
-			return nil
+		fencingToken, err := acquireManyLocksScript.Run(
+			acquireCtx, s.client, keys, token, DefaultExpiry.Milliseconds(),
+		).Int64()
+		if err == nil && fencingToken != 0 {
+			locks := make([]Lock, len(names))
+			for i, name := range names {
+				locks[i] = Lock{Name: name, ExpiresAt: DefaultExpiry, Token: token, FencingToken: fencingToken}
+				s.startRenewal(ctx, &locks[i])
+			}
+			return locks, nil
 		}
 		select {
-		case <-ctx.Done():
-			return errors.New("waiting for lock timed out")
-		case <-time.After(1 * time.Second):
+		case <-acquireCtx.Done():
+			return nil, errors.New("failed to Acquire locks")
+		case <-time.After(lockRetryInterval):
 			continue
 		}
 	}
 }
 
-func (s *RedisLockService) tryAcquireLock(ctx context.Context, name string) error {
-	value, err := s.client.Get(ctx, name).Result()
-	if err == redis.Nil && value != name {
-		return nil
+// SortedLockNames dedupes and sorts lock names so every caller takes them in the same order
+func SortedLockNames(names []string) []string {
+	unique := make(map[string]struct{}, len(names))
+	sorted := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := unique[name]; ok {
+			continue
+		}
+		unique[name] = struct{}{}
+		sorted = append(sorted, name)
 	}
-	return errors.New("failed to Acquire lock because it is already acquired")
+	sort.Strings(sorted)
+	return sorted
 }
 
-func (s *RedisLockService) handleLockResult(lock Lock) (Lock, error) {
-	if lock.Name == "" {
-		return Lock{}, errors.New("lock is empty")
+func (s *RedisLockService) tryAcquireLock(ctx context.Context, name, token string, expiresAt time.Duration) (int64, error) {
+	fencingToken, err := acquireLockScript.Run(
+		ctx, s.client, []string{name, fencingTokenKey}, token, expiresAt.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, err
 	}
-	return lock, nil
+	if fencingToken == 0 {
+		return 0, errors.New("failed to Acquire lock because it is already acquired")
+	}
+	return fencingToken, nil
+}
+
+// startRenewal keeps extending the lock while its holder is still working on it,
+// renewal stops on Release, when ctx is done or once the lock is lost.
+func (s *RedisLockService) startRenewal(ctx context.Context, lock *Lock) {
+	renewalCtx, stop := context.WithCancel(ctx)
+	lock.stopRenewal = stop
+	name, token, expiresAt := lock.Name, lock.Token, lock.ExpiresAt
+	interval := s.renewalInterval
+	if interval == 0 {
+		interval = expiresAt / 3
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewalCtx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := renewLockScript.Run(
+					renewalCtx, s.client, []string{name}, token, expiresAt.Milliseconds(),
+				).Int64()
+				if renewalCtx.Err() != nil {
+					return
+				}
+				if err != nil || renewed == 0 {
+					GetLogger().Warn("lost lock while renewing it", zap.String("name", name), zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
 }
 
 func (s *RedisLockService) Release(ctx context.Context, lock Lock) error {
+	if lock.Name == "" {
+		return nil
+	}
 	GetLogger().Debug("Releasing lock", zap.String("name", lock.Name))
-	_, err := s.client.Del(ctx, lock.Name).Result()
+	if lock.stopRenewal != nil {
+		lock.stopRenewal()
+	}
+	released, err := releaseLockScript.Run(ctx, s.client, []string{lock.Name}, lock.Token).Int64()
 	if err != nil {
 		GetLogger().Error("failed to Release lock", zap.Error(err))
 		return errors.New("failed to Release lock")
 	}
+	if released == 0 {
+		GetLogger().Debug("lock was already released or acquired by someone else", zap.String("name", lock.Name))
+	}
 	return nil
 }
 
@@ -125,3 +263,7 @@ func (s *RedisLockService) RunWithLock(ctx context.Context, name string, f func(
 	defer s.Release(ctx, lock)
 	return f()
 }
+
+func (s *RedisLockService) SeedFencingToken(ctx context.Context, fencingToken int64) error {
+	return seedFencingTokenScript.Run(ctx, s.client, []string{fencingTokenKey}, fencingToken).Err()
+}
@@ -41,6 +41,10 @@ func NewForbiddenError(message string, code string) *ApiError {
 	return NewCustomError(message, FORBIDDEN, code)
 }
 
+func NewConflictError(message string) *ApiError {
+	return newError(message, CONFLICT, CONFLICT_CODE)
+}
+
 func NewValidationError(message string, errors ...ErrorDetails) *ApiError {
 	return NewCustomError(message, BAD_REQUEST, INVALID_INPUT_CODE, errors...)
 }
@@ -55,6 +59,8 @@ func GenerateErrorFromStatus(status int) *ApiError {
 		return NewBadRequestError("Bad Request", BAD_REQUEST_CODE)
 	case FORBIDDEN:
 		return NewForbiddenError("Forbidden", FORBIDDEN_CODE)
+	case CONFLICT:
+		return NewConflictError("Conflict")
 	default:
 		return NewInternalServerError()
 	}
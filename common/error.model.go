@@ -7,6 +7,7 @@ const (
 	BAD_REQUEST           int = 400
 	UNAUTHORIZED          int = 401
 	FORBIDDEN             int = 403
+	CONFLICT              int = 409
 	INTERNAL_SERVER_ERROR int = 500
 )
 
@@ -15,6 +16,7 @@ const (
 	BAD_REQUEST_CODE    = "BAD_REQUEST"
 	UNAUTHORIZED_CODE   = "UNAUTHORIZED"
 	FORBIDDEN_CODE      = "FORBIDDEN"
+	CONFLICT_CODE       = "CONFLICT"
 	INTERNAL_ERROR_CODE = "INTERNAL_ERROR"
 	INVALID_INPUT_CODE  = "INVALID_INPUT"
 	UNKNOWN_ERROR_CODE  = "UNKNOWN_ERROR"
@@ -28,8 +28,12 @@ func GetUtcDateOnlyString() string {
 }
 
 func GetUtcDateOnlyStringFromTime(t time.Time) string {
+	return GetUtcDateOnlyFromTime(t).Format(time.RFC3339)
+}
+
+func GetUtcDateOnlyFromTime(t time.Time) time.Time {
 	y, m, d := t.UTC().Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 }
 
 func GetValues[K comparable, V any](input map[K]V) []V {
@@ -0,0 +1,118 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// InMemoryLockService only locks within a single process, it is meant for tests
+type InMemoryLockService struct {
+	mu           sync.Mutex
+	locks        map[string]inMemoryLock
+	fencingToken int64
+}
+
+type inMemoryLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+func CreateNewInMemoryLockService() *InMemoryLockService {
+	return &InMemoryLockService{
+		locks: make(map[string]inMemoryLock),
+	}
+}
+
+func (s *InMemoryLockService) Acquire(ctx context.Context, name string) (Lock, error) {
+	return s.CustomeDurationAcquire(ctx, name, DefaultExpiry, DefaultTimeout)
+}
+
+func (s *InMemoryLockService) CustomeDurationAcquire(ctx context.Context, name string, expiresAt, timeout time.Duration) (Lock, error) {
+	locks, err := s.acquireMany(ctx, []string{name}, expiresAt, timeout)
+	if err != nil {
+		return Lock{}, err
+	}
+	return locks[0], nil
+}
+
+func (s *InMemoryLockService) AcquireMany(ctx context.Context, names []string) ([]Lock, error) {
+	return s.acquireMany(ctx, SortedLockNames(names), DefaultExpiry, DefaultTimeout)
+}
+
+func (s *InMemoryLockService) acquireMany(ctx context.Context, names []string, expiresAt, timeout time.Duration) ([]Lock, error) {
+	if len(names) == 0 {
+		return []Lock{}, nil
+	}
+	token, err := GenerateUuid()
+	if err != nil {
+		return nil, errors.New("failed to generate lock token")
+	}
+	acquireCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		if fencingToken, ok := s.tryAcquire(names, token, expiresAt); ok {
+			locks := make([]Lock, len(names))
+			for i, name := range names {
+				locks[i] = Lock{Name: name, ExpiresAt: expiresAt, Token: token, FencingToken: fencingToken}
+			}
+			return locks, nil
+		}
+		select {
+		case <-acquireCtx.Done():
+			return nil, errors.New("failed to Acquire lock")
+		case <-time.After(lockRetryInterval):
+			continue
+		}
+	}
+}
+
+func (s *InMemoryLockService) tryAcquire(names []string, token string, expiresAt time.Duration) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, name := range names {
+		if lock, ok := s.locks[name]; ok && now.Before(lock.expiresAt) {
+			return 0, false
+		}
+	}
+	for _, name := range names {
+		s.locks[name] = inMemoryLock{token: token, expiresAt: now.Add(expiresAt)}
+	}
+	s.fencingToken++
+	return s.fencingToken, true
+}
+
+func (s *InMemoryLockService) Release(ctx context.Context, lock Lock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held, ok := s.locks[lock.Name]; ok && held.token == lock.Token {
+		delete(s.locks, lock.Name)
+	}
+	return nil
+}
+
+func (s *InMemoryLockService) ReleaseMany(ctx context.Context, locks *[]Lock) {
+	for _, lock := range *locks {
+		s.Release(ctx, lock)
+	}
+}
+
+func (s *InMemoryLockService) SeedFencingToken(ctx context.Context, fencingToken int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fencingToken > s.fencingToken {
+		s.fencingToken = fencingToken
+	}
+	return nil
+}
+
+func (s *InMemoryLockService) RunWithLock(ctx context.Context, name string, f func() error) error {
+	lock, err := s.Acquire(ctx, name)
+	if err != nil {
+		return NewBadRequestFromMessage("Failed to acquire lock")
+	}
+	defer s.Release(ctx, lock)
+	return f()
+}
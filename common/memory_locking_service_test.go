@@ -0,0 +1,48 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryLockService_AcquireMany(t *testing.T) {
+	service := CreateNewInMemoryLockService()
+	ctx := context.Background()
+
+	held, err := service.CustomeDurationAcquire(ctx, "lock:b", DefaultExpiry, time.Second)
+	assert.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = service.AcquireMany(waitCtx, []string{"lock:a", "lock:b"})
+	assert.Error(t, err)
+
+	assert.NoError(t, service.Release(ctx, held))
+	locks, err := service.AcquireMany(ctx, []string{"lock:b", "lock:a"})
+	assert.NoError(t, err)
+	assert.Greater(t, locks[0].FencingToken, held.FencingToken)
+
+	// a stale holder must not release a lock it no longer owns
+	assert.NoError(t, service.Release(ctx, held))
+	_, err = service.CustomeDurationAcquire(ctx, "lock:b", DefaultExpiry, 200*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestInMemoryLockService_SeedFencingToken(t *testing.T) {
+	service := CreateNewInMemoryLockService()
+	ctx := context.Background()
+
+	assert.NoError(t, service.SeedFencingToken(ctx, 41))
+	lock, err := service.Acquire(ctx, "lock:a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), lock.FencingToken)
+
+	// seeding never moves the counter back
+	assert.NoError(t, service.SeedFencingToken(ctx, 5))
+	lock, err = service.Acquire(ctx, "lock:b")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(43), lock.FencingToken)
+}
@@ -24,6 +24,11 @@ type DbOperatorKey struct{}
 
 type TransactionFunc func(ctx context.Context, tx pgx.Tx) error
 
+// TxBeginner is satisfied by *pgxpool.Pool, services depend on it so transactions can be faked in tests
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
 type PaginatedResponse[T any] struct {
 	PageSize       int    `json:"pageSize"`
 	EndCursor      string `json:"endCursor"`
@@ -81,7 +86,9 @@ type PaginationQuery struct {
 	RefreshCompareSymbol  string
 	ForwardCompareSymbol  string
 	BackwardCompareSymbol string
-	Op                    DbOperator
+	// BaseArgCount is the number of arguments referenced by BaseSql, they come before the conditions' arguments
+	BaseArgCount int
+	Op           DbOperator
 }
 
 type PaginationParams struct {
@@ -133,6 +140,11 @@ func (b *paginationQueryBuilder) WithConditions(conditions []string) *pagination
 	return b
 }
 
+func (b *paginationQueryBuilder) WithBaseArgCount(count int) *paginationQueryBuilder {
+	b.query.BaseArgCount = count
+	return b
+}
+
 func (b *paginationQueryBuilder) WithPageSize(pageSize int) *paginationQueryBuilder {
 	b.query.PageSize = pageSize
 	return b
@@ -164,7 +176,7 @@ func (q PaginationQuery) GetCurrentCursor() []string {
 }
 
 func (q *PaginationQuery) getFinalArgAndJoinedConditions() (int, string) {
-	finalArgIndex := 1
+	finalArgIndex := 1 + q.BaseArgCount
 	for _, condition := range q.Conditions {
 		if condition != "" &&
 			condition != " " &&
@@ -176,7 +188,7 @@ func (q *PaginationQuery) getFinalArgAndJoinedConditions() (int, string) {
 		}
 	}
 	var joinedConditions string
-	if finalArgIndex != 1 {
+	if finalArgIndex != 1+q.BaseArgCount {
 		joinedConditions = strings.Join(q.Conditions, " ")
 	}
 	return finalArgIndex, joinedConditions
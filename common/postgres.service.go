@@ -8,11 +8,11 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
 )
 
-func RunWithTransaction(ctx context.Context, pool *pgxpool.Pool, transaction TransactionFunc) error {
-	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+func RunWithTransaction(ctx context.Context, pool TxBeginner, transaction TransactionFunc) error {
+	tx, err := beginTransaction(ctx, pool)
 	if err != nil {
 		return NewInternalServerError()
 	}
@@ -25,10 +25,22 @@ func RunWithTransaction(ctx context.Context, pool *pgxpool.Pool, transaction Tra
 		}
 		return NewInternalServerError()
 	}
-	tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		LoggerFromCtx(ctx).Error("failed to commit transaction", zap.Error(err))
+		return NewInternalServerError()
+	}
 	return nil
 }
 
+// beginTransaction starts a savepoint when the context already runs inside a transaction
+// so nested calls commit or roll back together with the outer transaction
+func beginTransaction(ctx context.Context, pool TxBeginner) (pgx.Tx, error) {
+	if outer, ok := ctx.Value(DbOperatorKey{}).(pgx.Tx); ok {
+		return outer.Begin(ctx)
+	}
+	return pool.BeginTx(ctx, pgx.TxOptions{})
+}
+
 func SetPaginatedDataMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		paginationParam := getPaginationParams(r)
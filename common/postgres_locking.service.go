@@ -0,0 +1,221 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// PostgresLockService uses advisory locks, inside RunWithTransaction they are transaction
+// scoped and released on commit or rollback, otherwise they are held on a dedicated
+// connection until Release is called.
+type PostgresLockService struct {
+	pool     *pgxpool.Pool
+	mu       sync.Mutex
+	sessions map[string]*advisoryLockSession
+}
+
+type advisoryLockSession struct {
+	conn *pgxpool.Conn
+	keys map[string]int64
+}
+
+func CreateNewPostgresLockService(pool *pgxpool.Pool) IDistributedLockingService {
+	return &PostgresLockService{
+		pool:     pool,
+		sessions: make(map[string]*advisoryLockSession),
+	}
+}
+
+func (s *PostgresLockService) Acquire(ctx context.Context, name string) (Lock, error) {
+	return s.CustomeDurationAcquire(ctx, name, DefaultExpiry, DefaultTimeout)
+}
+
+func (s *PostgresLockService) CustomeDurationAcquire(ctx context.Context, name string, expiresAt, timeout time.Duration) (Lock, error) {
+	locks, err := s.acquireMany(ctx, []string{name}, expiresAt, timeout)
+	if err != nil {
+		return Lock{}, err
+	}
+	return locks[0], nil
+}
+
+func (s *PostgresLockService) AcquireMany(ctx context.Context, names []string) ([]Lock, error) {
+	return s.acquireMany(ctx, SortedLockNames(names), DefaultExpiry, DefaultTimeout)
+}
+
+func (s *PostgresLockService) acquireMany(ctx context.Context, names []string, expiresAt, timeout time.Duration) ([]Lock, error) {
+	if len(names) == 0 {
+		return []Lock{}, nil
+	}
+	GetLogger().Debug("Acquiring advisory locks", zap.Strings("names", names))
+	token, err := GenerateUuid()
+	if err != nil {
+		return nil, errors.New("failed to generate lock token")
+	}
+	keys := make([]int64, len(names))
+	for i, name := range names {
+		keys[i] = getAdvisoryLockKey(name)
+	}
+	tx, inTransaction := ctx.Value(DbOperatorKey{}).(pgx.Tx)
+	deadline := time.Now().Add(timeout)
+	for {
+		var fencingToken int64
+		if inTransaction {
+			fencingToken, err = s.tryTransactionLocks(ctx, tx, keys)
+		} else {
+			fencingToken, err = s.trySessionLocks(ctx, token, names, keys)
+		}
+		if err == nil {
+			locks := make([]Lock, len(names))
+			for i, name := range names {
+				locks[i] = Lock{Name: name, ExpiresAt: expiresAt, Token: token, FencingToken: fencingToken}
+			}
+			return locks, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("failed to Acquire lock")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.New("failed to Acquire lock")
+		case <-time.After(lockRetryInterval):
+			continue
+		}
+	}
+}
+
+// tryTransactionLocks takes the locks inside a savepoint so a partial acquisition is rolled back
+func (s *PostgresLockService) tryTransactionLocks(ctx context.Context, tx pgx.Tx, keys []int64) (int64, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer savepoint.Rollback(ctx)
+	var acquired bool
+	err = savepoint.QueryRow(ctx, `SELECT bool_and(pg_try_advisory_xact_lock(k)) FROM unnest($1::bigint[]) AS k`, keys).
+		Scan(&acquired)
+	if err != nil {
+		return 0, err
+	}
+	if !acquired {
+		return 0, errors.New("failed to Acquire lock because it is already acquired")
+	}
+	var fencingToken int64
+	if err := savepoint.QueryRow(ctx, `SELECT nextval('lock_fencing_tokens')`).Scan(&fencingToken); err != nil {
+		return 0, err
+	}
+	return fencingToken, savepoint.Commit(ctx)
+}
+
+func (s *PostgresLockService) trySessionLocks(ctx context.Context, token string, names []string, keys []int64) (int64, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	session := &advisoryLockSession{conn: conn, keys: make(map[string]int64, len(keys))}
+	for i, key := range keys {
+		var acquired bool
+		err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+		if err == nil && !acquired {
+			err = errors.New("failed to Acquire lock because it is already acquired")
+		}
+		if err != nil {
+			s.closeSession(ctx, session)
+			return 0, err
+		}
+		session.keys[names[i]] = key
+	}
+	var fencingToken int64
+	if err := conn.QueryRow(ctx, `SELECT nextval('lock_fencing_tokens')`).Scan(&fencingToken); err != nil {
+		s.closeSession(ctx, session)
+		return 0, err
+	}
+	s.mu.Lock()
+	s.sessions[token] = session
+	s.mu.Unlock()
+	return fencingToken, nil
+}
+
+func (s *PostgresLockService) closeSession(ctx context.Context, session *advisoryLockSession) {
+	for name := range session.keys {
+		s.unlockSessionKey(ctx, session, name)
+	}
+	session.conn.Release()
+}
+
+func (s *PostgresLockService) unlockSessionKey(ctx context.Context, session *advisoryLockSession, name string) {
+	key := session.keys[name]
+	delete(session.keys, name)
+	if _, err := session.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		GetLogger().Error("failed to Release advisory lock", zap.String("name", name), zap.Error(err))
+	}
+}
+
+func (s *PostgresLockService) Release(ctx context.Context, lock Lock) error {
+	if lock.Name == "" {
+		return nil
+	}
+	GetLogger().Debug("Releasing advisory lock", zap.String("name", lock.Name))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[lock.Token]
+	if !ok {
+		// transaction scoped locks are released by the transaction itself
+		return nil
+	}
+	if _, held := session.keys[lock.Name]; !held {
+		return nil
+	}
+	s.unlockSessionKey(ctx, session, lock.Name)
+	if len(session.keys) == 0 {
+		delete(s.sessions, lock.Token)
+		session.conn.Release()
+	}
+	return nil
+}
+
+func (s *PostgresLockService) ReleaseMany(ctx context.Context, locks *[]Lock) {
+	for _, lock := range *locks {
+		s.Release(ctx, lock)
+	}
+}
+
+func (s *PostgresLockService) RunWithLock(ctx context.Context, name string, f func() error) error {
+	lock, err := s.Acquire(ctx, name)
+	if err != nil {
+		return NewBadRequestFromMessage("Failed to acquire lock")
+	}
+	defer s.Release(ctx, lock)
+	return f()
+}
+
+func (s *PostgresLockService) SeedFencingToken(ctx context.Context, fencingToken int64) error {
+	_, err := s.pool.Exec(ctx, `
+	SELECT setval('lock_fencing_tokens', $1)
+	WHERE $1 > (SELECT last_value FROM lock_fencing_tokens)`, fencingToken)
+	return err
+}
+
+// GetPersistedFencingToken is the highest fencing token written to any batch, a lock
+// service has to hand out larger tokens or every guarded update will be rejected
+func GetPersistedFencingToken(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	var fencingToken int64
+	err := pool.QueryRow(ctx, `
+	SELECT GREATEST(
+		(SELECT COALESCE(MAX(fencing_token), 0) FROM batches),
+		(SELECT COALESCE(MAX(fencing_token), 0) FROM retailer_batches)
+	)`).Scan(&fencingToken)
+	return fencingToken, err
+}
+
+func getAdvisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
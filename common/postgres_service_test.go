@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTx struct {
+	pgx.Tx
+	savepoint  *recordingTx
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *recordingTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx.savepoint = &recordingTx{}
+	return tx.savepoint, nil
+}
+
+func (tx *recordingTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *recordingTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type recordingPool struct {
+	tx *recordingTx
+}
+
+func (p *recordingPool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	p.tx = &recordingTx{}
+	return p.tx, nil
+}
+
+func TestRunWithTransaction_RollsBackOnlyTheInnerSavepoint(t *testing.T) {
+	pool := &recordingPool{}
+	var innerErr error
+	err := RunWithTransaction(context.Background(), pool, func(ctx context.Context, tx pgx.Tx) error {
+		innerErr = RunWithTransaction(ctx, pool, func(ctx context.Context, tx pgx.Tx) error {
+			return NewBadRequestFromMessage("inner failed")
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Error(t, innerErr)
+	outer := pool.tx
+	assert.NotNil(t, outer.savepoint)
+	assert.True(t, outer.savepoint.rolledBack)
+	assert.False(t, outer.savepoint.committed)
+	assert.True(t, outer.committed)
+	assert.False(t, outer.rolledBack)
+}
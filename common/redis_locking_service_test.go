@@ -2,104 +2,171 @@ package common
 
 import (
 	"context"
-	"log"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestRedisLockService_Acquire_Release(t *testing.T) {
-	client := ConnectRedis(context.Background(), "redis://192.168.100.34:6379")
-	service := &RedisLockService{client: client}
+func newTestRedisLockService(t *testing.T) (*RedisLockService, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &RedisLockService{client: client}, server
+}
 
+func TestRedisLockService_Acquire_Release(t *testing.T) {
+	service, _ := newTestRedisLockService(t)
+	ctx := context.Background()
 	lockName := "testLock1"
-	expiresAt := DefaultExpiry
 
-	log.Printf("Acquiring lock %s", lockName)
-
-	lock, err := service.Acquire(context.Background(), lockName)
+	lock, err := service.Acquire(ctx, lockName)
 	assert.NoError(t, err)
 	assert.Equal(t, lockName, lock.Name)
-	assert.Equal(t, expiresAt, lock.ExpiresAt)
+	assert.Equal(t, DefaultExpiry, lock.ExpiresAt)
+	assert.NotEmpty(t, lock.Token)
 
-	retryErr := service.tryAcquireLock(context.Background(), lockName)
+	_, retryErr := service.tryAcquireLock(ctx, lockName, "other-token", DefaultExpiry)
 	assert.Error(t, retryErr) // The lock should not be available for other clients
 
-	log.Printf("Releasing lock %s", lockName)
-	err = service.Release(context.Background(), lock)
+	err = service.Release(ctx, lock)
 	assert.NoError(t, err)
 
-	log.Printf("ensuring lock %s is released", lockName)
-	err = service.tryAcquireLock(context.Background(), lockName)
+	_, err = service.tryAcquireLock(ctx, lockName, "other-token", DefaultExpiry)
 	assert.NoError(t, err)
 }
 
 func TestRedisLockService_Release_DurationExpired(t *testing.T) {
-	client := ConnectRedis(context.Background(), "redis://192.168.100.34:6379")
-	service := &RedisLockService{client: client}
-
+	service, server := newTestRedisLockService(t)
 	lockName := "testLock2"
-	expiresAt := 5 * time.Second // Short expiration time for testing
-
-	log.Printf("Acquiring lock %s with a short expiration time", lockName)
+	expiresAt := 5 * time.Second
 
-	_, err := service.CustomeDurationAcquire(context.Background(), lockName, expiresAt, time.Second)
+	// a cancelled context stands in for a holder that died without releasing
+	holderCtx, cancel := context.WithCancel(context.Background())
+	_, err := service.CustomeDurationAcquire(holderCtx, lockName, expiresAt, time.Second)
 	assert.NoError(t, err)
+	cancel()
 
-	// Wait for the lock to expire
-	time.Sleep(5 * time.Second)
+	server.FastForward(expiresAt)
 
-	log.Printf("ensuring lock %s is released", lockName)
-	err = service.tryAcquireLock(context.Background(), lockName)
+	_, err = service.tryAcquireLock(context.Background(), lockName, "other-token", DefaultExpiry)
 	assert.NoError(t, err)
 }
 
 func TestRedisLockService_MultipleReads(t *testing.T) {
-	client := ConnectRedis(context.Background(), "redis://192.168.100.34:6379")
-	service := &RedisLockService{client: client}
+	service, _ := newTestRedisLockService(t)
 	lockName := "testLock3"
 	expiresAt := 7 * time.Second
 	const numReaders = 5
 	errors := make([]error, 0)
-	tryAcquireLock := func() {
-		_, err := service.CustomeDurationAcquire(context.Background(), lockName, expiresAt, time.Second)
-		errors = append(errors, err)
-		time.Sleep(100 * time.Millisecond)
-	}
 	for i := 0; i < numReaders; i++ {
-		tryAcquireLock()
+		lock, err := service.CustomeDurationAcquire(context.Background(), lockName, expiresAt, 200*time.Millisecond)
+		if err == nil {
+			defer service.Release(context.Background(), lock)
+		}
+		errors = append(errors, err)
 	}
 
-	firstReaderFailed := false
-	for _, err := range errors {
-		if !firstReaderFailed {
-			assert.NoError(t, err) // The first reader should not fail
-			firstReaderFailed = true
-		} else {
-			assert.Error(t, err) // All other readers should fail
-		}
+	assert.NoError(t, errors[0]) // The first reader should not fail
+	for _, err := range errors[1:] {
+		assert.Error(t, err) // All other readers should fail
 	}
 }
 
 func TestRedisLockService_Release_MultipleTimes(t *testing.T) {
-	client := ConnectRedis(context.Background(), "redis://192.168.100.34:6379")
-	service := &RedisLockService{client: client}
-
-	lockName := "testLock4"
-	expiresAt := DefaultExpiry
-
-	log.Printf("Acquiring lock %s", lockName)
-
-	lock, err := service.Acquire(context.Background(), lockName)
+	service, _ := newTestRedisLockService(t)
+	lock, err := service.Acquire(context.Background(), "testLock4")
 	assert.NoError(t, err)
-	assert.Equal(t, lockName, lock.Name)
-	assert.Equal(t, expiresAt, lock.ExpiresAt)
 
-	// Try to release the same lock multiple times
 	for i := 0; i < 3; i++ {
-		log.Printf("Releasing lock %s (Attempt %d)", lockName, i+1)
 		err = service.Release(context.Background(), lock)
 		assert.NoError(t, err) // No error should be returned when releasing the same lock multiple times
 	}
 }
+
+func TestRedisLockService_Release_DoesNotStealLock(t *testing.T) {
+	service, server := newTestRedisLockService(t)
+	ctx := context.Background()
+	lockName := "testLock5"
+
+	holderCtx, cancel := context.WithCancel(ctx)
+	staleLock, err := service.CustomeDurationAcquire(holderCtx, lockName, time.Second, time.Second)
+	assert.NoError(t, err)
+	cancel()
+	server.FastForward(time.Second)
+
+	newLock, err := service.Acquire(ctx, lockName)
+	assert.NoError(t, err)
+	defer service.Release(ctx, newLock)
+
+	err = service.Release(ctx, staleLock)
+	assert.NoError(t, err)
+	value, err := server.Get(lockName)
+	assert.NoError(t, err)
+	assert.Equal(t, newLock.Token, value)
+	assert.Greater(t, newLock.FencingToken, staleLock.FencingToken)
+}
+
+func TestRedisLockService_Renewal(t *testing.T) {
+	service, server := newTestRedisLockService(t)
+	ctx := context.Background()
+	lockName := "testLock6"
+	expiresAt := 300 * time.Millisecond
+
+	service.renewalInterval = time.Millisecond
+
+	lock, err := service.CustomeDurationAcquire(ctx, lockName, expiresAt, time.Second)
+	assert.NoError(t, err)
+	// miniredis only expires keys when fast forwarded, a renewal resets the ttl to the full duration
+	server.FastForward(expiresAt * 2 / 3)
+	assert.Eventually(t, func() bool {
+		return server.TTL(lockName) == expiresAt
+	}, time.Second, time.Millisecond)
+	server.FastForward(expiresAt * 2 / 3)
+	assert.True(t, server.Exists(lockName))
+
+	assert.NoError(t, service.Release(ctx, lock))
+	assert.False(t, server.Exists(lockName))
+}
+
+func TestGetFencingToken(t *testing.T) {
+	locks := []Lock{{FencingToken: 3}, {FencingToken: 7}, {}}
+	assert.Equal(t, int64(7), GetFencingToken(locks))
+}
+
+func TestRedisLockService_AcquireMany_AllOrNothing(t *testing.T) {
+	service, server := newTestRedisLockService(t)
+	ctx := context.Background()
+
+	held, err := service.Acquire(ctx, "lock:b")
+	assert.NoError(t, err)
+
+	_, err = service.AcquireMany(ctx, []string{"lock:c", "lock:a", "lock:b"})
+	assert.Error(t, err)
+	assert.False(t, server.Exists("lock:a")) // nothing is left behind on failure
+	assert.False(t, server.Exists("lock:c"))
+
+	assert.NoError(t, service.Release(ctx, held))
+	locks, err := service.AcquireMany(ctx, []string{"lock:c", "lock:a", "lock:b", "lock:a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lock:a", "lock:b", "lock:c"}, []string{locks[0].Name, locks[1].Name, locks[2].Name})
+	service.ReleaseMany(ctx, &locks)
+	assert.False(t, server.Exists("lock:a"))
+}
+
+func TestRedisLockService_SeedFencingToken(t *testing.T) {
+	service, _ := newTestRedisLockService(t)
+	ctx := context.Background()
+
+	assert.NoError(t, service.SeedFencingToken(ctx, 41))
+	lock, err := service.Acquire(ctx, "lock:a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), lock.FencingToken)
+
+	assert.NoError(t, service.SeedFencingToken(ctx, 5))
+	lock, err = service.Acquire(ctx, "lock:b")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(43), lock.FencingToken)
+}
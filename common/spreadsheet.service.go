@@ -0,0 +1,122 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	CsvFormat  = "csv"
+	XlsxFormat = "xlsx"
+
+	maxSpreadsheetSize = 10 << 20
+	spreadsheetSheet   = "Sheet1"
+)
+
+func GetSpreadsheetFormat(filename string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if format != CsvFormat && format != XlsxFormat {
+		return "", NewBadRequestError("file must be a csv or xlsx file", "invalid_file_format")
+	}
+	return format, nil
+}
+
+// ReadSpreadsheet returns the rows of a csv file or of the first sheet of an xlsx file
+func ReadSpreadsheet(reader io.Reader, format string) ([][]string, error) {
+	if format == CsvFormat {
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		rows, err := csvReader.ReadAll()
+		if err != nil {
+			GetLogger().Error("failed to read csv file", zap.Error(err))
+			return nil, NewBadRequestError("failed to read csv file", "invalid_file")
+		}
+		return rows, nil
+	}
+	file, err := excelize.OpenReader(reader)
+	if err != nil {
+		GetLogger().Error("failed to open xlsx file", zap.Error(err))
+		return nil, NewBadRequestError("failed to read xlsx file", "invalid_file")
+	}
+	defer file.Close()
+	rows, err := file.GetRows(file.GetSheetName(0))
+	if err != nil {
+		GetLogger().Error("failed to read xlsx file", zap.Error(err))
+		return nil, NewBadRequestError("failed to read xlsx file", "invalid_file")
+	}
+	return rows, nil
+}
+
+func WriteSpreadsheet(writer io.Writer, format string, rows [][]string) error {
+	if format == CsvFormat {
+		csvWriter := csv.NewWriter(writer)
+		if err := csvWriter.WriteAll(rows); err != nil {
+			GetLogger().Error("failed to write csv file", zap.Error(err))
+			return NewInternalServerError()
+		}
+		return nil
+	}
+	file := excelize.NewFile()
+	defer file.Close()
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := file.SetSheetRow(spreadsheetSheet, cell, &row); err != nil {
+			GetLogger().Error("failed to write xlsx row", zap.Error(err))
+			return NewInternalServerError()
+		}
+	}
+	if err := file.Write(writer); err != nil {
+		GetLogger().Error("failed to write xlsx file", zap.Error(err))
+		return NewInternalServerError()
+	}
+	return nil
+}
+
+// ReadSpreadsheetFromRequest reads the multipart "file" field, the format comes from its extension
+func ReadSpreadsheetFromRequest(w http.ResponseWriter, r *http.Request) ([][]string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSpreadsheetSize)
+	file, header, err := r.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, NewBadRequestError("file is too large", "file_too_large")
+	}
+	if err != nil {
+		GetLogger().Error("failed to read uploaded file", zap.Error(err))
+		return nil, NewBadRequestError("file is required", "file_required")
+	}
+	defer file.Close()
+	format, err := GetSpreadsheetFormat(header.Filename)
+	if err != nil {
+		return nil, err
+	}
+	return ReadSpreadsheet(file, format)
+}
+
+func WriteSpreadsheetResponse(w http.ResponseWriter, name, format string, rows [][]string, err error) {
+	if err != nil {
+		WriteResponseFromError(w, err)
+		return
+	}
+	var buffer bytes.Buffer
+	if err := WriteSpreadsheet(&buffer, format, rows); err != nil {
+		WriteResponseFromError(w, err)
+		return
+	}
+	contentType := "text/csv"
+	if format == XlsxFormat {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"."+format+"\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpreadsheetRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"name", "options"},
+		{"milk, whole", "size=small|large"},
+		{"حليب", ""},
+	}
+	for _, format := range []string{CsvFormat, XlsxFormat} {
+		var buffer bytes.Buffer
+		assert.NoError(t, WriteSpreadsheet(&buffer, format, rows))
+		read, err := ReadSpreadsheet(&buffer, format)
+		assert.NoError(t, err)
+		assert.Equal(t, rows[:2], read[:2], format)
+		assert.Equal(t, "حليب", read[2][0], format)
+	}
+}
+
+func TestGetSpreadsheetFormat(t *testing.T) {
+	format, err := GetSpreadsheetFormat("products.XLSX")
+	assert.NoError(t, err)
+	assert.Equal(t, XlsxFormat, format)
+	_, err = GetSpreadsheetFormat("products.json")
+	assert.Error(t, err)
+}
+
+func TestReadSpreadsheetFromRequest_RejectsLargeFiles(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "products.csv")
+	part.Write(bytes.Repeat([]byte("a"), maxSpreadsheetSize+1))
+	writer.Close()
+	r := httptest.NewRequest(http.MethodPost, "/catalogue/import", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	_, err := ReadSpreadsheetFromRequest(w, r)
+	apiErr, ok := err.(*ApiError)
+	assert.True(t, ok)
+	assert.Equal(t, "file_too_large", apiErr.Code)
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ITokenRevocationService interface {
+	// Revoke returns false when the token was already revoked
+	Revoke(ctx context.Context, token TokenInfo) (bool, error)
+	RevokeAllForUser(ctx context.Context, userId int) error
+	IsRevoked(ctx context.Context, token TokenInfo) (bool, error)
+}
+
+var tokenRevocationService ITokenRevocationService
+
+func SetTokenRevocationService(service ITokenRevocationService) {
+	tokenRevocationService = service
+}
+
+// isTokenRevoked fails closed, a token is rejected when its revocation can't be checked
+func isTokenRevoked(ctx context.Context, token TokenInfo) bool {
+	if tokenRevocationService == nil {
+		return false
+	}
+	revoked, err := tokenRevocationService.IsRevoked(ctx, token)
+	if err != nil {
+		LoggerFromCtx(ctx).Error("failed to check token revocation", zap.Error(err))
+		return true
+	}
+	return revoked
+}
+
+type PostgresTokenRevocationService struct {
+	*pgxpool.Pool
+}
+
+func NewPostgresTokenRevocationService(pool *pgxpool.Pool) *PostgresTokenRevocationService {
+	return &PostgresTokenRevocationService{Pool: pool}
+}
+
+func (s *PostgresTokenRevocationService) Revoke(ctx context.Context, token TokenInfo) (bool, error) {
+	op := GetOperator(ctx, s.Pool)
+	sql := `INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`
+	c, err := op.Exec(ctx, sql, token.Id, token.UserId, token.ExpiresAt.UTC())
+	if err != nil {
+		LoggerFromCtx(ctx).Error("failed to revoke token", zap.Error(err))
+		return false, NewInternalServerError()
+	}
+	s.deleteExpiredTokens(ctx)
+	return c.RowsAffected() > 0, nil
+}
+
+// RevokeAllForUser revokes every token issued to the user up until now
+func (s *PostgresTokenRevocationService) RevokeAllForUser(ctx context.Context, userId int) error {
+	op := GetOperator(ctx, s.Pool)
+	sql := `INSERT INTO user_token_revocations (user_id, revoked_before) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before`
+	_, err := op.Exec(ctx, sql, userId, getRevokedBefore(time.Now()))
+	if err != nil {
+		LoggerFromCtx(ctx).Error("failed to revoke user tokens", zap.Int("userId", userId), zap.Error(err))
+		return NewInternalServerError()
+	}
+	return nil
+}
+
+func (s *PostgresTokenRevocationService) IsRevoked(ctx context.Context, token TokenInfo) (bool, error) {
+	op := GetOperator(ctx, s.Pool)
+	sql := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+	OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before > $3)`
+	var revoked bool
+	err := op.QueryRow(ctx, sql, token.Id, token.UserId, token.IssuedAt.UTC()).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// tokens only carry the millisecond they were issued in, so a revocation covers the millisecond it happened in
+func getRevokedBefore(now time.Time) time.Time {
+	return now.UTC().Truncate(time.Millisecond).Add(time.Millisecond)
+}
+
+// revoked tokens are only kept until they would have expired anyway
+func (s *PostgresTokenRevocationService) deleteExpiredTokens(ctx context.Context) {
+	op := GetOperator(ctx, s.Pool)
+	if _, err := op.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, time.Now().UTC()); err != nil {
+		LoggerFromCtx(ctx).Warn("failed to delete expired revoked tokens", zap.Error(err))
+	}
+}
@@ -15,8 +15,15 @@ type ApiConfig struct {
 	InitialSysAdminPass  string
 	RedisUrl             string
 	Secret               string
+	LockingBackend       string
 }
 
+const (
+	RedisLockingBackend    = "redis"
+	PostgresLockingBackend = "postgres"
+	InMemoryLockingBackend = "memory"
+)
+
 func LoadEnv() ApiConfig {
 	return ApiConfig{
 		Host:                 os.Getenv("HOST_ADDRESS"),
@@ -26,7 +33,15 @@ func LoadEnv() ApiConfig {
 		RedisUrl:             os.Getenv("REDIS_CACHE_URL"),
 		Port:                 os.Getenv("PORT"),
 		Secret:               os.Getenv("SECRET"),
+		LockingBackend:       getEnvOrDefault("LOCKING_BACKEND", RedisLockingBackend),
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
+	return defaultValue
 }
 
 func (c ApiConfig) GetListeningAddress(defaultPort string) string {
@@ -13,6 +13,12 @@ var RegisteredServiceProvider *ServiceProvider
 var RegisteredApiConfig ApiConfig
 
 func main() {
+	common.ConfigEssentials()
+	RegisteredApiConfig = LoadEnv()
+	if args := common.GetCommandArgs(); len(args) > 0 {
+		runCommand(args)
+		return
+	}
 	r := setUp()
 	defer cleanUp()
 	http.ListenAndServe(RegisteredApiConfig.GetListeningAddress("3000"), r)
@@ -28,8 +34,6 @@ func setUserIdExtractor() {
 }
 
 func setUp() chi.Router {
-	common.ConfigEssentials()
-	RegisteredApiConfig = LoadEnv()
 	common.SetSecret(RegisteredApiConfig.Secret)
 	RegisteredServiceProvider = &ServiceProvider{}
 	RegisteredServiceProvider.initiate(RegisteredApiConfig)
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+}
+
+func TestLoadMigrations_OrdersAndPairsFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"sql/0010_add_index.up.sql":   {Data: []byte("CREATE INDEX")},
+		"sql/0002_add_table.up.sql":   {Data: []byte("CREATE TABLE")},
+		"sql/0002_add_table.down.sql": {Data: []byte("DROP TABLE")},
+	}
+	migrations, err := loadMigrations(files, "sql")
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, Migration{Version: 2, Name: "add_table", Up: "CREATE TABLE", Down: "DROP TABLE"}, migrations[0])
+	assert.Equal(t, 10, migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+}
+
+func TestLoadMigrations_InvalidFiles(t *testing.T) {
+	_, err := loadMigrations(fstest.MapFS{"sql/add_table.up.sql": {}}, "sql")
+	assert.Error(t, err)
+	_, err = loadMigrations(fstest.MapFS{"sql/0001_add_table.sql": {}}, "sql")
+	assert.Error(t, err)
+	_, err = loadMigrations(fstest.MapFS{"sql/0001_add_table.down.sql": {Data: []byte("DROP")}}, "sql")
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"go.uber.org/zap"
+)
+
+// migrationLockKey makes sure only one instance migrates at a time
+const migrationLockKey = 7362901
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.getAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.adoptBaseline(ctx, conn, applied); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			common.GetLogger().Info("applying migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			err := m.runInTransaction(ctx, conn, migration.Up,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the latest applied migration only
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.getAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be rolled back", migration.Version, migration.Name)
+			}
+			common.GetLogger().Info("rolling back migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			err := m.runInTransaction(ctx, conn, migration.Down,
+				`DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			return nil
+		}
+		common.GetLogger().Info("no migrations to roll back")
+		return nil
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	err := m.withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.getAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+func (m *Migrator) withMigrationLock(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	_, err = conn.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+	return f(conn)
+}
+
+func (m *Migrator) getAppliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// adoptBaseline marks the baseline as applied on databases that were created from the
+// old init.sql, running it again would fail since every table already exists.
+func (m *Migrator) adoptBaseline(ctx context.Context, conn *pgxpool.Conn, applied map[int]time.Time) error {
+	if len(applied) > 0 || len(m.migrations) == 0 {
+		return nil
+	}
+	var hasSchema bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('public.users') IS NOT NULL`).Scan(&hasSchema); err != nil {
+		return err
+	}
+	if !hasSchema {
+		return nil
+	}
+	baseline := m.migrations[0]
+	common.GetLogger().Info("existing schema found, marking baseline as applied", zap.Int("version", baseline.Version))
+	_, err := conn.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, baseline.Version, baseline.Name)
+	if err != nil {
+		return err
+	}
+	applied[baseline.Version] = time.Now().UTC()
+	return nil
+}
+
+func (m *Migrator) runInTransaction(ctx context.Context, conn *pgxpool.Conn, migrationSql string, recordSql string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	// without arguments pgx uses the simple protocol which allows several statements
+	if _, err := tx.Exec(ctx, migrationSql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, recordSql, args...); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.New("failed to commit migration")
+	}
+	return nil
+}
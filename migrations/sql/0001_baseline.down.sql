@@ -0,0 +1,35 @@
+DROP TABLE IF EXISTS transaction_history CASCADE;
+DROP TABLE IF EXISTS transaction_history_reasons CASCADE;
+
+DROP TABLE IF EXISTS retailer_batches CASCADE;
+DROP TABLE IF EXISTS retailer_contact_info_translations CASCADE;
+DROP TABLE IF EXISTS retailer_contact_info CASCADE;
+DROP TABLE IF EXISTS retailer_translations CASCADE;
+DROP TABLE IF EXISTS retailers CASCADE;
+
+DROP TABLE IF EXISTS batches CASCADE;
+DROP TABLE IF EXISTS recipes CASCADE;
+
+DROP TABLE IF EXISTS product_variant_values CASCADE;
+DROP TABLE IF EXISTS product_variant_translations CASCADE;
+DROP TABLE IF EXISTS product_variants CASCADE;
+DROP TABLE IF EXISTS product_option_values CASCADE;
+DROP TABLE IF EXISTS product_options CASCADE;
+
+DROP TABLE IF EXISTS product_translations CASCADE;
+DROP TABLE IF EXISTS products CASCADE;
+DROP TABLE IF EXISTS category_translations CASCADE;
+DROP TABLE IF EXISTS categories CASCADE;
+
+DROP TABLE IF EXISTS user_warehouses CASCADE;
+DROP TABLE IF EXISTS warehouses CASCADE;
+
+DROP TABLE IF EXISTS unit_conversions CASCADE;
+DROP TABLE IF EXISTS unit_translations CASCADE;
+DROP TABLE IF EXISTS units CASCADE;
+
+DROP TABLE IF EXISTS role_permissions CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
+DROP TABLE IF EXISTS user_permissions CASCADE;
+DROP TABLE IF EXISTS permissions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
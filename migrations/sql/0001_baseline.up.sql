@@ -1,10 +1,4 @@
 -- AUTHENTICATION TABLES --
-DROP TABLE IF EXISTS user_permissions CASCADE;
-DROP TABLE IF EXISTS role_permissions CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
-DROP TABLE IF EXISTS permissions CASCADE;
-DROP TABLE IF EXISTS roles CASCADE;
-
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
     email VARCHAR(255) NOT NULL UNIQUE,
@@ -33,7 +27,6 @@ CREATE TABLE user_permissions (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-
 CREATE TABLE roles (
     id SERIAL PRIMARY KEY,
     name VARCHAR(50) NOT NULL UNIQUE,
@@ -49,18 +42,11 @@ CREATE TABLE role_permissions (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-DROP INDEX IF EXISTS idx_user_permission CASCADE;
-DROP INDEX IF EXISTS idx_role_permission CASCADE;
-
 CREATE UNIQUE INDEX idx_user_permission ON user_permissions(user_id, permission_handle);
 CREATE UNIQUE INDEX idx_role_permission ON role_permissions(role_id, permission_handle);
 -- END AUTHENTICATION TABLES --
 
 -- UNIT TABLES --
-DROP TABLE IF EXISTS units CASCADE;
-DROP TABLE IF EXISTS unit_translations CASCADE;
-DROP TABLE IF EXISTS unit_conversions CASCADE;
-
 CREATE TABLE units (
     id SERIAL PRIMARY KEY,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -82,18 +68,12 @@ CREATE TABLE unit_conversions (
     conversion_factor NUMERIC(12, 6) NOT NULL
 );
 
-DROP INDEX IF EXISTS idx_unit_name CASCADE;
-DROP INDEX IF EXISTS idx_unit_conversion CASCADE;
-
 CREATE UNIQUE INDEX idx_unit_conversion ON unit_conversions(to_unit_id, from_unit_id);
 CREATE UNIQUE INDEX idx_unit_translations ON unit_translations(unit_id, language_code);
 
 -- END UNIT TABLES --
 
 -- WAREHOUSE TABLES --
-DROP TABLE IF EXISTS warehouses CASCADE;
-DROP TABLE IF EXISTS user_warehouses CASCADE;
-
 CREATE TABLE warehouses (
     id SERIAL PRIMARY KEY,
     name VARCHAR(50) NOT NULL,
@@ -109,20 +89,11 @@ CREATE TABLE user_warehouses (
     warehouse_id INTEGER NOT NULL REFERENCES warehouses(id)
 );
 
-DROP INDEX IF EXISTS idx_warehouse_name CASCADE;
-DROP INDEX IF EXISTS idx_user_warehouse CASCADE;
-
 CREATE UNIQUE INDEX idx_warehouse_name ON warehouses(name);
 CREATE UNIQUE INDEX idx_user_warehouse ON user_warehouses(user_id, warehouse_id);
 -- END WAREHOUSE TABLES --
 
-
 -- PRODUCT TABLES --
-DROP TABLE IF EXISTS categories CASCADE;
-DROP TABLE IF EXISTS category_translations CASCADE;
-DROP TABLE IF EXISTS product_translations CASCADE;
-DROP TABLE IF EXISTS products CASCADE;
-
 CREATE TABLE categories (
     id SERIAL PRIMARY KEY,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -154,14 +125,6 @@ CREATE TABLE product_translations (
     language_code VARCHAR(2) NOT NULL DEFAULT 'en'
 );
 
-DROP INDEX IF EXISTS idx_category_translation CASCADE;
-DROP INDEX IF EXISTS idx_product_translation_name CASCADE;
-DROP INDEX IF EXISTS idx_product_translation CASCADE;
-DROP INDEX IF EXISTS idx_product_is_archived CASCADE;
-DROP INDEX IF EXISTS idx_product_category CASCADE;
-DROP INDEX IF EXISTS idx_product_created_at CASCADE;
-DROP INDEX IF EXISTS idx_product_is_ingredient CASCADE;
-
 CREATE UNIQUE INDEX idx_category_translation ON category_translations(category_id, language_code);
 CREATE UNIQUE INDEX idx_product_translation ON product_translations(product_id, language_code);
 CREATE INDEX idx_product_is_archived ON products(is_archived);
@@ -172,14 +135,6 @@ CREATE INDEX idx_product_is_ingredient ON products(is_ingredient);
 -- END PRODUCT TABLES --
 
 -- VARIANT TABLES --
-DROP TABLE IF EXISTS product_options CASCADE;
-DROP TABLE IF EXISTS product_option_translations CASCADE;
-DROP TABLE IF EXISTS product_option_values CASCADE;
-DROP TABLE IF EXISTS product_option_values_translations CASCADE;
-DROP TABLE IF EXISTS product_variants CASCADE;
-DROP TABLE IF EXISTS product_variant_translations CASCADE;
-DROP TABLE IF EXISTS product_variant_values CASCADE;
-
 CREATE TABLE product_options (
     id SERIAL PRIMARY KEY,
     product_id INTEGER NOT NULL REFERENCES products(id),
@@ -187,7 +142,6 @@ CREATE TABLE product_options (
     language_code VARCHAR(2) NOT NULL DEFAULT 'en'
 );
 
-
 create table product_option_values(
     id SERIAL PRIMARY KEY,
     product_option_id INTEGER NOT NULL REFERENCES product_options(id),
@@ -227,18 +181,6 @@ CREATE TABLE product_variant_values (
     product_variant_id INTEGER NOT NULL REFERENCES product_variants(id)
 );
 
-
-DROP INDEX IF EXISTS idx_product_options CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_sku CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_created_at CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_is_archived CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_is_default CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_price CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_translation CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_translation_id CASCADE;
-DROP INDEX IF EXISTS idx_product_variant_values CASCADE;
-DROP INDEX IF EXISTS idx_product_option_values CASCADE;
-
 CREATE UNIQUE INDEX idx_product_options ON product_options(name, language_code, product_id);
 CREATE UNIQUE INDEX idx_product_variant_sku ON product_variants(sku);
 CREATE INDEX idx_product_variant_created_at ON product_variants(created_at);
@@ -252,9 +194,6 @@ CREATE UNIQUE INDEX idx_product_option_values on product_option_values(value, la
 -- END VARIANT TABLES --
 
 -- RECIPE AND BATCHES TABLES --
-DROP TABLE IF EXISTS recipes CASCADE;
-DROP TABLE IF EXISTS batches CASCADE;
-
 CREATE TABLE recipes (
     id SERIAL PRIMARY KEY,
     result_variant_sku VARCHAR(36) NOT NULL REFERENCES product_variants(sku) ON UPDATE CASCADE,
@@ -276,21 +215,12 @@ CREATE TABLE batches (
     expires_at TIMESTAMP NOT NULL
 );
 
-DROP INDEX IF EXISTS idx_recipe CASCADE;
-DROP INDEX IF EXISTS idx_batch CASCADE;
-
 CREATE UNIQUE INDEX idx_recipe ON recipes(result_variant_sku, recipe_variant_sku);
 CREATE UNIQUE INDEX idx_batch ON batches(sku, warehouse_id, expires_at);
 
 -- END RECIPE AND BATCHES TABLES --
 
 -- RETAILER TABLES --
-DROP TABLE IF EXISTS retailers CASCADE;
-DROP TABLE IF EXISTS retailer_translations CASCADE;
-DROP TABLE IF EXISTS retailer_contact_info CASCADE;
-DROP TABLE IF EXISTS retailer_contact_info_translations CASCADE;
-DROP TABLE IF EXISTS retailer_batches CASCADE;
-
 CREATE TABLE retailers (
     id SERIAL PRIMARY KEY,
     lat  DOUBLE PRECISION NOT NULL,
@@ -336,11 +266,6 @@ CREATE TABLE retailer_batches (
     expires_at TIMESTAMP NOT NULL
 );
 
-DROP INDEX IF EXISTS idx_retailer_translation CASCADE;
-DROP INDEX IF EXISTS idx_retailer_contact_info_translation CASCADE;
-DROP INDEX IF EXISTS idx_retailer_contact_info CASCADE;
-DROP INDEX IF EXISTS idx_retailer_batch CASCADE;
-
 CREATE INDEX idx_retailer_translation ON retailer_translations(name, language_code);
 CREATE INDEX idx_retailer_contact_info_translation ON retailer_contact_info_translations(name, language_code);
 CREATE UNIQUE INDEX idx_retailer_contact_info ON retailer_contact_info(retailer_id, phone);
@@ -348,9 +273,6 @@ CREATE UNIQUE INDEX idx_retailer_batch ON retailer_batches(sku, retailer_id, exp
 -- END RETAILER TABLES --
 
 -- TRANSACTIONS TABLES --
-DROP TABLE IF EXISTS transaction_history CASCADE;
-DROP TABLE IF EXISTS transaction_history_reasons CASCADE;
-
 CREATE TABLE transaction_history_reasons (
     id SERIAL PRIMARY KEY,
     name VARCHAR(50) UNIQUE NOT NULL,
@@ -376,8 +298,5 @@ CREATE TABLE transaction_history (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-
-DROP INDEX IF EXISTS idx_transaction_history CASCADE;
-
 CREATE INDEX idx_transaction_history ON transaction_history(batch_id, retailer_batch_id, sku, warehouse_id, retailer_id, user_id, created_at);
 -- END TRANSACTIONS TABLES --
\ No newline at end of file
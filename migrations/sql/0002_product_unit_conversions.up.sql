@@ -0,0 +1,10 @@
+-- databases created from init.sql after the conversions were added already have the table
+CREATE TABLE IF NOT EXISTS product_unit_conversions (
+    id SERIAL PRIMARY KEY,
+    sku VARCHAR(36) NOT NULL REFERENCES product_variants(sku) ON UPDATE CASCADE ON DELETE CASCADE,
+    to_unit_id INTEGER NOT NULL REFERENCES units(id),
+    from_unit_id INTEGER NOT NULL REFERENCES units(id),
+    conversion_factor NUMERIC(12, 6) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_unit_conversion ON product_unit_conversions(sku, to_unit_id, from_unit_id);
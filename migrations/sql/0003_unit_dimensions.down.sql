@@ -0,0 +1 @@
+ALTER TABLE units DROP COLUMN IF EXISTS dimension;
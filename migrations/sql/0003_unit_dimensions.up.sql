@@ -0,0 +1,17 @@
+ALTER TABLE units ADD COLUMN IF NOT EXISTS dimension VARCHAR(10) NOT NULL DEFAULT 'count' CHECK (dimension IN ('mass', 'volume', 'count', 'length'));
+
+-- existing units are classified by their english symbol, anything not recognised stays a count
+UPDATE units u SET dimension = 'mass'
+FROM unit_translations utx
+WHERE utx.unit_id = u.id AND utx.language_code = 'en'
+AND lower(utx.symbol) IN ('mg', 'g', 'kg', 'oz', 'lb');
+
+UPDATE units u SET dimension = 'volume'
+FROM unit_translations utx
+WHERE utx.unit_id = u.id AND utx.language_code = 'en'
+AND lower(utx.symbol) IN ('ml', 'cl', 'dl', 'l', 'tsp', 'tbsp', 'cup', 'gal');
+
+UPDATE units u SET dimension = 'length'
+FROM unit_translations utx
+WHERE utx.unit_id = u.id AND utx.language_code = 'en'
+AND lower(utx.symbol) IN ('mm', 'cm', 'm', 'km', 'in', 'ft');
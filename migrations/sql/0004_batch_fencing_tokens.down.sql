@@ -0,0 +1,2 @@
+ALTER TABLE retailer_batches DROP COLUMN IF EXISTS fencing_token;
+ALTER TABLE batches DROP COLUMN IF EXISTS fencing_token;
@@ -0,0 +1,2 @@
+ALTER TABLE batches ADD COLUMN IF NOT EXISTS fencing_token BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE retailer_batches ADD COLUMN IF NOT EXISTS fencing_token BIGINT NOT NULL DEFAULT 0;
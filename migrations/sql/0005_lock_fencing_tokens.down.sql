@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS lock_fencing_tokens;
@@ -0,0 +1,2 @@
+-- fencing tokens for the postgres locking backend
+CREATE SEQUENCE IF NOT EXISTS lock_fencing_tokens;
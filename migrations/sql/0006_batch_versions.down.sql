@@ -0,0 +1,2 @@
+ALTER TABLE retailer_batches DROP COLUMN IF EXISTS version;
+ALTER TABLE batches DROP COLUMN IF EXISTS version;
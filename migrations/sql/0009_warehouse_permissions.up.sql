@@ -0,0 +1,11 @@
+-- permissions granted to a user only inside one of their warehouses
+CREATE TABLE user_warehouse_permissions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    warehouse_id INTEGER NOT NULL,
+    permission_handle VARCHAR(50) NOT NULL REFERENCES permissions(handle),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id, warehouse_id) REFERENCES user_warehouses(user_id, warehouse_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_warehouse_permission ON user_warehouse_permissions(user_id, warehouse_id, permission_handle);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_retailer_permissions;
+DROP TABLE IF EXISTS user_retailers;
@@ -0,0 +1,26 @@
+CREATE TABLE user_retailers (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    retailer_id INTEGER NOT NULL REFERENCES retailers(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_user_retailer ON user_retailers(user_id, retailer_id);
+CREATE INDEX idx_user_retailer_retailer ON user_retailers(retailer_id);
+
+-- permissions granted to a user only inside one of their retailers
+CREATE TABLE user_retailer_permissions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    retailer_id INTEGER NOT NULL,
+    permission_handle VARCHAR(50) NOT NULL REFERENCES permissions(handle),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id, retailer_id) REFERENCES user_retailers(user_id, retailer_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_retailer_permission ON user_retailer_permissions(user_id, retailer_id, permission_handle);
+
+INSERT INTO permissions (handle, name, description) VALUES
+('has_retailer_control', 'has retailer control', ''),
+('has_retailer_batch_control', 'has retailer batch control', '')
+ON CONFLICT (handle) DO NOTHING;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS api_keys;
+UPDATE users SET email = 'service-account-' || id || '@invalid', is_active = false WHERE email IS NULL;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_required;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS is_service_account;
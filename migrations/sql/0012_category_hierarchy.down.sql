@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_category_translation_name;
+ALTER TABLE category_translations ADD CONSTRAINT category_translations_name_key UNIQUE (name);
+DROP INDEX IF EXISTS idx_category_parent;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
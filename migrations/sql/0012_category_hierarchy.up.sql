@@ -0,0 +1,6 @@
+ALTER TABLE categories ADD COLUMN parent_id INTEGER REFERENCES categories(id);
+CREATE INDEX idx_category_parent ON categories(parent_id);
+
+-- names only need to be unique within a language
+ALTER TABLE category_translations DROP CONSTRAINT IF EXISTS category_translations_name_key;
+CREATE UNIQUE INDEX idx_category_translation_name ON category_translations(name, language_code);
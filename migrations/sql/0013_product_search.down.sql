@@ -0,0 +1,12 @@
+DROP FUNCTION IF EXISTS search_product_variants(TEXT, TEXT);
+DROP INDEX IF EXISTS idx_product_variant_barcode_search_trgm;
+DROP INDEX IF EXISTS idx_product_variant_sku_search_trgm;
+DROP INDEX IF EXISTS idx_product_option_value_search_fts;
+DROP INDEX IF EXISTS idx_product_option_value_search_trgm;
+DROP INDEX IF EXISTS idx_product_variant_translation_search_fts;
+DROP INDEX IF EXISTS idx_product_variant_translation_search_trgm;
+DROP INDEX IF EXISTS idx_product_translation_search_fts;
+DROP INDEX IF EXISTS idx_product_translation_search_trgm;
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
+DROP INDEX IF EXISTS idx_product_variant_barcode;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,78 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE product_variants ADD COLUMN barcode VARCHAR(64);
+CREATE UNIQUE INDEX idx_product_variant_barcode ON product_variants(barcode);
+
+-- folds case, arabic diacritics, tatweel and letter variants so spelling differences still match
+CREATE OR REPLACE FUNCTION search_normalize(input TEXT) RETURNS TEXT AS $$
+    SELECT lower(
+        translate(
+            regexp_replace(coalesce(input, ''), '[ً-ٰٟـ]', '', 'g'),
+            'أإآٱىةؤئ',
+            'اااايهوي'
+        )
+    );
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX idx_product_translation_search_trgm ON product_translations
+    USING GIN (search_normalize(name) gin_trgm_ops);
+CREATE INDEX idx_product_translation_search_fts ON product_translations
+    USING GIN (to_tsvector('simple', search_normalize(name)));
+CREATE INDEX idx_product_variant_translation_search_trgm ON product_variant_translations
+    USING GIN (search_normalize(name) gin_trgm_ops);
+CREATE INDEX idx_product_variant_translation_search_fts ON product_variant_translations
+    USING GIN (to_tsvector('simple', search_normalize(name)));
+CREATE INDEX idx_product_option_value_search_trgm ON product_option_values
+    USING GIN (search_normalize(value) gin_trgm_ops);
+CREATE INDEX idx_product_option_value_search_fts ON product_option_values
+    USING GIN (to_tsvector('simple', search_normalize(value)));
+CREATE INDEX idx_product_variant_sku_search_trgm ON product_variants
+    USING GIN (lower(sku) gin_trgm_ops);
+CREATE INDEX idx_product_variant_barcode_search_trgm ON product_variants
+    USING GIN (lower(barcode) gin_trgm_ops);
+
+-- ranks every variant matching the term in any language, prefix_query is a to_tsquery expression
+-- built from the term; variant names weigh more than product names which weigh more than option values,
+-- an exact sku or barcode match always ranks first
+CREATE OR REPLACE FUNCTION search_product_variants(term TEXT, prefix_query TEXT)
+RETURNS TABLE (product_variant_id INTEGER, rank NUMERIC) AS $$
+    SELECT matches.product_variant_id, round(max(matches.score)::numeric, 6) AS rank
+    FROM (
+        SELECT pvartx.product_variant_id,
+            ts_rank(to_tsvector('simple', search_normalize(pvartx.name)), to_tsquery('simple', search_normalize(prefix_query)))
+            + word_similarity(search_normalize(term), search_normalize(pvartx.name)) AS score
+        FROM product_variant_translations pvartx
+        WHERE to_tsvector('simple', search_normalize(pvartx.name)) @@ to_tsquery('simple', search_normalize(prefix_query))
+        OR search_normalize(term) <% search_normalize(pvartx.name)
+        UNION ALL
+        SELECT pvar.id,
+            0.8 * (
+                ts_rank(to_tsvector('simple', search_normalize(ptx.name)), to_tsquery('simple', search_normalize(prefix_query)))
+                + word_similarity(search_normalize(term), search_normalize(ptx.name))
+            ) AS score
+        FROM product_translations ptx
+        JOIN product_variants pvar ON pvar.product_id = ptx.product_id
+        WHERE to_tsvector('simple', search_normalize(ptx.name)) @@ to_tsquery('simple', search_normalize(prefix_query))
+        OR search_normalize(term) <% search_normalize(ptx.name)
+        UNION ALL
+        SELECT pvv.product_variant_id,
+            0.6 * (
+                ts_rank(to_tsvector('simple', search_normalize(pov.value)), to_tsquery('simple', search_normalize(prefix_query)))
+                + word_similarity(search_normalize(term), search_normalize(pov.value))
+            ) AS score
+        FROM product_option_values pov
+        JOIN product_variant_values pvv ON pvv.product_option_value_id = pov.id
+        WHERE to_tsvector('simple', search_normalize(pov.value)) @@ to_tsquery('simple', search_normalize(prefix_query))
+        OR search_normalize(term) <% search_normalize(pov.value)
+        UNION ALL
+        SELECT pvar.id,
+            CASE
+                WHEN lower(pvar.sku) = lower(term) OR lower(pvar.barcode) = lower(term) THEN 2
+                ELSE greatest(similarity(lower(pvar.sku), lower(term)), similarity(coalesce(lower(pvar.barcode), ''), lower(term)))
+            END AS score
+        FROM product_variants pvar
+        WHERE lower(pvar.sku) % lower(term) OR lower(pvar.barcode) % lower(term)
+        OR lower(pvar.sku) = lower(term) OR lower(pvar.barcode) = lower(term)
+    ) matches
+    GROUP BY matches.product_variant_id;
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1 @@
+ALTER TABLE batches DROP COLUMN IF EXISTS lot_code;
@@ -0,0 +1,5 @@
+ALTER TABLE batches ADD COLUMN lot_code VARCHAR(64);
+
+INSERT INTO transaction_history_reasons (name, description, is_positive)
+VALUES ('openingBalance', 'Opening balance', TRUE)
+ON CONFLICT (name) DO NOTHING;
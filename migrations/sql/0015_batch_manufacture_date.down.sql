@@ -0,0 +1,2 @@
+ALTER TABLE retailer_batches DROP COLUMN IF EXISTS manufactured_at;
+ALTER TABLE batches DROP COLUMN IF EXISTS manufactured_at;
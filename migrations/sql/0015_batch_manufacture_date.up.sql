@@ -0,0 +1,2 @@
+ALTER TABLE batches ADD COLUMN manufactured_at TIMESTAMP;
+ALTER TABLE retailer_batches ADD COLUMN manufactured_at TIMESTAMP;
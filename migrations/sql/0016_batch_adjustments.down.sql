@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_batch;
+CREATE UNIQUE INDEX idx_batch ON batches(sku, warehouse_id, expires_at);
+ALTER TABLE batches DROP COLUMN IF EXISTS parent_batch_id;
@@ -0,0 +1,17 @@
+ALTER TABLE batches ADD COLUMN parent_batch_id INTEGER REFERENCES batches(id);
+
+-- batches split off another batch share its sku and expiry date, received goods only go to the original batch
+DROP INDEX IF EXISTS idx_batch;
+CREATE UNIQUE INDEX idx_batch ON batches(sku, warehouse_id, expires_at) WHERE parent_batch_id IS NULL;
+
+INSERT INTO transaction_history_reasons (name, description, is_positive) VALUES
+('splitOut', 'Split into another batch', FALSE),
+('splitIn', 'Split from another batch', TRUE),
+('mergeOut', 'Merged into another batch', FALSE),
+('mergeIn', 'Merged from another batch', TRUE),
+('redated', 'Expiry date changed', FALSE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (handle, name, description) VALUES
+('can_redate_batch', 'can redate batch', '')
+ON CONFLICT (handle) DO NOTHING;
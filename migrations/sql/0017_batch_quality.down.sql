@@ -0,0 +1,11 @@
+ALTER TABLE retailer_batches
+    DROP COLUMN IF EXISTS quality_changed_at,
+    DROP COLUMN IF EXISTS quality_changed_by,
+    DROP COLUMN IF EXISTS quality_reason,
+    DROP COLUMN IF EXISTS quality_status;
+
+ALTER TABLE batches
+    DROP COLUMN IF EXISTS quality_changed_at,
+    DROP COLUMN IF EXISTS quality_changed_by,
+    DROP COLUMN IF EXISTS quality_reason,
+    DROP COLUMN IF EXISTS quality_status;
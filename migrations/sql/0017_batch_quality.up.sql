@@ -0,0 +1,17 @@
+ALTER TABLE batches
+    ADD COLUMN quality_status VARCHAR(20) NOT NULL DEFAULT 'available'
+        CHECK (quality_status IN ('available', 'quarantined', 'on_hold', 'rejected')),
+    ADD COLUMN quality_reason VARCHAR(255),
+    ADD COLUMN quality_changed_by INTEGER REFERENCES users(id),
+    ADD COLUMN quality_changed_at TIMESTAMP;
+
+ALTER TABLE retailer_batches
+    ADD COLUMN quality_status VARCHAR(20) NOT NULL DEFAULT 'available'
+        CHECK (quality_status IN ('available', 'quarantined', 'on_hold', 'rejected')),
+    ADD COLUMN quality_reason VARCHAR(255),
+    ADD COLUMN quality_changed_by INTEGER REFERENCES users(id),
+    ADD COLUMN quality_changed_at TIMESTAMP;
+
+INSERT INTO permissions (handle, name, description) VALUES
+('can_release_batch', 'can release batch', '')
+ON CONFLICT (handle) DO NOTHING;
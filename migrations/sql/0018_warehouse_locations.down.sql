@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_batch_location;
+ALTER TABLE batches DROP COLUMN IF EXISTS location_id;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS storage_class;
+DROP TABLE IF EXISTS warehouse_locations;
@@ -0,0 +1,28 @@
+-- zones hold aisles and aisles hold bins, a location without a temperature class takes the class of its parent
+CREATE TABLE warehouse_locations (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    parent_id INTEGER REFERENCES warehouse_locations(id),
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('zone', 'aisle', 'bin')),
+    code VARCHAR(32) NOT NULL,
+    temperature_class VARCHAR(10) CHECK (temperature_class IN ('ambient', 'chilled', 'frozen')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_warehouse_location_code ON warehouse_locations(warehouse_id, code);
+CREATE INDEX idx_warehouse_location_parent ON warehouse_locations(parent_id);
+
+ALTER TABLE product_variants ADD COLUMN storage_class VARCHAR(10) NOT NULL DEFAULT 'ambient'
+    CHECK (storage_class IN ('ambient', 'chilled', 'frozen'));
+
+-- batches without a location are received but not put away yet
+ALTER TABLE batches ADD COLUMN location_id INTEGER REFERENCES warehouse_locations(id);
+CREATE INDEX idx_batch_location ON batches(location_id);
+
+INSERT INTO transaction_history_reasons (name, description, is_positive) VALUES
+('locationMove', 'Moved to another location', FALSE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (handle, name, description) VALUES
+('has_location_control', 'has location control', '')
+ON CONFLICT (handle) DO NOTHING;
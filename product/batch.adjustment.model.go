@@ -0,0 +1,112 @@
+package product
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+const maxMergedBatches = 50
+
+type BatchSplitInput struct {
+	BatchId  int     `json:"batchId"`
+	Quantity float64 `json:"quantity"`
+	UnitId   int     `json:"unitId"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+// BatchMergeInput merges the batches into the batch with BatchId which keeps its expiry date
+type BatchMergeInput struct {
+	BatchId  int    `json:"batchId"`
+	BatchIds []int  `json:"batchIds"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type BatchRedateInput struct {
+	BatchId   int       `json:"batchId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Comment   string    `json:"comment,omitempty"`
+}
+
+type BatchSplitResult struct {
+	BatchId int `json:"batchId"`
+}
+
+func ValidateBatchSplitInput(input BatchSplitInput) error {
+	return getBatchAdjustmentValidationError(
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateNotZero(input.Quantity, "quantity"),
+		common.ValidateId(input.UnitId, "unitId"),
+		common.ValidateStringLength(input.Comment, "comment", 0, 200),
+	)
+}
+
+func ValidateBatchMergeInput(input BatchMergeInput) error {
+	validationResults := []common.ErrorDetails{
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateSliceSize(input.BatchIds, "batchIds", 1, maxMergedBatches),
+		common.ValidateStringLength(input.Comment, "comment", 0, 200),
+	}
+	found := map[int]bool{input.BatchId: true}
+	for i, id := range input.BatchIds {
+		field := "batchIds[" + strconv.Itoa(i) + "]"
+		if found[id] {
+			validationResults = append(validationResults, common.ErrorDetails{
+				Message: field + " is repeated",
+				Field:   field,
+			})
+		}
+		found[id] = true
+	}
+	return getBatchAdjustmentValidationError(validationResults...)
+}
+
+func ValidateBatchRedateInput(input BatchRedateInput) error {
+	return getBatchAdjustmentValidationError(
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateTime(input.ExpiresAt, "expiresAt"),
+		common.ValidateStringLength(input.Comment, "comment", 0, 200),
+	)
+}
+
+func getBatchAdjustmentValidationError(validationResults ...common.ErrorDetails) error {
+	errors := make([]common.ErrorDetails, 0)
+	for _, result := range validationResults {
+		if len(result.Message) > 0 {
+			errors = append(errors, result)
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid batch adjustment", errors...)
+	}
+	return nil
+}
+
+// getMergedBatchError checks the batches can be merged into the target, merged stock keeps the
+// expiry date of the target so it has to expire first
+func getMergedBatchError(target BatchBase, batch BatchBase) error {
+	id := strconv.Itoa(*batch.Id)
+	if batch.Sku != target.Sku {
+		return common.NewBadRequestError("batch "+id+" is not of the same sku", "batch_sku_mismatch")
+	}
+	if batch.ExpiresAt.Before(target.ExpiresAt) {
+		return common.NewBadRequestError("batch "+id+" expires before the batch it is merged into", "batch_expires_first")
+	}
+	if batch.Quantity <= 0 {
+		return common.NewBadRequestError("batch "+id+" is empty", "batch_empty")
+	}
+	// merging held stock into available stock would release it
+	if batch.Quality.Status != target.Quality.Status {
+		return common.NewBadRequestError("batch "+id+" does not have the same quality status", "batch_quality_mismatch")
+	}
+	return nil
+}
+
+func getRedateComment(from, to time.Time, comment string) string {
+	redateComment := "expiry changed from " + from.Format(time.DateOnly) + " to " + to.Format(time.DateOnly)
+	if comment != "" {
+		redateComment += ": " + comment
+	}
+	return redateComment
+}
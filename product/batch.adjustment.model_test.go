@@ -0,0 +1,39 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBatchMergeInput(t *testing.T) {
+	assert.NoError(t, ValidateBatchMergeInput(BatchMergeInput{BatchId: 1, BatchIds: []int{2, 3}}))
+	assert.Error(t, ValidateBatchMergeInput(BatchMergeInput{BatchId: 1}))
+	err := ValidateBatchMergeInput(BatchMergeInput{BatchId: 1, BatchIds: []int{2, 1}})
+	assert.Error(t, err)
+	assert.Equal(t, "batchIds[1]", err.(*common.ApiError).Errors[0].Field)
+}
+
+func TestGetMergedBatchError(t *testing.T) {
+	targetId, batchId := 1, 2
+	expiresAt := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	target := BatchBase{Id: &targetId, Sku: "sku-1", Quantity: 1, ExpiresAt: expiresAt}
+	batch := BatchBase{Id: &batchId, Sku: "sku-1", Quantity: 2, ExpiresAt: expiresAt.AddDate(0, 0, 1)}
+	assert.NoError(t, getMergedBatchError(target, batch))
+	assert.Error(t, getMergedBatchError(target, batch.SetExpiresAt(expiresAt.AddDate(0, 0, -1))))
+	assert.Error(t, getMergedBatchError(target, batch.SetQuantity(0)))
+	quarantined := batch
+	quarantined.Quality.Status = BatchQualityQuarantined
+	assert.Error(t, getMergedBatchError(target, quarantined))
+	batch.Sku = "sku-2"
+	assert.Error(t, getMergedBatchError(target, batch))
+}
+
+func TestGetRedateComment(t *testing.T) {
+	from := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "expiry changed from 2024-05-10 to 2024-06-01", getRedateComment(from, to, ""))
+	assert.Equal(t, "expiry changed from 2024-05-10 to 2024-06-01: lab passed", getRedateComment(from, to, "lab passed"))
+}
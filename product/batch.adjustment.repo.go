@@ -0,0 +1,76 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+	"go.uber.org/zap"
+)
+
+func (r *BatchRepository) GetBatchBasesByIds(ctx context.Context, ids []int) (map[int]BatchBase, error) {
+	sql := `
+	select id, warehouse_id, sku, quantity, unit_id, expires_at, parent_batch_id, location_id, version, quality_status
+	from batches where id = any($1) and warehouse_id = $2
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, ids, warehouse.GetWarehouseId(ctx))
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to get batch bases", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get batch bases")
+	}
+	defer rows.Close()
+	batchBases := make(map[int]BatchBase)
+	for rows.Next() {
+		var batchBase BatchBase
+		err := rows.Scan(
+			&batchBase.Id, &batchBase.WarehouseId, &batchBase.Sku, &batchBase.Quantity,
+			&batchBase.UnitId, &batchBase.ExpiresAt, &batchBase.ParentBatchId, &batchBase.LocationId, &batchBase.Version,
+			&batchBase.Quality.Status,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("Failed to scan batch bases", zap.Error(err))
+			return nil, common.NewBadRequestFromMessage("Failed to scan batch bases")
+		}
+		batchBases[*batchBase.Id] = batchBase
+	}
+	return batchBases, nil
+}
+
+// ReserveBatchId takes the id of a batch before it is created
+func (r *BatchRepository) ReserveBatchId(ctx context.Context) (int, error) {
+	sql := `select nextval(pg_get_serial_sequence('batches', 'id'))`
+	op := common.GetOperator(ctx, r.Pool)
+	var id int
+	if err := op.QueryRow(ctx, sql).Scan(&id); err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to reserve batch id", zap.Error(err))
+		return 0, common.NewBadRequestFromMessage("Failed to reserve batch id")
+	}
+	return id, nil
+}
+
+// GetOriginalBatchId returns the batch of the sku that expires at the date and was not split off
+// another batch, received goods go to that batch so there can only be one
+func (r *BatchRepository) GetOriginalBatchId(ctx context.Context, sku string, expiresAt time.Time) (*int, error) {
+	sql := `
+	select id from batches
+	where sku = $1 and warehouse_id = $2 and expires_at = $3 and parent_batch_id is null
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, sku, warehouse.GetWarehouseId(ctx), common.GetUtcDateOnlyStringFromTime(expiresAt))
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to get batch by expiry date", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get batch by expiry date")
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, nil
+	}
+	var id int
+	if err := rows.Scan(&id); err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to scan batch by expiry date", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get batch by expiry date")
+	}
+	return &id, nil
+}
@@ -0,0 +1,254 @@
+package product
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+)
+
+// SplitBatch moves part of a batch into a new batch that keeps the sku, expiry date and lot of the batch,
+// e.g. to quarantine the damaged part of a delivery
+func (s *BatchService) SplitBatch(ctx context.Context, input BatchSplitInput) (BatchSplitResult, error) {
+	if err := ValidateBatchSplitInput(input); err != nil {
+		return BatchSplitResult{}, err
+	}
+	var result BatchSplitResult
+	err := common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, bulkBatchUpdateInfo, err := s.lockBatchAdjustment(ctx, input.BatchId, nil)
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		batchBase := batchBases[input.BatchId]
+		batchVariantMetaInfo := bulkBatchUpdateInfo.BatchVariantMetaInfoLookup[batchBase.Sku]
+		batchVariantMetaInfo.UnitId = batchBase.UnitId
+		convertedBatchInput, err := s.convertBatchInput(ctx, BatchInput{
+			Sku:      batchBase.Sku,
+			Quantity: input.Quantity,
+			UnitId:   input.UnitId,
+		}, batchVariantMetaInfo)
+		if err != nil {
+			return err
+		}
+		if convertedBatchInput.Quantity >= batchBase.Quantity {
+			return common.NewBadRequestError("split quantity must be less than the batch quantity", "insufficient_quantity")
+		}
+		splitBatchId, err := s.batchRepo.ReserveBatchId(ctx)
+		if err != nil {
+			return err
+		}
+		result.BatchId = splitBatchId
+		totalCost := batchVariantMetaInfo.Cost * convertedBatchInput.Quantity
+		return s.processBulkBatchUnitOfWork(ctx, BulkBatchUpdateUnitOfWork{
+			BatchUpdateRequestLookup: map[string]BatchUpdateRequest{
+				strconv.Itoa(input.BatchId): {
+					BatchId:    batchBase.Id,
+					NewValue:   batchBase.Quantity - convertedBatchInput.Quantity,
+					Reason:     transactions.TransactionReasonTypeSplitOut,
+					Sku:        batchBase.Sku,
+					ModifiedBy: convertedBatchInput.Quantity,
+					Version:    batchBase.Version,
+				},
+			},
+			BatchSplitRequests: []BatchSplitRequest{{
+				Id:            splitBatchId,
+				ParentBatchId: input.BatchId,
+				Quantity:      convertedBatchInput.Quantity,
+			}},
+			BatchTransactionHistory: []transactions.CreateWarehouseTransactionCommand{
+				{
+					BatchId:  input.BatchId,
+					Quantity: convertedBatchInput.Quantity,
+					UnitId:   batchBase.UnitId,
+					Reason:   transactions.TransactionReasonTypeSplitOut,
+					Cost:     totalCost,
+					Comment:  input.Comment,
+					Sku:      batchBase.Sku,
+				},
+				{
+					BatchId:  splitBatchId,
+					Quantity: convertedBatchInput.Quantity,
+					UnitId:   batchBase.UnitId,
+					Reason:   transactions.TransactionReasonTypeSplitIn,
+					Cost:     totalCost,
+					Comment:  input.Comment,
+					Sku:      batchBase.Sku,
+				},
+			},
+			FencingToken: common.GetFencingToken(bulkBatchUpdateInfo.locks),
+		})
+	})
+	if err != nil {
+		return BatchSplitResult{}, err
+	}
+	return result, nil
+}
+
+// MergeBatches empties the batches into the batch with the earliest expiry date
+func (s *BatchService) MergeBatches(ctx context.Context, input BatchMergeInput) error {
+	if err := ValidateBatchMergeInput(input); err != nil {
+		return err
+	}
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, bulkBatchUpdateInfo, err := s.lockBatchAdjustment(ctx, input.BatchId, input.BatchIds)
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		target := batchBases[input.BatchId]
+		batchVariantMetaInfo := bulkBatchUpdateInfo.BatchVariantMetaInfoLookup[target.Sku]
+		batchUpdateRequestLookup := make(map[string]BatchUpdateRequest)
+		transactionHistory := make([]transactions.CreateWarehouseTransactionCommand, 0)
+		mergedQuantity := 0.0
+		for _, id := range input.BatchIds {
+			batchBase := batchBases[id]
+			if err := getMergedBatchError(target, batchBase); err != nil {
+				return err
+			}
+			convertedQuantity, err := s.convertBatchQuantity(ctx, batchBase, target.UnitId)
+			if err != nil {
+				return err
+			}
+			mergedQuantity += convertedQuantity
+			totalCost := batchVariantMetaInfo.Cost * convertedQuantity
+			batchUpdateRequestLookup[strconv.Itoa(id)] = BatchUpdateRequest{
+				BatchId:    batchBase.Id,
+				NewValue:   0,
+				Reason:     transactions.TransactionReasonTypeMergeOut,
+				Sku:        batchBase.Sku,
+				ModifiedBy: batchBase.Quantity,
+				Version:    batchBase.Version,
+			}
+			transactionHistory = append(transactionHistory,
+				transactions.CreateWarehouseTransactionCommand{
+					BatchId:  id,
+					Quantity: batchBase.Quantity,
+					UnitId:   batchBase.UnitId,
+					Reason:   transactions.TransactionReasonTypeMergeOut,
+					Cost:     totalCost,
+					Comment:  input.Comment,
+					Sku:      batchBase.Sku,
+				},
+				transactions.CreateWarehouseTransactionCommand{
+					BatchId:  input.BatchId,
+					Quantity: convertedQuantity,
+					UnitId:   target.UnitId,
+					Reason:   transactions.TransactionReasonTypeMergeIn,
+					Cost:     totalCost,
+					Comment:  input.Comment,
+					Sku:      target.Sku,
+				},
+			)
+		}
+		batchUpdateRequestLookup[strconv.Itoa(input.BatchId)] = BatchUpdateRequest{
+			BatchId:    target.Id,
+			NewValue:   target.Quantity + mergedQuantity,
+			Reason:     transactions.TransactionReasonTypeMergeIn,
+			Sku:        target.Sku,
+			ModifiedBy: mergedQuantity,
+			Version:    target.Version,
+		}
+		return s.processBulkBatchUnitOfWork(ctx, BulkBatchUpdateUnitOfWork{
+			BatchUpdateRequestLookup: batchUpdateRequestLookup,
+			BatchTransactionHistory:  transactionHistory,
+			FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
+		})
+	})
+}
+
+// RedateBatch changes the expiry date of a batch after it was inspected again
+func (s *BatchService) RedateBatch(ctx context.Context, input BatchRedateInput) error {
+	if err := ValidateBatchRedateInput(input); err != nil {
+		return err
+	}
+	expiryDate := common.GetUtcDateOnlyFromTime(input.ExpiresAt)
+	if expiryDate.Before(common.GetUtcDateOnlyFromTime(time.Now())) {
+		return common.NewBadRequestError("expiresAt cannot be in the past", "batch_expired")
+	}
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, bulkBatchUpdateInfo, err := s.lockBatchAdjustment(ctx, input.BatchId, nil)
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		batchBase := batchBases[input.BatchId]
+		if batchBase.Quantity <= 0 {
+			return common.NewBadRequestError("batch is empty", "batch_empty")
+		}
+		if expiryDate.Equal(common.GetUtcDateOnlyFromTime(batchBase.ExpiresAt)) {
+			return common.NewBadRequestError("batch already expires at that date", "batch_same_expiry")
+		}
+		if batchBase.ParentBatchId == nil {
+			originalBatchId, err := s.batchRepo.GetOriginalBatchId(ctx, batchBase.Sku, expiryDate)
+			if err != nil {
+				return err
+			}
+			if originalBatchId != nil {
+				return common.NewBadRequestError(
+					"batch "+strconv.Itoa(*originalBatchId)+" of the sku already expires at that date, merge into it instead",
+					"batch_exists",
+				)
+			}
+		}
+		batchVariantMetaInfo := bulkBatchUpdateInfo.BatchVariantMetaInfoLookup[batchBase.Sku]
+		return s.processBulkBatchUnitOfWork(ctx, BulkBatchUpdateUnitOfWork{
+			BatchRedateRequests: []BatchRedateRequest{{
+				BatchId:    input.BatchId,
+				ExpiryDate: expiryDate,
+				Version:    batchBase.Version,
+			}},
+			BatchTransactionHistory: []transactions.CreateWarehouseTransactionCommand{{
+				BatchId:  input.BatchId,
+				Quantity: batchBase.Quantity,
+				UnitId:   batchBase.UnitId,
+				Reason:   transactions.TransactionReasonTypeRedated,
+				Cost:     batchVariantMetaInfo.Cost * batchBase.Quantity,
+				Comment:  getRedateComment(batchBase.ExpiresAt, expiryDate, input.Comment),
+				Sku:      batchBase.Sku,
+			}},
+			FencingToken: common.GetFencingToken(bulkBatchUpdateInfo.locks),
+		})
+	})
+}
+
+// lockBatchAdjustment gets the batches being adjusted and locks them together with their sku
+func (s *BatchService) lockBatchAdjustment(
+	ctx context.Context,
+	batchId int,
+	otherBatchIds []int,
+) (
+	map[int]BatchBase,
+	BulkBatchUpdateInfo,
+	error,
+) {
+	ids := append([]int{batchId}, otherBatchIds...)
+	batchBases, err := s.batchRepo.GetBatchBasesByIds(ctx, ids)
+	if err != nil {
+		return nil, BulkBatchUpdateInfo{}, err
+	}
+	for _, id := range ids {
+		if _, ok := batchBases[id]; !ok {
+			return nil, BulkBatchUpdateInfo{}, common.NewNotFoundError("batch " + strconv.Itoa(id) + " not found")
+		}
+	}
+	sku := batchBases[batchId].Sku
+	bulkBatchUpdateInfo, err := s.lockBatchUpdateRequest(ctx, BulkBatchUpdateInfo{
+		Ids:     ids,
+		SkuList: []string{sku},
+	})
+	if err != nil {
+		return nil, bulkBatchUpdateInfo, err
+	}
+	bulkBatchUpdateInfo.BatchVariantMetaInfoLookup, err = s.batchRepo.GetBatchVariantMetaInfo(ctx, []string{sku})
+	if err != nil {
+		return nil, bulkBatchUpdateInfo, err
+	}
+	if _, ok := bulkBatchUpdateInfo.BatchVariantMetaInfoLookup[sku]; !ok {
+		return nil, bulkBatchUpdateInfo, common.NewBadRequestFromMessage("variant meta info not found")
+	}
+	return batchBases, bulkBatchUpdateInfo, nil
+}
@@ -2,6 +2,7 @@ package product
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
@@ -44,6 +45,105 @@ func (c BatchController) IncrementBatchWithRecipe(w http.ResponseWriter, r *http
 	})
 }
 
+func (c BatchController) ImportOpeningBalance(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[OpeningBalanceInput](w, r.Body, func(input OpeningBalanceInput) {
+		err := c.batchService.ImportOpeningBalance(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Opening balance imported successfully",
+		})
+	})
+}
+
+func (c BatchController) SplitBatch(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchSplitInput](w, r.Body, func(input BatchSplitInput) {
+		result, err := c.batchService.SplitBatch(r.Context(), input)
+		common.WriteResponse(common.Result[BatchSplitResult]{
+			Error:  err,
+			Writer: w,
+			Data:   result,
+		})
+	})
+}
+
+func (c BatchController) MergeBatches(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchMergeInput](w, r.Body, func(input BatchMergeInput) {
+		err := c.batchService.MergeBatches(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batches merged successfully",
+		})
+	})
+}
+
+func (c BatchController) RedateBatch(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchRedateInput](w, r.Body, func(input BatchRedateInput) {
+		err := c.batchService.RedateBatch(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch expiry date changed successfully",
+		})
+	})
+}
+
+func (c BatchController) SetBatchQuality(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchQualityInput](w, r.Body, func(input BatchQualityInput) {
+		err := c.batchService.SetBatchQuality(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch quality status changed successfully",
+		})
+	})
+}
+
+func (c BatchController) ReleaseBatch(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchReleaseInput](w, r.Body, func(input BatchReleaseInput) {
+		err := c.batchService.ReleaseBatch(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch released successfully",
+		})
+	})
+}
+
+func (c BatchController) MoveBatch(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[BatchMoveInput](w, r.Body, func(input BatchMoveInput) {
+		err := c.batchService.MoveBatch(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch moved successfully",
+		})
+	})
+}
+
+func (c BatchController) CreatePickList(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[PickListInput](w, r.Body, func(input PickListInput) {
+		pickList, err := c.batchService.CreatePickList(r.Context(), input)
+		common.WriteResponse(common.Result[PickList]{
+			Error:  err,
+			Writer: w,
+			Data:   pickList,
+		})
+	})
+}
+
+func (c BatchController) ConfirmPick(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[ConfirmPickInput](w, r.Body, func(input ConfirmPickInput) {
+		err := c.batchService.ConfirmPick(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Picks confirmed successfully",
+		})
+	})
+}
+
 func (c BatchController) DecrementBatch(w http.ResponseWriter, r *http.Request) {
 	common.ParseBody[BatchInput](w, r.Body, func(input BatchInput) {
 		err := c.batchService.DecrementBatch(r.Context(), input)
@@ -118,7 +218,8 @@ func (c BatchController) BulkDecrementBatch(w http.ResponseWriter, r *http.Reque
 }
 
 func (c BatchController) GetBatches(w http.ResponseWriter, r *http.Request) {
-	batchesPage, err := c.batchService.GetBatches(r.Context())
+	locationId, _ := strconv.Atoi(r.URL.Query().Get("locationId"))
+	batchesPage, err := c.batchService.GetBatches(r.Context(), BatchFilter{LocationId: locationId})
 	common.WriteResponse[common.PaginatedResponse[Batch]](
 		common.Result[common.PaginatedResponse[Batch]]{
 			Error:  err,
@@ -43,6 +43,7 @@ func (s *BatchService) processBulkBatchDecrement(
 	bulkBatchUpdateUnitOfWork := BulkBatchUpdateUnitOfWork{
 		BatchUpdateRequestLookup: batchUpdateRequestLookup,
 		BatchTransactionHistory:  transactionHistory,
+		FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
 	}
 	return s.processBulkBatchUnitOfWork(ctx, bulkBatchUpdateUnitOfWork)
 }
@@ -62,6 +63,9 @@ func (s *BatchService) createDecrementBatchesUpdateRequest(
 		if !ok {
 			return nil, nil, common.NewBadRequestFromMessage("batch to update not found")
 		}
+		if !CanDecrementBatch(batchBase.Quality, batchInput.Reason) {
+			return nil, nil, GetBatchNotAvailableError(batchBase.Quality)
+		}
 		batchVariantMetaInfo, ok := bulkUpdateBatchInfo.BatchVariantMetaInfoLookup[batchInput.Sku]
 		if !ok {
 			return nil, nil, common.NewBadRequestFromMessage("variant meta info not found")
@@ -81,6 +85,7 @@ func (s *BatchService) createDecrementBatchesUpdateRequest(
 			Reason:     convertedBatchInput.Reason,
 			Sku:        convertedBatchInput.Sku,
 			ModifiedBy: convertedBatchInput.Quantity,
+			Version:    batchBase.Version,
 		}
 		transactionCommand := transactions.CreateWarehouseTransactionCommand{
 			BatchId:  *batchBase.Id,
@@ -50,7 +50,7 @@ func (r *BatchRepository) extractBatchInfoFromInputs(inputs []BatchInput) (
 	batchToCreateLookup = make(map[string]BatchInput)
 	for _, input := range inputs {
 		if input.Id == nil {
-			batchToCreateLookup[input.Sku] = input
+			batchToCreateLookup[GetBatchCreateKey(input.Sku, input.ExpiresAt, input.ManufacturedAt)] = input
 		} else {
 			ids = append(ids, *input.Id)
 			batchToUpdateLookup[input.Sku] = input
@@ -74,7 +74,9 @@ func (r *BatchRepository) getBatchesBasedOnSkuListAndIds(
 		batches.warehouse_id as warehouse_id,
 		batches.sku as batch_sku,
 		batches.quantity as batch_qty,
-		batches.unit_id as batch_unit_id
+		batches.unit_id as batch_unit_id,
+		batches.version as batch_version,
+		batches.quality_status as batch_quality_status
 	from
 		batches
 	where
@@ -100,7 +102,8 @@ func (r *BatchRepository) getProductMetaInfoFromSkuList(
 		product_variants.sku as pvar_sku,
 		product_variants.standard_unit_id as pvar_unit,
 		product_variants.expires_in_days as pvar_expires_in,
-		product_variants.price as pvar_price
+		product_variants.price as pvar_price,
+		product_variants.storage_class as pvar_storage_class
 	from
 		product_variants
 	where
@@ -129,8 +132,10 @@ func (r *BatchRepository) parseBatchBasesLookupFromResults(
 		var batchSku *string
 		var batchQty *float64
 		var batchUnitId *int
+		var batchVersion *int
+		var batchQualityStatus *string
 		err := rows.Scan(
-			&batchId, &warehouseId, &batchSku, &batchQty, &batchUnitId,
+			&batchId, &warehouseId, &batchSku, &batchQty, &batchUnitId, &batchVersion, &batchQualityStatus,
 		)
 		if err != nil {
 			common.GetLogger().Error("Failed to scan batch bases", zap.Error(err))
@@ -140,6 +145,7 @@ func (r *BatchRepository) parseBatchBasesLookupFromResults(
 			batchSku != nil &&
 			batchQty != nil &&
 			batchUnitId != nil &&
+			batchVersion != nil &&
 			warehouseId != nil {
 			batch := BatchBase{
 				Id:          batchId,
@@ -147,6 +153,10 @@ func (r *BatchRepository) parseBatchBasesLookupFromResults(
 				Sku:         *batchSku,
 				Quantity:    *batchQty,
 				UnitId:      *batchUnitId,
+				Version:     *batchVersion,
+			}
+			if batchQualityStatus != nil {
+				batch.Quality.Status = *batchQualityStatus
 			}
 			batchBasesLookup[batch.Sku] = batch
 		}
@@ -172,8 +182,9 @@ func (r *BatchRepository) parseBatchVariantMetaInfoLookupFromResults(
 		var metaUnitId *int
 		var metaExpiresInDays *int
 		var metaCost *float64
+		var metaStorageClass string
 		err := rows.Scan(
-			&metaSku, &metaUnitId, &metaExpiresInDays, &metaCost,
+			&metaSku, &metaUnitId, &metaExpiresInDays, &metaCost, &metaStorageClass,
 		)
 		if err != nil {
 			common.GetLogger().Error("Failed to scan batch bases", zap.Error(err))
@@ -187,6 +198,7 @@ func (r *BatchRepository) parseBatchVariantMetaInfoLookupFromResults(
 				UnitId:        *metaUnitId,
 				ExpiresInDays: *metaExpiresInDays,
 				Cost:          *metaCost,
+				StorageClass:  metaStorageClass,
 			}
 			batchVariantMetaInfoLookup[*metaSku] = batchVariantMetaInfo
 		}
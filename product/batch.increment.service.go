@@ -61,6 +61,7 @@ func (s *BatchService) processBulkBatchIncrement(
 		BatchUpdateRequestLookup: batchUpdateRequestLookup,
 		BatchCreateRequestLookup: batchCreateRequestLookup,
 		BatchTransactionHistory:  transactionHistory,
+		FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
 	}
 	return s.processBulkBatchUnitOfWork(ctx, bulkBatchUpdateUnitOfWork)
 }
@@ -96,6 +97,7 @@ func (s *BatchService) createIncrementBatchesUpdateRequest(
 			Reason:     convertedBatchInput.Reason,
 			Sku:        convertedBatchInput.Sku,
 			ModifiedBy: convertedBatchInput.Quantity,
+			Version:    batchBase.Version,
 		}
 		transactionCommand := transactions.CreateWarehouseTransactionCommand{
 			BatchId:  *batchBase.Id,
@@ -130,13 +132,23 @@ func (s *BatchService) createBatchCreateRequest(
 		if err != nil {
 			return nil, nil, err
 		}
+		expiryDate, err := GetBatchExpiryDate(
+			batchInput.ExpiresAt,
+			batchInput.ManufacturedAt,
+			batchVariantMetaInfo.ExpiresInDays,
+			time.Now(),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
 		totalCost := batchVariantMetaInfo.Cost * convertedBatchInput.Quantity
-		expiryDate := time.Now().AddDate(0, 0, batchVariantMetaInfo.ExpiresInDays)
-		batchCreateRequestLookup[convertedBatchInput.Sku] = BatchCreateRequest{
-			BatchSku:   convertedBatchInput.Sku,
-			Quantity:   convertedBatchInput.Quantity,
-			UnitId:     batchVariantMetaInfo.UnitId,
-			ExpiryDate: expiryDate,
+		createKey := GetBatchCreateKey(convertedBatchInput.Sku, batchInput.ExpiresAt, batchInput.ManufacturedAt)
+		batchCreateRequestLookup[createKey] = BatchCreateRequest{
+			BatchSku:       convertedBatchInput.Sku,
+			Quantity:       convertedBatchInput.Quantity,
+			UnitId:         batchVariantMetaInfo.UnitId,
+			ExpiryDate:     expiryDate,
+			ManufacturedAt: GetBatchManufactureDate(batchInput.ManufacturedAt),
 		}
 		transactionCommand := transactions.CreateWarehouseTransactionCommand{
 			Quantity: convertedBatchInput.Quantity,
@@ -0,0 +1,37 @@
+package product
+
+import (
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+// BatchFilter narrows the batch listing, a 0 location matches every batch
+type BatchFilter struct {
+	LocationId int
+}
+
+// BatchMoveInput puts a batch away or moves it to another bin, the whole batch is moved
+// so part of a batch has to be split off first
+type BatchMoveInput struct {
+	BatchId    int    `json:"batchId"`
+	LocationId int    `json:"locationId"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+func ValidateBatchMoveInput(input BatchMoveInput) error {
+	return getBatchAdjustmentValidationError(
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateId(input.LocationId, "locationId"),
+		common.ValidateStringLength(input.Comment, "comment", 0, 200),
+	)
+}
+
+func getLocationMoveComment(from, to string, comment string) string {
+	if from == "" {
+		from = "unassigned"
+	}
+	moveComment := "moved from " + from + " to " + to
+	if comment != "" {
+		moveComment += ": " + comment
+	}
+	return moveComment
+}
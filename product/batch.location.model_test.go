@@ -0,0 +1,17 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBatchMoveInput(t *testing.T) {
+	assert.NoError(t, ValidateBatchMoveInput(BatchMoveInput{BatchId: 1, LocationId: 2}))
+	assert.Error(t, ValidateBatchMoveInput(BatchMoveInput{BatchId: 1}))
+}
+
+func TestGetLocationMoveComment(t *testing.T) {
+	assert.Equal(t, "moved from unassigned to A-01-01", getLocationMoveComment("", "A-01-01", ""))
+	assert.Equal(t, "moved from A-01-01 to B-02-01: restock", getLocationMoveComment("A-01-01", "B-02-01", "restock"))
+}
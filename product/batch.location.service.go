@@ -0,0 +1,71 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+)
+
+func (s *BatchService) MoveBatch(ctx context.Context, input BatchMoveInput) error {
+	if err := ValidateBatchMoveInput(input); err != nil {
+		return err
+	}
+	path, err := s.warehouseService.GetLocationPath(ctx, input.LocationId)
+	if err != nil {
+		return err
+	}
+	location := path[0]
+	if location.Kind != warehouse.LocationKindBin {
+		return common.NewBadRequestError("batches can only be stored in a bin", "location_not_bin")
+	}
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, bulkBatchUpdateInfo, err := s.lockBatchAdjustment(ctx, input.BatchId, nil)
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		batchBase := batchBases[input.BatchId]
+		if batchBase.Quantity <= 0 {
+			return common.NewBadRequestError("batch is empty", "batch_empty")
+		}
+		if batchBase.LocationId != nil && *batchBase.LocationId == input.LocationId {
+			return common.NewBadRequestError("batch is already stored in "+location.Code, "batch_same_location")
+		}
+		batchVariantMetaInfo := bulkBatchUpdateInfo.BatchVariantMetaInfoLookup[batchBase.Sku]
+		if !warehouse.CanStoreInLocation(batchVariantMetaInfo.StorageClass, path) {
+			return common.NewBadRequestError(
+				batchBase.Sku+" has to be stored "+batchVariantMetaInfo.StorageClass+", "+location.Code+" is "+
+					warehouse.GetLocationTemperatureClass(path),
+				"location_storage_class",
+			)
+		}
+		from := ""
+		if batchBase.LocationId != nil {
+			fromPath, err := s.warehouseService.GetLocationPath(ctx, *batchBase.LocationId)
+			if err != nil {
+				return err
+			}
+			from = fromPath[0].Code
+		}
+		return s.processBulkBatchUnitOfWork(ctx, BulkBatchUpdateUnitOfWork{
+			BatchMoveRequests: []BatchMoveRequest{{
+				BatchId:    input.BatchId,
+				LocationId: input.LocationId,
+				Version:    batchBase.Version,
+			}},
+			BatchTransactionHistory: []transactions.CreateWarehouseTransactionCommand{{
+				BatchId:  input.BatchId,
+				Quantity: batchBase.Quantity,
+				UnitId:   batchBase.UnitId,
+				Reason:   transactions.TransactionReasonTypeLocationMove,
+				Cost:     batchVariantMetaInfo.Cost * batchBase.Quantity,
+				Comment:  getLocationMoveComment(from, location.Code, input.Comment),
+				Sku:      batchBase.Sku,
+			}},
+			FencingToken: common.GetFencingToken(bulkBatchUpdateInfo.locks),
+		})
+	})
+}
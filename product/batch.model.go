@@ -15,15 +15,25 @@ type BatchInput struct {
 	UnitId   int     `json:"unitId"`
 	Reason   string  `json:"reason,omitempty"`
 	Comment  string  `json:"comment,omitempty"`
+	// ExpiresAt and ManufacturedAt are the dates printed on received goods, when both are missing
+	// the batch expires after the shelf life of the variant
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	ManufacturedAt *time.Time `json:"manufacturedAt,omitempty"`
 }
 
 type BatchBase struct {
-	Id          *int      `json:"id,omitempty"`
-	WarehouseId *int      `json:"warehouseId,omitempty"`
-	Sku         string    `json:"sku"`
-	Quantity    float64   `json:"quantity"`
-	UnitId      int       `json:"unitId"`
-	ExpiresAt   time.Time `json:"expiresAt"`
+	Id             *int         `json:"id,omitempty"`
+	WarehouseId    *int         `json:"warehouseId,omitempty"`
+	Sku            string       `json:"sku"`
+	Quantity       float64      `json:"quantity"`
+	UnitId         int          `json:"unitId"`
+	ExpiresAt      time.Time    `json:"expiresAt"`
+	LotCode        string       `json:"lotCode,omitempty"`
+	ManufacturedAt *time.Time   `json:"manufacturedAt,omitempty"`
+	ParentBatchId  *int         `json:"parentBatchId,omitempty"`
+	LocationId     *int         `json:"locationId,omitempty"`
+	Quality        BatchQuality `json:"quality"`
+	Version        int          `json:"version,omitempty"`
 }
 
 type Batch struct {
@@ -32,6 +42,8 @@ type Batch struct {
 	Unit               unit.Unit           `json:"unit"`
 	ProductName        string              `json:"productName"`
 	IsIngredient       bool                `json:"isIngredient"`
+	LocationCode       string              `json:"locationCode,omitempty"`
+	Category           *Category           `json:"category,omitempty"`
 }
 
 func (b BatchBase) SetQuantity(quantity float64) BatchBase {
@@ -82,6 +94,73 @@ func ValidateBatchInputIncrement(input BatchInput) error {
 	return nil
 }
 
+// GetBatchExpiryDate resolves the expiry date of received goods and checks it against the shelf life
+// of the variant, goods can not expire later than their shelf life allows from when they were made
+func GetBatchExpiryDate(expiresAt, manufacturedAt *time.Time, expiresInDays int, now time.Time) (time.Time, error) {
+	today := common.GetUtcDateOnlyFromTime(now)
+	if manufacturedAt != nil && common.GetUtcDateOnlyFromTime(*manufacturedAt).After(today) {
+		return time.Time{}, common.NewValidationError("invalid batch dates", common.ErrorDetails{
+			Message: "manufacturedAt cannot be in the future",
+			Field:   "manufacturedAt",
+		})
+	}
+	madeAt := today
+	if manufacturedAt != nil {
+		madeAt = common.GetUtcDateOnlyFromTime(*manufacturedAt)
+	}
+	latestExpiryDate := madeAt.AddDate(0, 0, expiresInDays)
+	if expiresAt == nil && manufacturedAt == nil {
+		return now.AddDate(0, 0, expiresInDays), nil
+	}
+	if expiresAt == nil {
+		expiresAt = &latestExpiryDate
+	}
+	expiryDate := common.GetUtcDateOnlyFromTime(*expiresAt)
+	details := make([]common.ErrorDetails, 0)
+	if manufacturedAt != nil && !expiryDate.After(madeAt) {
+		details = append(details, common.ErrorDetails{
+			Message: "expiresAt must be after the manufacture date",
+			Field:   "expiresAt",
+		})
+	}
+	if expiryDate.After(latestExpiryDate) {
+		details = append(details, common.ErrorDetails{
+			Message: "expiresAt is later than the shelf life of " + strconv.Itoa(expiresInDays) + " days allows",
+			Field:   "expiresAt",
+		})
+	}
+	if expiryDate.Before(today) {
+		details = append(details, common.ErrorDetails{
+			Message: "batch has already expired",
+			Field:   "expiresAt",
+		})
+	}
+	if len(details) > 0 {
+		return time.Time{}, common.NewValidationError("invalid batch dates", details...)
+	}
+	return expiryDate, nil
+}
+
+func GetBatchManufactureDate(manufacturedAt *time.Time) *time.Time {
+	if manufacturedAt == nil {
+		return nil
+	}
+	manufactureDate := common.GetUtcDateOnlyFromTime(*manufacturedAt)
+	return &manufactureDate
+}
+
+// GetBatchCreateKey keeps inputs of the same sku with different dates apart since they go to different batches
+func GetBatchCreateKey(sku string, expiresAt, manufacturedAt *time.Time) string {
+	key := sku
+	if expiresAt != nil {
+		key += ":" + common.GetUtcDateOnlyStringFromTime(*expiresAt)
+	}
+	if manufacturedAt != nil {
+		key += ":made:" + common.GetUtcDateOnlyStringFromTime(*manufacturedAt)
+	}
+	return key
+}
+
 func ValidateBatchInputsDecrement(inputs []BatchInput) error {
 	if len(inputs) == 0 {
 		return common.NewValidationError(
@@ -121,6 +200,12 @@ func ValidateBatchInputDecrement(input BatchInput) error {
 	return nil
 }
 
+func (b *Batch) setCategory(category Category) {
+	if category.Id != nil {
+		b.Category = &category
+	}
+}
+
 func (b Batch) GetCursorValue() []string {
 	return []string{
 		common.GetUtcDateOnlyStringFromTime(b.ExpiresAt),
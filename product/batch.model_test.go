@@ -0,0 +1,51 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBatchExpiryDate(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	expiryDate, err := GetBatchExpiryDate(nil, nil, 30, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 30), expiryDate)
+
+	manufacturedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	expiryDate, err = GetBatchExpiryDate(nil, &manufacturedAt, 30, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), expiryDate)
+
+	expiresAt := time.Date(2024, 5, 20, 8, 0, 0, 0, time.UTC)
+	expiryDate, err = GetBatchExpiryDate(&expiresAt, &manufacturedAt, 30, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), expiryDate)
+}
+
+func TestGetBatchExpiryDateChecksShelfLife(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	tooLate := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	_, err := GetBatchExpiryDate(&tooLate, nil, 30, now)
+	assert.Error(t, err)
+
+	expired := time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC)
+	_, err = GetBatchExpiryDate(&expired, nil, 30, now)
+	assert.Error(t, err)
+
+	future := time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)
+	_, err = GetBatchExpiryDate(nil, &future, 30, now)
+	assert.Error(t, err)
+
+	manufacturedAt := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	_, err = GetBatchExpiryDate(&manufacturedAt, &manufacturedAt, 30, now)
+	assert.Error(t, err)
+}
+
+func TestGetBatchCreateKey(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "sku-1", GetBatchCreateKey("sku-1", nil, nil))
+	assert.NotEqual(t, GetBatchCreateKey("sku-1", nil, nil), GetBatchCreateKey("sku-1", &expiresAt, nil))
+	assert.NotEqual(t, GetBatchCreateKey("sku-1", &expiresAt, nil), GetBatchCreateKey("sku-1", nil, &expiresAt))
+}
@@ -0,0 +1,110 @@
+package product
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+const maxOpeningBalanceLines = 5000
+
+type OpeningBalanceOverrideKey struct{}
+
+// OpeningBalanceLine is a batch as it already exists in the warehouse, the cost is per unit of the line
+type OpeningBalanceLine struct {
+	Sku       string  `json:"sku"`
+	Quantity  float64 `json:"quantity"`
+	UnitId    int     `json:"unitId"`
+	ExpiresAt string  `json:"expiresAt"`
+	Cost      float64 `json:"cost"`
+	LotCode   string  `json:"lotCode,omitempty"`
+}
+
+// OpeningBalanceInput can only be imported into an empty warehouse unless override is set
+type OpeningBalanceInput struct {
+	Override bool                 `json:"override"`
+	Lines    []OpeningBalanceLine `json:"lines"`
+}
+
+type OpeningBalanceBatch struct {
+	Sku       string
+	Quantity  float64
+	UnitId    int
+	ExpiresAt time.Time
+	LotCode   *string
+}
+
+// parseOpeningBalanceExpiry accepts dates such as 2024-12-31 as well as full timestamps
+func parseOpeningBalanceExpiry(value string) (time.Time, bool) {
+	if expiresAt, err := time.Parse(time.DateOnly, value); err == nil {
+		return expiresAt, true
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	return expiresAt, err == nil
+}
+
+func getOpeningBalanceLineKey(sku string, expiresAt time.Time) string {
+	return sku + ":" + common.GetUtcDateOnlyStringFromTime(expiresAt)
+}
+
+func getOpeningBalanceSkus(lines []OpeningBalanceLine) []string {
+	found := make(map[string]bool)
+	skus := make([]string, 0)
+	for _, line := range lines {
+		if !found[line.Sku] {
+			found[line.Sku] = true
+			skus = append(skus, line.Sku)
+		}
+	}
+	return skus
+}
+
+func ValidateOpeningBalanceInput(input OpeningBalanceInput) error {
+	sizeErr := common.ValidateSliceSize(input.Lines, "lines", 1, maxOpeningBalanceLines)
+	if len(sizeErr.Message) > 0 {
+		return common.NewValidationError("invalid opening balance", sizeErr)
+	}
+	errors := make([]common.ErrorDetails, 0)
+	lineKeys := make(map[string]bool)
+	for i, line := range input.Lines {
+		errors = append(errors, validateOpeningBalanceLine(line, "lines["+strconv.Itoa(i)+"].", lineKeys)...)
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid opening balance", errors...)
+	}
+	return nil
+}
+
+// validateOpeningBalanceLine records the sku and expiry date of the line in lineKeys, batches are
+// unique per sku and expiry date so two lines with the same ones would end up in the same batch
+func validateOpeningBalanceLine(line OpeningBalanceLine, prefix string, lineKeys map[string]bool) []common.ErrorDetails {
+	validationResults := []common.ErrorDetails{
+		common.ValidateStringLength(line.Sku, prefix+"sku", 10, 36),
+		common.ValidateNotZero(line.Quantity, prefix+"quantity"),
+		common.ValidateId(line.UnitId, prefix+"unitId"),
+		common.ValidateNotZero(line.Cost, prefix+"cost"),
+		common.ValidateStringLength(line.LotCode, prefix+"lotCode", 0, 64),
+	}
+	expiresAt, ok := parseOpeningBalanceExpiry(line.ExpiresAt)
+	if !ok {
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: prefix + "expiresAt must be a date such as 2024-12-31",
+			Field:   prefix + "expiresAt",
+		})
+	} else if key := getOpeningBalanceLineKey(line.Sku, expiresAt); lineKeys[key] {
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: "sku and expiry date are repeated in another line",
+			Field:   prefix + "expiresAt",
+		})
+	} else {
+		lineKeys[key] = true
+	}
+	errors := make([]common.ErrorDetails, 0)
+	for _, result := range validationResults {
+		if len(result.Message) > 0 {
+			errors = append(errors, result)
+		}
+	}
+	return errors
+}
@@ -0,0 +1,36 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOpeningBalanceExpiry(t *testing.T) {
+	expiresAt, ok := parseOpeningBalanceExpiry("2024-12-31")
+	assert.True(t, ok)
+	assert.Equal(t, "2024-12-31", expiresAt.Format("2006-01-02"))
+	_, ok = parseOpeningBalanceExpiry("2024-12-31T10:00:00Z")
+	assert.True(t, ok)
+	_, ok = parseOpeningBalanceExpiry("31/12/2024")
+	assert.False(t, ok)
+}
+
+func TestValidateOpeningBalanceInput(t *testing.T) {
+	line := OpeningBalanceLine{Sku: "sku-0000001", Quantity: 2, UnitId: 1, ExpiresAt: "2024-12-31", Cost: 1.5}
+	assert.NoError(t, ValidateOpeningBalanceInput(OpeningBalanceInput{Lines: []OpeningBalanceLine{line}}))
+	assert.Error(t, ValidateOpeningBalanceInput(OpeningBalanceInput{}))
+
+	repeated := line
+	repeated.ExpiresAt = "2024-12-31T00:00:00Z"
+	err := ValidateOpeningBalanceInput(OpeningBalanceInput{Lines: []OpeningBalanceLine{line, repeated}})
+	assert.Error(t, err)
+	assert.Equal(t, "lines[1].expiresAt", err.(*common.ApiError).Errors[0].Field)
+
+	repeated.ExpiresAt = "2025-01-31"
+	repeated.Cost = 0
+	err = ValidateOpeningBalanceInput(OpeningBalanceInput{Lines: []OpeningBalanceLine{line, repeated}})
+	assert.Error(t, err)
+	assert.Equal(t, "lines[1].cost", err.(*common.ApiError).Errors[0].Field)
+}
@@ -0,0 +1,85 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+	"go.uber.org/zap"
+)
+
+// LockWarehouse holds the warehouse row until the transaction ends so imports into the
+// same warehouse can not both find it empty
+func (r *BatchRepository) LockWarehouse(ctx context.Context) error {
+	sql := `select id from warehouses where id = $1 for update`
+	op := common.GetOperator(ctx, r.Pool)
+	var id int
+	err := op.QueryRow(ctx, sql, warehouse.GetWarehouseId(ctx)).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return common.NewNotFoundError("Warehouse not found")
+	}
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to lock warehouse", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to lock warehouse")
+	}
+	return nil
+}
+
+func (r *BatchRepository) HasWarehouseBatches(ctx context.Context) (bool, error) {
+	sql := `select exists(select 1 from batches where warehouse_id = $1)`
+	op := common.GetOperator(ctx, r.Pool)
+	var hasBatches bool
+	err := op.QueryRow(ctx, sql, warehouse.GetWarehouseId(ctx)).Scan(&hasBatches)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to check warehouse batches", zap.Error(err))
+		return false, common.NewBadRequestFromMessage("Failed to check warehouse batches")
+	}
+	return hasBatches, nil
+}
+
+func (r *BatchRepository) GetBatchVariantMetaInfo(ctx context.Context, skus []string) (map[string]BatchVariantMetaInfo, error) {
+	pgxBatch := &pgx.Batch{}
+	r.getProductMetaInfoFromSkuList(pgxBatch, skus)
+	op := common.GetOperator(ctx, r.Pool)
+	results := op.SendBatch(ctx, pgxBatch)
+	defer results.Close()
+	return r.parseBatchVariantMetaInfoLookupFromResults(results)
+}
+
+// CreateOpeningBalanceBatch adds to the batch of the same sku and expiry date when the
+// warehouse already has one, which only happens when the opening balance is overridden
+func (r *BatchRepository) CreateOpeningBalanceBatch(
+	ctx context.Context,
+	batch OpeningBalanceBatch,
+	fencingToken int64,
+) (int, error) {
+	sql := `
+	INSERT INTO batches (sku, warehouse_id, quantity, unit_id, expires_at, lot_code, fencing_token)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (sku, warehouse_id, expires_at) WHERE parent_batch_id IS NULL DO UPDATE SET
+		quantity = batches.quantity + EXCLUDED.quantity,
+		lot_code = COALESCE(EXCLUDED.lot_code, batches.lot_code),
+		fencing_token = EXCLUDED.fencing_token,
+		version = batches.version + 1,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE batches.fencing_token <= EXCLUDED.fencing_token
+	RETURNING id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	row := op.QueryRow(
+		ctx, sql, batch.Sku, warehouse.GetWarehouseId(ctx), batch.Quantity, batch.UnitId,
+		common.GetUtcDateOnlyStringFromTime(batch.ExpiresAt), batch.LotCode, fencingToken,
+	)
+	var id int
+	err := row.Scan(&id)
+	if err == pgx.ErrNoRows {
+		common.LoggerFromCtx(ctx).Warn("opening balance rejected by fencing token", zap.Int64("fencing_token", fencingToken))
+		return 0, common.NewConflictError("Batch was modified by another request, please try again")
+	}
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to create opening balance batch", zap.Error(err))
+		return 0, common.NewBadRequestFromMessage("Failed to create opening balance batch")
+	}
+	return id, nil
+}
@@ -0,0 +1,96 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+)
+
+// ImportOpeningBalance loads the stock a warehouse already holds with the real expiry dates
+// and costs of its batches instead of deriving them from the product variant
+func (s *BatchService) ImportOpeningBalance(ctx context.Context, input OpeningBalanceInput) error {
+	if err := ValidateOpeningBalanceInput(input); err != nil {
+		return err
+	}
+	skus := getOpeningBalanceSkus(input.Lines)
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		bulkBatchUpdateInfo, lockErr := s.lockBatchUpdateRequest(ctx, BulkBatchUpdateInfo{SkuList: skus})
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if lockErr != nil {
+			return lockErr
+		}
+		// the sku locks do not cover imports of other skus into the same warehouse
+		if err := s.batchRepo.LockWarehouse(ctx); err != nil {
+			return err
+		}
+		if !input.Override {
+			hasBatches, err := s.batchRepo.HasWarehouseBatches(ctx)
+			if err != nil {
+				return err
+			}
+			if hasBatches {
+				return common.NewBadRequestError(
+					"warehouse already has stock, override is required to import an opening balance",
+					"warehouse_not_empty",
+				)
+			}
+		}
+		batchVariantMetaInfoLookup, err := s.batchRepo.GetBatchVariantMetaInfo(ctx, skus)
+		if err != nil {
+			return err
+		}
+		fencingToken := common.GetFencingToken(bulkBatchUpdateInfo.locks)
+		for _, line := range input.Lines {
+			if err := s.createOpeningBalanceBatch(ctx, line, batchVariantMetaInfoLookup, fencingToken); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BatchService) createOpeningBalanceBatch(
+	ctx context.Context,
+	line OpeningBalanceLine,
+	batchVariantMetaInfoLookup map[string]BatchVariantMetaInfo,
+	fencingToken int64,
+) error {
+	batchVariantMetaInfo, ok := batchVariantMetaInfoLookup[line.Sku]
+	if !ok {
+		return common.NewNotFoundError("product variant " + line.Sku + " not found")
+	}
+	convertedBatchInput, err := s.convertBatchInput(ctx, BatchInput{
+		Sku:      line.Sku,
+		Quantity: line.Quantity,
+		UnitId:   line.UnitId,
+	}, batchVariantMetaInfo)
+	if err != nil {
+		return err
+	}
+	expiresAt, _ := parseOpeningBalanceExpiry(line.ExpiresAt)
+	var lotCode *string
+	if line.LotCode != "" {
+		lotCode = &line.LotCode
+	}
+	batchId, err := s.batchRepo.CreateOpeningBalanceBatch(ctx, OpeningBalanceBatch{
+		Sku:       convertedBatchInput.Sku,
+		Quantity:  convertedBatchInput.Quantity,
+		UnitId:    batchVariantMetaInfo.UnitId,
+		ExpiresAt: expiresAt,
+		LotCode:   lotCode,
+	}, fencingToken)
+	if err != nil {
+		return err
+	}
+	return s.transactionService.CreateWarehouseTransaction(ctx, transactions.CreateWarehouseTransactionCommand{
+		BatchId:  batchId,
+		Quantity: convertedBatchInput.Quantity,
+		UnitId:   batchVariantMetaInfo.UnitId,
+		Reason:   transactions.TransactionReasonTypeOpeningBalance,
+		Cost:     line.Cost * line.Quantity,
+		Comment:  line.LotCode,
+		Sku:      convertedBatchInput.Sku,
+	})
+}
@@ -0,0 +1,173 @@
+package product
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+const maxPickListLines = 500
+
+// PickListInput is the stock needed for a retailer order or a production plan, every sku is listed once
+type PickListInput struct {
+	Lines []PickListLine `json:"lines"`
+}
+
+type PickListLine struct {
+	Sku      string  `json:"sku"`
+	Quantity float64 `json:"quantity"`
+	UnitId   int     `json:"unitId"`
+}
+
+// PickListItem is the quantity to take from a batch, quantities are in the standard unit of the variant
+type PickListItem struct {
+	BatchId      int       `json:"batchId"`
+	Sku          string    `json:"sku"`
+	Quantity     float64   `json:"quantity"`
+	UnitId       int       `json:"unitId"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	LocationId   *int      `json:"locationId,omitempty"`
+	LocationCode string    `json:"locationCode,omitempty"`
+}
+
+type PickListShortage struct {
+	Sku      string  `json:"sku"`
+	Quantity float64 `json:"quantity"`
+	UnitId   int     `json:"unitId"`
+}
+
+type PickList struct {
+	Items     []PickListItem     `json:"items"`
+	Shortages []PickListShortage `json:"shortages,omitempty"`
+}
+
+// ConfirmPickInput takes the items that were actually picked out of their batches
+type ConfirmPickInput struct {
+	Reason  string            `json:"reason"`
+	Comment string            `json:"comment,omitempty"`
+	Items   []PickConfirmItem `json:"items"`
+}
+
+type PickConfirmItem struct {
+	BatchId  int     `json:"batchId"`
+	Quantity float64 `json:"quantity"`
+	UnitId   int     `json:"unitId"`
+}
+
+func ValidatePickListInput(input PickListInput) error {
+	sizeErr := common.ValidateSliceSize(input.Lines, "lines", 1, maxPickListLines)
+	if len(sizeErr.Message) > 0 {
+		return common.NewValidationError("invalid pick list", sizeErr)
+	}
+	validationResults := make([]common.ErrorDetails, 0)
+	skus := make(map[string]bool)
+	for i, line := range input.Lines {
+		prefix := "lines[" + strconv.Itoa(i) + "]."
+		validationResults = append(validationResults,
+			common.ValidateStringLength(line.Sku, prefix+"sku", 10, 36),
+			common.ValidateNotZero(line.Quantity, prefix+"quantity"),
+			common.ValidateId(line.UnitId, prefix+"unitId"),
+		)
+		if skus[line.Sku] {
+			validationResults = append(validationResults, common.ErrorDetails{
+				Message: "sku is repeated in another line",
+				Field:   prefix + "sku",
+			})
+		}
+		skus[line.Sku] = true
+	}
+	return getPickValidationError("invalid pick list", validationResults)
+}
+
+func ValidateConfirmPickInput(input ConfirmPickInput) error {
+	sizeErr := common.ValidateSliceSize(input.Items, "items", 1, maxPickListLines)
+	if len(sizeErr.Message) > 0 {
+		return common.NewValidationError("invalid pick confirmation", sizeErr)
+	}
+	validationResults := []common.ErrorDetails{
+		common.ValidateAlphanuemericName(input.Reason, "reason"),
+		common.ValidateStringLength(input.Comment, "comment", 0, 200),
+	}
+	batchIds := make(map[int]bool)
+	for i, item := range input.Items {
+		prefix := "items[" + strconv.Itoa(i) + "]."
+		validationResults = append(validationResults,
+			common.ValidateId(item.BatchId, prefix+"batchId"),
+			common.ValidateNotZero(item.Quantity, prefix+"quantity"),
+			common.ValidateId(item.UnitId, prefix+"unitId"),
+		)
+		if batchIds[item.BatchId] {
+			validationResults = append(validationResults, common.ErrorDetails{
+				Message: "batch is repeated in another item",
+				Field:   prefix + "batchId",
+			})
+		}
+		batchIds[item.BatchId] = true
+	}
+	return getPickValidationError("invalid pick confirmation", validationResults)
+}
+
+func getPickValidationError(message string, validationResults []common.ErrorDetails) error {
+	errors := make([]common.ErrorDetails, 0)
+	for _, result := range validationResults {
+		if len(result.Message) > 0 {
+			errors = append(errors, result)
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError(message, errors...)
+	}
+	return nil
+}
+
+// pickBatches takes the quantity out of the batches in the order they are given, which is
+// earliest expiry first, whatever the batches can not cover is returned as the shortage
+func pickBatches(quantity float64, unitId int, batches []Batch) ([]PickListItem, float64) {
+	items := make([]PickListItem, 0)
+	for _, batch := range batches {
+		if quantity <= 0 {
+			break
+		}
+		if batch.Quantity <= 0 {
+			continue
+		}
+		picked := quantity
+		if batch.Quantity < picked {
+			picked = batch.Quantity
+		}
+		quantity -= picked
+		items = append(items, PickListItem{
+			BatchId:      *batch.Id,
+			Sku:          batch.Sku,
+			Quantity:     picked,
+			UnitId:       unitId,
+			ExpiresAt:    batch.ExpiresAt,
+			LocationId:   batch.LocationId,
+			LocationCode: batch.LocationCode,
+		})
+	}
+	if quantity < 0 {
+		quantity = 0
+	}
+	return items, quantity
+}
+
+// sortPickListItems walks the warehouse in location code order so a picker passes every bin once,
+// batches that are not put away yet are picked last
+func sortPickListItems(items []PickListItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.LocationCode == "") != (b.LocationCode == "") {
+			return b.LocationCode == ""
+		}
+		if a.LocationCode != b.LocationCode {
+			return a.LocationCode < b.LocationCode
+		}
+		if a.Sku != b.Sku {
+			return a.Sku < b.Sku
+		}
+		return a.ExpiresAt.Before(b.ExpiresAt)
+	})
+}
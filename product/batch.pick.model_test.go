@@ -0,0 +1,60 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickBatches(t *testing.T) {
+	first, second, third := 1, 2, 3
+	expiresAt := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	batches := []Batch{
+		{BatchBase: BatchBase{Id: &first, Sku: "sku-1", Quantity: 4, ExpiresAt: expiresAt}},
+		{BatchBase: BatchBase{Id: &second, Sku: "sku-1", Quantity: 0, ExpiresAt: expiresAt.AddDate(0, 0, 1)}},
+		{BatchBase: BatchBase{Id: &third, Sku: "sku-1", Quantity: 10, ExpiresAt: expiresAt.AddDate(0, 0, 2)}, LocationCode: "A-01-01"},
+	}
+	items, shortage := pickBatches(6, 1, batches)
+	assert.Equal(t, 0.0, shortage)
+	assert.Len(t, items, 2)
+	assert.Equal(t, first, items[0].BatchId)
+	assert.Equal(t, 4.0, items[0].Quantity)
+	assert.Equal(t, third, items[1].BatchId)
+	assert.Equal(t, 2.0, items[1].Quantity)
+	assert.Equal(t, "A-01-01", items[1].LocationCode)
+
+	items, shortage = pickBatches(20, 1, batches)
+	assert.Len(t, items, 2)
+	assert.Equal(t, 6.0, shortage)
+}
+
+func TestSortPickListItems(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	items := []PickListItem{
+		{BatchId: 1, Sku: "sku-1"},
+		{BatchId: 2, Sku: "sku-2", LocationCode: "B-01-01"},
+		{BatchId: 3, Sku: "sku-1", LocationCode: "A-02-01", ExpiresAt: expiresAt.AddDate(0, 0, 1)},
+		{BatchId: 4, Sku: "sku-1", LocationCode: "A-02-01", ExpiresAt: expiresAt},
+	}
+	sortPickListItems(items)
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.BatchId
+	}
+	assert.Equal(t, []int{4, 3, 2, 1}, ids)
+}
+
+func TestValidatePickListInput(t *testing.T) {
+	line := PickListLine{Sku: "sku-000001", Quantity: 1, UnitId: 1}
+	assert.NoError(t, ValidatePickListInput(PickListInput{Lines: []PickListLine{line}}))
+	assert.Error(t, ValidatePickListInput(PickListInput{}))
+	assert.Error(t, ValidatePickListInput(PickListInput{Lines: []PickListLine{line, line}}))
+}
+
+func TestValidateConfirmPickInput(t *testing.T) {
+	item := PickConfirmItem{BatchId: 1, Quantity: 2, UnitId: 1}
+	assert.NoError(t, ValidateConfirmPickInput(ConfirmPickInput{Reason: "sold", Items: []PickConfirmItem{item}}))
+	assert.Error(t, ValidateConfirmPickInput(ConfirmPickInput{Items: []PickConfirmItem{item}}))
+	assert.Error(t, ValidateConfirmPickInput(ConfirmPickInput{Reason: "sold", Items: []PickConfirmItem{item, item}}))
+}
@@ -0,0 +1,42 @@
+package product
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+	"go.uber.org/zap"
+)
+
+// GetPickableBatches returns the available batches of the skus that are not expired, earliest expiry first
+func (r *BatchRepository) GetPickableBatches(ctx context.Context, skus []string) ([]Batch, error) {
+	sql := `
+	select b.id, b.sku, b.quantity, b.unit_id, b.expires_at, b.location_id, coalesce(wl.code, '')
+	from batches b
+	left join warehouse_locations wl on wl.id = b.location_id
+	where b.sku = any($1) and b.warehouse_id = $2 and b.quantity > 0
+	and b.expires_at >= NOW() and b.quality_status = 'available'
+	order by b.sku, b.expires_at ASC, b.id ASC
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, skus, warehouse.GetWarehouseId(ctx))
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to get pickable batches", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get pickable batches")
+	}
+	defer rows.Close()
+	batches := make([]Batch, 0)
+	for rows.Next() {
+		var batch Batch
+		err := rows.Scan(
+			&batch.Id, &batch.Sku, &batch.Quantity, &batch.UnitId, &batch.ExpiresAt,
+			&batch.LocationId, &batch.LocationCode,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("Failed to scan pickable batch", zap.Error(err))
+			return nil, common.NewBadRequestFromMessage("Failed to get pickable batches")
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
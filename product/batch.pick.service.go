@@ -0,0 +1,153 @@
+package product
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+)
+
+// CreatePickList chooses the batches to pick the lines from without changing any stock,
+// the picks are only posted once they are confirmed
+func (s *BatchService) CreatePickList(ctx context.Context, input PickListInput) (PickList, error) {
+	if err := ValidatePickListInput(input); err != nil {
+		return PickList{}, err
+	}
+	skus := make([]string, len(input.Lines))
+	for i, line := range input.Lines {
+		skus[i] = line.Sku
+	}
+	batchVariantMetaInfoLookup, err := s.batchRepo.GetBatchVariantMetaInfo(ctx, skus)
+	if err != nil {
+		return PickList{}, err
+	}
+	batches, err := s.batchRepo.GetPickableBatches(ctx, skus)
+	if err != nil {
+		return PickList{}, err
+	}
+	batchesLookup := make(map[string][]Batch)
+	for _, batch := range batches {
+		quantity, err := s.convertBatchQuantity(ctx, batch.BatchBase, batchVariantMetaInfoLookup[batch.Sku].UnitId)
+		if err != nil {
+			return PickList{}, err
+		}
+		batch.Quantity = quantity
+		batchesLookup[batch.Sku] = append(batchesLookup[batch.Sku], batch)
+	}
+	pickList := PickList{Items: make([]PickListItem, 0)}
+	for _, line := range input.Lines {
+		batchVariantMetaInfo, ok := batchVariantMetaInfoLookup[line.Sku]
+		if !ok {
+			return PickList{}, common.NewBadRequestError("sku "+line.Sku+" does not exist", "sku_not_found")
+		}
+		convertedLine, err := s.convertBatchInput(ctx, BatchInput{
+			Sku:      line.Sku,
+			Quantity: line.Quantity,
+			UnitId:   line.UnitId,
+		}, batchVariantMetaInfo)
+		if err != nil {
+			return PickList{}, err
+		}
+		items, shortage := pickBatches(convertedLine.Quantity, batchVariantMetaInfo.UnitId, batchesLookup[line.Sku])
+		pickList.Items = append(pickList.Items, items...)
+		if shortage > 0 {
+			pickList.Shortages = append(pickList.Shortages, PickListShortage{
+				Sku:      line.Sku,
+				Quantity: shortage,
+				UnitId:   batchVariantMetaInfo.UnitId,
+			})
+		}
+	}
+	sortPickListItems(pickList.Items)
+	return pickList, nil
+}
+
+// ConfirmPick posts the decrements of the picked items, a sku can be picked from several batches
+// so the update requests are keyed by batch instead of by sku like in BulkDecrementBatch
+func (s *BatchService) ConfirmPick(ctx context.Context, input ConfirmPickInput) error {
+	if err := ValidateConfirmPickInput(input); err != nil {
+		return err
+	}
+	ids := make([]int, len(input.Items))
+	for i, item := range input.Items {
+		ids[i] = item.BatchId
+	}
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, err := s.batchRepo.GetBatchBasesByIds(ctx, ids)
+		if err != nil {
+			return err
+		}
+		skus := make([]string, 0)
+		skuSet := make(map[string]bool)
+		for _, id := range ids {
+			batchBase, ok := batchBases[id]
+			if !ok {
+				return common.NewNotFoundError("batch " + strconv.Itoa(id) + " not found")
+			}
+			if !skuSet[batchBase.Sku] {
+				skuSet[batchBase.Sku] = true
+				skus = append(skus, batchBase.Sku)
+			}
+		}
+		bulkBatchUpdateInfo, err := s.lockBatchUpdateRequest(ctx, BulkBatchUpdateInfo{Ids: ids, SkuList: skus})
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		batchVariantMetaInfoLookup, err := s.batchRepo.GetBatchVariantMetaInfo(ctx, skus)
+		if err != nil {
+			return err
+		}
+		batchUpdateRequestLookup := make(map[string]BatchUpdateRequest)
+		transactionHistory := make([]transactions.CreateWarehouseTransactionCommand, 0, len(input.Items))
+		for _, item := range input.Items {
+			batchBase := batchBases[item.BatchId]
+			if !CanDecrementBatch(batchBase.Quality, input.Reason) {
+				return GetBatchNotAvailableError(batchBase.Quality)
+			}
+			batchVariantMetaInfo, ok := batchVariantMetaInfoLookup[batchBase.Sku]
+			if !ok {
+				return common.NewBadRequestFromMessage("variant meta info not found")
+			}
+			batchVariantMetaInfo.UnitId = batchBase.UnitId
+			convertedBatchInput, err := s.convertBatchInput(ctx, BatchInput{
+				Sku:      batchBase.Sku,
+				Quantity: item.Quantity,
+				UnitId:   item.UnitId,
+			}, batchVariantMetaInfo)
+			if err != nil {
+				return err
+			}
+			if convertedBatchInput.Quantity > batchBase.Quantity {
+				return common.NewBadRequestError(
+					"batch "+strconv.Itoa(item.BatchId)+" does not have enough quantity",
+					"insufficient_quantity",
+				)
+			}
+			batchUpdateRequestLookup[strconv.Itoa(item.BatchId)] = BatchUpdateRequest{
+				BatchId:    batchBase.Id,
+				NewValue:   batchBase.Quantity - convertedBatchInput.Quantity,
+				Reason:     input.Reason,
+				Sku:        batchBase.Sku,
+				ModifiedBy: convertedBatchInput.Quantity,
+				Version:    batchBase.Version,
+			}
+			transactionHistory = append(transactionHistory, transactions.CreateWarehouseTransactionCommand{
+				BatchId:  item.BatchId,
+				Quantity: convertedBatchInput.Quantity,
+				UnitId:   batchBase.UnitId,
+				Reason:   input.Reason,
+				Cost:     batchVariantMetaInfo.Cost * convertedBatchInput.Quantity,
+				Comment:  input.Comment,
+				Sku:      batchBase.Sku,
+			})
+		}
+		return s.processBulkBatchUnitOfWork(ctx, BulkBatchUpdateUnitOfWork{
+			BatchUpdateRequestLookup: batchUpdateRequestLookup,
+			BatchTransactionHistory:  transactionHistory,
+			FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package product
+
+import (
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+)
+
+const (
+	BatchQualityAvailable   = "available"
+	BatchQualityQuarantined = "quarantined"
+	BatchQualityOnHold      = "on_hold"
+	BatchQualityRejected    = "rejected"
+)
+
+// batchWriteOffReasons are the only ways rejected stock can leave a batch
+var batchWriteOffReasons = map[string]bool{
+	transactions.TransactionReasonTypeExpired:       true,
+	transactions.TransactionReasonTypeDamaged:       true,
+	transactions.TransactionReasonTypeLost:          true,
+	transactions.TransactionReasonTypeReturn:        true,
+	transactions.TransactionReasonTypeAuditDecrease: true,
+}
+
+type BatchQuality struct {
+	Status    string     `json:"status"`
+	Reason    string     `json:"reason,omitempty"`
+	ChangedBy *int       `json:"changedBy,omitempty"`
+	ChangedAt *time.Time `json:"changedAt,omitempty"`
+}
+
+// BatchQualityInput holds a batch back, releasing it uses BatchReleaseInput since it needs its own permission
+type BatchQualityInput struct {
+	BatchId    int    `json:"batchId"`
+	RetailerId int    `json:"retailerId,omitempty"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason"`
+}
+
+type BatchReleaseInput struct {
+	BatchId    int    `json:"batchId"`
+	RetailerId int    `json:"retailerId,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+func (q BatchQuality) IsAvailable() bool {
+	return q.Status == "" || q.Status == BatchQualityAvailable
+}
+
+// CanDecrementBatch keeps held stock in place, rejected stock can only be written off
+func CanDecrementBatch(quality BatchQuality, reason string) bool {
+	if quality.IsAvailable() {
+		return true
+	}
+	return quality.Status == BatchQualityRejected && batchWriteOffReasons[reason]
+}
+
+func GetBatchNotAvailableError(quality BatchQuality) error {
+	return common.NewBadRequestError("batch is "+quality.Status+" and can not be used", "batch_not_available")
+}
+
+func GetBatchQualityChangeError(current BatchQuality, status string) error {
+	if status == BatchQualityAvailable && current.IsAvailable() {
+		return common.NewBadRequestError("batch is already available", "batch_available")
+	}
+	if current.Status == status {
+		return common.NewBadRequestError("batch is already "+status, "batch_same_quality")
+	}
+	return nil
+}
+
+func ValidateBatchQualityInput(input BatchQualityInput) error {
+	validationResults := []common.ErrorDetails{
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateStringLength(input.Reason, "reason", 1, 255),
+	}
+	switch input.Status {
+	case BatchQualityQuarantined, BatchQualityOnHold, BatchQualityRejected:
+	default:
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: "status must be one of quarantined, on_hold or rejected",
+			Field:   "status",
+		})
+	}
+	return getBatchAdjustmentValidationError(validationResults...)
+}
+
+func ValidateBatchReleaseInput(input BatchReleaseInput) error {
+	return getBatchAdjustmentValidationError(
+		common.ValidateId(input.BatchId, "batchId"),
+		common.ValidateStringLength(input.Reason, "reason", 1, 255),
+	)
+}
@@ -0,0 +1,29 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanDecrementBatch(t *testing.T) {
+	assert.True(t, CanDecrementBatch(BatchQuality{Status: BatchQualityAvailable}, transactions.TransactionReasonTypeSold))
+	assert.False(t, CanDecrementBatch(BatchQuality{Status: BatchQualityQuarantined}, transactions.TransactionReasonTypeSold))
+	assert.False(t, CanDecrementBatch(BatchQuality{Status: BatchQualityOnHold}, transactions.TransactionReasonTypeDamaged))
+	assert.False(t, CanDecrementBatch(BatchQuality{Status: BatchQualityRejected}, transactions.TransactionReasonTypeSold))
+	assert.True(t, CanDecrementBatch(BatchQuality{Status: BatchQualityRejected}, transactions.TransactionReasonTypeDamaged))
+}
+
+func TestValidateBatchQualityInput(t *testing.T) {
+	assert.NoError(t, ValidateBatchQualityInput(BatchQualityInput{BatchId: 1, Status: BatchQualityQuarantined, Reason: "lab test"}))
+	assert.Error(t, ValidateBatchQualityInput(BatchQualityInput{BatchId: 1, Status: BatchQualityAvailable, Reason: "lab test"}))
+	assert.Error(t, ValidateBatchQualityInput(BatchQualityInput{BatchId: 1, Status: BatchQualityOnHold}))
+}
+
+func TestGetBatchQualityChangeError(t *testing.T) {
+	assert.NoError(t, GetBatchQualityChangeError(BatchQuality{Status: BatchQualityAvailable}, BatchQualityQuarantined))
+	assert.NoError(t, GetBatchQualityChangeError(BatchQuality{Status: BatchQualityQuarantined}, BatchQualityAvailable))
+	assert.Error(t, GetBatchQualityChangeError(BatchQuality{Status: BatchQualityAvailable}, BatchQualityAvailable))
+	assert.Error(t, GetBatchQualityChangeError(BatchQuality{Status: BatchQualityOnHold}, BatchQualityOnHold))
+}
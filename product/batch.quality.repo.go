@@ -0,0 +1,36 @@
+package product
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+	"go.uber.org/zap"
+)
+
+func (r *BatchRepository) UpdateBatchQuality(
+	ctx context.Context,
+	batchBase BatchBase,
+	quality BatchQuality,
+	fencingToken int64,
+) error {
+	sql := `
+	UPDATE batches SET quality_status = $1, quality_reason = $2, quality_changed_by = $3,
+	quality_changed_at = CURRENT_TIMESTAMP, fencing_token = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $5 and warehouse_id = $6 and fencing_token <= $4 and version = $7
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	tag, err := op.Exec(ctx, sql,
+		quality.Status, quality.Reason, quality.ChangedBy, fencingToken,
+		batchBase.Id, warehouse.GetWarehouseId(ctx), batchBase.Version,
+	)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to update batch quality", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to update batch quality")
+	}
+	if tag.RowsAffected() == 0 {
+		common.LoggerFromCtx(ctx).Warn("batch quality update rejected by version or fencing token", zap.Int64("fencing_token", fencingToken))
+		return common.NewConflictError("Batch was modified by another request, please try again")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/user"
+)
+
+// SetBatchQuality holds a batch back from being used until it is released, e.g. while waiting on lab results
+func (s *BatchService) SetBatchQuality(ctx context.Context, input BatchQualityInput) error {
+	if err := ValidateBatchQualityInput(input); err != nil {
+		return err
+	}
+	return s.updateBatchQuality(ctx, input.BatchId, input.Status, input.Reason)
+}
+
+func (s *BatchService) ReleaseBatch(ctx context.Context, input BatchReleaseInput) error {
+	if err := ValidateBatchReleaseInput(input); err != nil {
+		return err
+	}
+	return s.updateBatchQuality(ctx, input.BatchId, BatchQualityAvailable, input.Reason)
+}
+
+func (s *BatchService) updateBatchQuality(ctx context.Context, batchId int, status string, reason string) error {
+	return common.RunWithTransaction(ctx, s.batchRepo.(*BatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBases, bulkBatchUpdateInfo, err := s.lockBatchAdjustment(ctx, batchId, nil)
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		batchBase := batchBases[batchId]
+		if err := GetBatchQualityChangeError(batchBase.Quality, status); err != nil {
+			return err
+		}
+		changedBy := user.GetUserFromContext(ctx).Id
+		return s.batchRepo.UpdateBatchQuality(ctx, batchBase, BatchQuality{
+			Status:    status,
+			Reason:    reason,
+			ChangedBy: &changedBy,
+		}, common.GetFencingToken(bulkBatchUpdateInfo.locks))
+	})
+}
@@ -183,7 +183,9 @@ func (r *BatchRepository) getMostExpiredRecipeBatchBases(
 		batches.warehouse_id as warehouse_id,
 		batches.sku as batch_sku,
 		batches.quantity as batch_qty,
-		batches.unit_id as batch_unit_id
+		batches.unit_id as batch_unit_id,
+		batches.version as batch_version,
+		batches.quality_status as batch_quality_status
 	from
 		batches
 	join recipes on
@@ -194,6 +196,8 @@ func (r *BatchRepository) getMostExpiredRecipeBatchBases(
 			batches.warehouse_id = $2
 		and
 			expires_at >= NOW()
+		and
+			batches.quality_status = 'available'
 	ORDER BY batches.sku, batches.expires_at ASC
 		`,
 		skus,
@@ -214,7 +218,9 @@ func (r *BatchRepository) getLeastExpiredRecipeBatchBases(
 		batches.warehouse_id as warehouse_id,
 		batches.sku as batch_sku,
 		batches.quantity as batch_qty,
-		batches.unit_id as batch_unit_id	
+		batches.unit_id as batch_unit_id,
+		batches.version as batch_version,
+		batches.quality_status as batch_quality_status
 	from
 		batches
 	join recipes on
@@ -225,6 +231,8 @@ func (r *BatchRepository) getLeastExpiredRecipeBatchBases(
 			batches.warehouse_id = $2
 		and
 			expires_at >= NOW()
+		and
+			batches.quality_status = 'available'
 	ORDER BY batches.sku, batches.expires_at DESC
 		`,
 		skus,
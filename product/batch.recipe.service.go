@@ -106,6 +106,7 @@ func (s *BatchService) processBulkBatchIncrementWithRecipe(
 		BatchUpdateRequestLookup: batchUpdateRequestLookup,
 		BatchCreateRequestLookup: batchCreateRequestLookup,
 		BatchTransactionHistory:  transactionHistory,
+		FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
 	}
 	return s.processBulkBatchUnitOfWork(ctx, bulkBatchUpdateUnitOfWork)
 }
@@ -198,6 +199,7 @@ func (s *BatchService) createRecipeUpdateFromBatchUpdate(
 			Reason:     transactions.TransactionReasonTypeRecipeUse,
 			Sku:        recipe.RecipeVariantSku,
 			ModifiedBy: recipeTotalModifyBy,
+			Version:    recipeBatchBase.Version,
 		}
 		transactionCommand := transactions.CreateWarehouseTransactionCommand{
 			BatchId:  *recipeBatchBase.Id,
@@ -243,7 +245,7 @@ func (s *BatchService) createRecipeUpdateFromBatchCreate(
 			Id:       recipeBatchBase.Id,
 			Sku:      recipe.RecipeVariantSku,
 			Quantity: recipe.Quantity,
-			UnitId:   recipeVariantMetaInfo.UnitId,
+			UnitId:   *recipe.Unit.Id,
 			Reason:   transactions.TransactionReasonTypeRecipeUse,
 		}
 		convertedRecipeInput, err := s.convertBatchInput(ctx, recipeBatchInput, recipeVariantMetaInfo)
@@ -267,6 +269,7 @@ func (s *BatchService) createRecipeUpdateFromBatchCreate(
 			Reason:     transactions.TransactionReasonTypeRecipeUse,
 			Sku:        recipe.RecipeVariantSku,
 			ModifiedBy: recipeTotalModifyBy,
+			Version:    recipeBatchBase.Version,
 		}
 		transactionCommand := transactions.CreateWarehouseTransactionCommand{
 			BatchId:  *recipeBatchBase.Id,
@@ -15,21 +15,35 @@ import (
 type IBatchRepository interface {
 	CreateBatch(ctx context.Context, input BatchInput, expiresAt string) (int, error)
 	UpdateBatch(ctx context.Context, base BatchBase) error
-	GetBatches(ctx context.Context, params common.PaginationParams) ([]Batch, error)
+	GetBatches(ctx context.Context, params common.PaginationParams, locationIds []int) ([]Batch, error)
 	SearchBatchesBySku(ctx context.Context, sku string, params common.PaginationParams) ([]Batch, error)
 	GetBulkBatchUpdateInfo(ctx context.Context, inputs []BatchInput) (BulkBatchUpdateInfo, error)
 	GetBulkBatchUpdateInfoWithRecipe(ctx context.Context, inputs []BatchInput) (BulkBatchUpdateInfo, error)
 	GetBatchById(ctx context.Context, id int) (Batch, error)
+	LockWarehouse(ctx context.Context) error
+	HasWarehouseBatches(ctx context.Context) (bool, error)
+	GetBatchVariantMetaInfo(ctx context.Context, skus []string) (map[string]BatchVariantMetaInfo, error)
+	CreateOpeningBalanceBatch(ctx context.Context, batch OpeningBalanceBatch, fencingToken int64) (int, error)
+	GetBatchBasesByIds(ctx context.Context, ids []int) (map[int]BatchBase, error)
+	ReserveBatchId(ctx context.Context) (int, error)
+	GetOriginalBatchId(ctx context.Context, sku string, expiresAt time.Time) (*int, error)
+	UpdateBatchQuality(ctx context.Context, batchBase BatchBase, quality BatchQuality, fencingToken int64) error
+	GetPickableBatches(ctx context.Context, skus []string) ([]Batch, error)
 }
 
 const baseBatchListingSql = `
-select b.id, b.sku, b.quantity, b.expires_at, utx.unit_id, utx.name, utx.symbol,
-pvartx.name, pvar.id, pvar.price, pvar.product_id, ptx.name, p.is_ingredient from batches b
+select b.id, b.sku, b.quantity, b.expires_at, coalesce(b.lot_code, ''), b.manufactured_at, b.parent_batch_id,
+b.quality_status, coalesce(b.quality_reason, ''), b.quality_changed_by, b.quality_changed_at,
+b.location_id, coalesce(wl.code, ''), utx.unit_id, utx.name, utx.symbol,
+pvartx.name, pvar.id, pvar.price, pvar.product_id, ptx.name, p.is_ingredient,
+p.category_id, ctgtx.name from batches b
 join unit_translations utx on utx.unit_id = b.unit_id
 join product_variants pvar on pvar.sku = b.sku
 join product_translations ptx on ptx.product_id = pvar.product_id
 join products p on p.id = pvar.product_id
 join product_variant_translations pvartx on pvartx.product_variant_id = pvar.id and utx.language_code = pvartx.language_code
+left join category_translations ctgtx on ctgtx.category_id = p.category_id and ctgtx.language_code = utx.language_code
+left join warehouse_locations wl on wl.id = b.location_id
 `
 
 type BatchRepository struct {
@@ -57,7 +71,7 @@ func (r *BatchRepository) CreateBatch(ctx context.Context, input BatchInput, exp
 func (r *BatchRepository) UpdateBatch(ctx context.Context, base BatchBase) error {
 	updatedAt := time.Now().UTC()
 	op := common.GetOperator(ctx, r.Pool)
-	sql := `UPDATE batches SET quantity = $1, updated_at = $2 WHERE id = $3 and warehouse_id = $4`
+	sql := `UPDATE batches SET quantity = $1, updated_at = $2, version = version + 1 WHERE id = $3 and warehouse_id = $4`
 	_, err := op.Exec(ctx, sql, base.Quantity, updatedAt, base.Id, base.WarehouseId)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("Failed to update batch", zap.Error(err))
@@ -66,26 +80,34 @@ func (r *BatchRepository) UpdateBatch(ctx context.Context, base BatchBase) error
 	return nil
 }
 
-func (r *BatchRepository) GetBatches(ctx context.Context, params common.PaginationParams) ([]Batch, error) {
+// GetBatches lists the batches of the warehouse, only batches stored in one of the locations are listed
+// when location ids are given
+func (r *BatchRepository) GetBatches(ctx context.Context, params common.PaginationParams, locationIds []int) ([]Batch, error) {
 	warehouseId := warehouse.GetWarehouseId(ctx)
 	op := common.GetOperator(ctx, r.Pool)
 	lang := common.GetLanguageParam(ctx)
+	conditions := []string{
+		"utx.language_code = $1",
+		"AND",
+		"b.warehouse_id = $2",
+	}
+	args := []interface{}{lang, warehouseId}
+	if locationIds != nil {
+		conditions = append(conditions, "AND", "b.location_id = any($3)")
+		args = append(args, locationIds)
+	}
 	sqlBuilder := common.NewPaginationQueryBuilder(
 		baseBatchListingSql,
 		[]string{"b.expires_at ASC", "b.id ASC"},
 	)
 	rows, err := sqlBuilder.
 		WithOperator(op).
-		WithConditions([]string{
-			"utx.language_code = $1",
-			"AND",
-			"b.warehouse_id = $2",
-		}).
+		WithConditions(conditions).
 		WithParams(params).
 		WithCursorKeys([]string{"b.expires_at", "b.id"}).
 		WithCompareSymbols(">", ">=", "<").
 		Build().
-		Query(ctx, lang, warehouseId)
+		Query(ctx, args...)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("Failed to get batches", zap.Error(err))
 		return []Batch{}, common.NewBadRequestFromMessage("Failed to get batches")
@@ -130,11 +152,14 @@ func (r *BatchRepository) parseBatchRows(rows pgx.Rows) ([]Batch, error) {
 		var batch Batch
 		var productVariantBase ProductVariantBase
 		var unit unit.Unit
+		var category Category
 		err := rows.Scan(
-			&batch.Id, &batch.Sku, &batch.Quantity, &batch.ExpiresAt,
-			&unit.Id, &unit.Name, &unit.Symbol,
+			&batch.Id, &batch.Sku, &batch.Quantity, &batch.ExpiresAt, &batch.LotCode, &batch.ManufacturedAt, &batch.ParentBatchId,
+			&batch.Quality.Status, &batch.Quality.Reason, &batch.Quality.ChangedBy, &batch.Quality.ChangedAt,
+			&batch.LocationId, &batch.LocationCode, &unit.Id, &unit.Name, &unit.Symbol,
 			&productVariantBase.Name, &productVariantBase.Id, &productVariantBase.Price,
 			&productVariantBase.ProductId, &batch.ProductName, &batch.IsIngredient,
+			&category.Id, &category.Name,
 		)
 		if err != nil {
 			common.GetLogger().Error("Failed to scan batches", zap.Error(err))
@@ -142,6 +167,7 @@ func (r *BatchRepository) parseBatchRows(rows pgx.Rows) ([]Batch, error) {
 		}
 		batch.Unit = unit
 		batch.ProductVariantBase = &productVariantBase
+		batch.setCategory(category)
 		batches = append(batches, batch)
 	}
 	return batches, nil
@@ -156,32 +182,93 @@ func (r *BatchRepository) processBulkBatchUnitOfWork(
 	// create create sql batches
 	op := common.GetOperator(ctx, r.Pool)
 	warehouseId := warehouse.GetWarehouseId(ctx)
+	fencingToken := bulkBatchUpdateUnitOfWork.FencingToken
+	updatesStart := transactionsBatch.Len()
 	for _, batchUpdateRequest := range bulkBatchUpdateUnitOfWork.BatchUpdateRequestLookup {
 		transactionsBatch.Queue(
-			"UPDATE batches SET quantity = $1 WHERE id = $2 and warehouse_id = $3",
+			`UPDATE batches SET quantity = $1, fencing_token = $4, version = version + 1
+			WHERE id = $2 and warehouse_id = $3 and fencing_token <= $4 and version = $5`,
 			batchUpdateRequest.NewValue,
 			batchUpdateRequest.BatchId,
 			warehouseId,
+			fencingToken,
+			batchUpdateRequest.Version,
 		)
 	}
+	// received goods are added to the batch of the same sku and expiry date when the warehouse has an available one
+	createsStart := transactionsBatch.Len()
 	for _, batchCreateRequest := range bulkBatchUpdateUnitOfWork.BatchCreateRequestLookup {
 		transactionsBatch.Queue(
-			"INSERT INTO batches (sku, warehouse_id, quantity, unit_id, expires_at) VALUES ($1, $2, $3, $4, $5)",
+			`INSERT INTO batches (sku, warehouse_id, quantity, unit_id, expires_at, manufactured_at, fencing_token)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (sku, warehouse_id, expires_at) WHERE parent_batch_id IS NULL DO UPDATE SET
+				quantity = batches.quantity + EXCLUDED.quantity,
+				fencing_token = EXCLUDED.fencing_token,
+				version = batches.version + 1,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE batches.fencing_token <= EXCLUDED.fencing_token AND batches.quality_status = 'available'`,
 			batchCreateRequest.BatchSku,
 			warehouseId,
 			batchCreateRequest.Quantity,
 			batchCreateRequest.UnitId,
 			common.GetUtcDateOnlyStringFromTime(batchCreateRequest.ExpiryDate),
+			batchCreateRequest.ManufacturedAt,
+			fencingToken,
+		)
+	}
+	createsEnd := transactionsBatch.Len()
+	for _, batchSplitRequest := range bulkBatchUpdateUnitOfWork.BatchSplitRequests {
+		transactionsBatch.Queue(
+			`INSERT INTO batches (id, sku, warehouse_id, quantity, unit_id, expires_at, manufactured_at, lot_code,
+			quality_status, quality_reason, quality_changed_by, quality_changed_at, location_id, parent_batch_id, fencing_token)
+			SELECT $1, sku, warehouse_id, $2, unit_id, expires_at, manufactured_at, lot_code,
+			quality_status, quality_reason, quality_changed_by, quality_changed_at, location_id, id, $3
+			FROM batches WHERE id = $4 and warehouse_id = $5`,
+			batchSplitRequest.Id,
+			batchSplitRequest.Quantity,
+			fencingToken,
+			batchSplitRequest.ParentBatchId,
+			warehouseId,
+		)
+	}
+	for _, batchRedateRequest := range bulkBatchUpdateUnitOfWork.BatchRedateRequests {
+		transactionsBatch.Queue(
+			`UPDATE batches SET expires_at = $1, fencing_token = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 and warehouse_id = $3 and fencing_token <= $4 and version = $5`,
+			common.GetUtcDateOnlyStringFromTime(batchRedateRequest.ExpiryDate),
+			batchRedateRequest.BatchId,
+			warehouseId,
+			fencingToken,
+			batchRedateRequest.Version,
+		)
+	}
+	for _, batchMoveRequest := range bulkBatchUpdateUnitOfWork.BatchMoveRequests {
+		transactionsBatch.Queue(
+			`UPDATE batches SET location_id = $1, fencing_token = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2 and warehouse_id = $3 and fencing_token <= $4 and version = $5`,
+			batchMoveRequest.LocationId,
+			batchMoveRequest.BatchId,
+			warehouseId,
+			fencingToken,
+			batchMoveRequest.Version,
 		)
 	}
 	results := op.SendBatch(ctx, transactionsBatch)
 	defer results.Close()
 	for i := 0; i < transactionsBatch.Len(); i++ {
-		_, err := results.Exec()
+		tag, err := results.Exec()
 		if err != nil {
 			common.LoggerFromCtx(ctx).Error("Failed to process bulk batch unit of work", zap.Error(err))
 			return common.NewBadRequestFromMessage("Failed to process bulk batch unit of work")
 		}
+		// goods are never added to a held batch, it has to be released first
+		if i >= createsStart && i < createsEnd && tag.RowsAffected() == 0 {
+			return common.NewConflictError("A batch with the same expiry date is not available to receive goods")
+		}
+		if i >= updatesStart && tag.RowsAffected() == 0 {
+			common.LoggerFromCtx(ctx).Warn("batch update rejected by version or fencing token", zap.Int64("fencing_token", fencingToken))
+			return common.NewConflictError("Batch was modified by another request, please try again")
+		}
 	}
 	return nil
 }
@@ -195,11 +282,14 @@ func (r *BatchRepository) GetBatchById(ctx context.Context, batchId int) (Batch,
 	var batch Batch
 	var productVariantBase ProductVariantBase
 	var unit unit.Unit
+	var category Category
 	err := row.Scan(
-		&batch.Id, &batch.Sku, &batch.Quantity, &batch.ExpiresAt,
-		&unit.Id, &unit.Name, &unit.Symbol,
+		&batch.Id, &batch.Sku, &batch.Quantity, &batch.ExpiresAt, &batch.LotCode, &batch.ManufacturedAt, &batch.ParentBatchId,
+		&batch.Quality.Status, &batch.Quality.Reason, &batch.Quality.ChangedBy, &batch.Quality.ChangedAt,
+		&batch.LocationId, &batch.LocationCode, &unit.Id, &unit.Name, &unit.Symbol,
 		&productVariantBase.Name, &productVariantBase.Id, &productVariantBase.Price,
 		&productVariantBase.ProductId, &batch.ProductName, &batch.IsIngredient,
+		&category.Id, &category.Name,
 	)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("Failed to get batch by id", zap.Error(err))
@@ -207,5 +297,6 @@ func (r *BatchRepository) GetBatchById(ctx context.Context, batchId int) (Batch,
 	}
 	batch.Unit = unit
 	batch.ProductVariantBase = &productVariantBase
+	batch.setCategory(category)
 	return batch, nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
 	"github.com/nayefradwi/zanobia_inventory_manager/unit"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 )
 
 type UseMostExpiredKey struct{}
@@ -17,9 +18,18 @@ type IBatchService interface {
 	BulkDecrementBatch(ctx context.Context, inputs []BatchInput) error
 	IncrementBatchWithRecipe(ctx context.Context, batchInput BatchInput) error
 	BulkIncrementWithRecipeBatch(ctx context.Context, inputs []BatchInput) error
-	GetBatches(ctx context.Context) (common.PaginatedResponse[Batch], error)
+	GetBatches(ctx context.Context, filter BatchFilter) (common.PaginatedResponse[Batch], error)
 	SearchBatchesBySku(ctx context.Context, sku string) (common.PaginatedResponse[Batch], error)
 	GetBatchById(ctx context.Context, id int) (Batch, error)
+	ImportOpeningBalance(ctx context.Context, input OpeningBalanceInput) error
+	SplitBatch(ctx context.Context, input BatchSplitInput) (BatchSplitResult, error)
+	MergeBatches(ctx context.Context, input BatchMergeInput) error
+	RedateBatch(ctx context.Context, input BatchRedateInput) error
+	SetBatchQuality(ctx context.Context, input BatchQualityInput) error
+	ReleaseBatch(ctx context.Context, input BatchReleaseInput) error
+	MoveBatch(ctx context.Context, input BatchMoveInput) error
+	CreatePickList(ctx context.Context, input PickListInput) (PickList, error)
+	ConfirmPick(ctx context.Context, input ConfirmPickInput) error
 }
 
 type BatchService struct {
@@ -29,6 +39,7 @@ type BatchService struct {
 	unitService        unit.IUnitService
 	recipeService      IRecipeService
 	transactionService transactions.ITransactionService
+	warehouseService   warehouse.IWarehouseService
 }
 
 func NewBatchService(
@@ -38,6 +49,7 @@ func NewBatchService(
 	unitService unit.IUnitService,
 	recipeService IRecipeService,
 	transactionService transactions.ITransactionService,
+	warehouseService warehouse.IWarehouseService,
 ) *BatchService {
 	return &BatchService{
 		batchRepo,
@@ -46,6 +58,7 @@ func NewBatchService(
 		unitService,
 		recipeService,
 		transactionService,
+		warehouseService,
 	}
 }
 
@@ -56,9 +69,17 @@ func GenerateBatchLockKey(batchInput BatchInput) string {
 	return "batch:" + batchInput.Sku + ":lock"
 }
 
-func (s *BatchService) GetBatches(ctx context.Context) (common.PaginatedResponse[Batch], error) {
+func (s *BatchService) GetBatches(ctx context.Context, filter BatchFilter) (common.PaginatedResponse[Batch], error) {
 	paginationParam := common.GetPaginationParams(ctx)
-	batches, err := s.batchRepo.GetBatches(ctx, paginationParam)
+	var locationIds []int
+	if filter.LocationId != 0 {
+		ids, err := s.warehouseService.GetLocationSubtreeIds(ctx, filter.LocationId)
+		if err != nil {
+			return common.PaginatedResponse[Batch]{}, err
+		}
+		locationIds = ids
+	}
+	batches, err := s.batchRepo.GetBatches(ctx, paginationParam, locationIds)
 	if err != nil {
 		return common.PaginatedResponse[Batch]{}, err
 	}
@@ -104,6 +125,7 @@ func (s *BatchService) convertBatchInput(
 		ToUnitId:   &batchVariantMetaInfo.UnitId,
 		Quantity:   batchInput.Quantity,
 		FromUnitId: &batchInput.UnitId,
+		Sku:        batchInput.Sku,
 	}
 	conversionOutput, err := s.unitService.ConvertUnit(ctx, convertInput)
 	if err != nil {
@@ -114,6 +136,24 @@ func (s *BatchService) convertBatchInput(
 	return batchInput, nil
 }
 
+// convertBatchQuantity brings the quantity of a batch into the given unit, batches of a sku
+// can be kept in different units when the standard unit of the variant changed
+func (s *BatchService) convertBatchQuantity(ctx context.Context, batch BatchBase, toUnitId int) (float64, error) {
+	if batch.UnitId == toUnitId {
+		return batch.Quantity, nil
+	}
+	conversionOutput, err := s.unitService.ConvertUnit(ctx, unit.ConvertUnitInput{
+		ToUnitId:   &toUnitId,
+		Quantity:   batch.Quantity,
+		FromUnitId: &batch.UnitId,
+		Sku:        batch.Sku,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return conversionOutput.Quantity, nil
+}
+
 func (s *BatchService) processBulkBatchUnitOfWork(
 	ctx context.Context,
 	bulkBatchUpdateUnitOfWork BulkBatchUpdateUnitOfWork,
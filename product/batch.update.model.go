@@ -11,6 +11,7 @@ type BatchVariantMetaInfo struct {
 	UnitId        int
 	ExpiresInDays int
 	Cost          float64
+	StorageClass  string
 }
 
 type BulkBatchUpdateInfo struct {
@@ -30,17 +31,43 @@ type BatchUpdateRequest struct {
 	Reason     string
 	Sku        string
 	ModifiedBy float64
+	Version    int
 }
 
 type BatchCreateRequest struct {
-	BatchSku   string
-	Quantity   float64
-	UnitId     int
+	BatchSku       string
+	Quantity       float64
+	UnitId         int
+	ExpiryDate     time.Time
+	ManufacturedAt *time.Time
+}
+
+// BatchSplitRequest creates a batch out of part of its parent, the id is reserved beforehand
+// so the transaction history can point to the new batch
+type BatchSplitRequest struct {
+	Id            int
+	ParentBatchId int
+	Quantity      float64
+}
+
+type BatchRedateRequest struct {
+	BatchId    int
 	ExpiryDate time.Time
+	Version    int
+}
+
+type BatchMoveRequest struct {
+	BatchId    int
+	LocationId int
+	Version    int
 }
 
 type BulkBatchUpdateUnitOfWork struct {
 	BatchUpdateRequestLookup map[string]BatchUpdateRequest
 	BatchCreateRequestLookup map[string]BatchCreateRequest
+	BatchSplitRequests       []BatchSplitRequest
+	BatchRedateRequests      []BatchRedateRequest
+	BatchMoveRequests        []BatchMoveRequest
 	BatchTransactionHistory  []transactions.CreateWarehouseTransactionCommand
+	FencingToken             int64
 }
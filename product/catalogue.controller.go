@@ -0,0 +1,44 @@
+package product
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+type CatalogueController struct {
+	service ICatalogueService
+}
+
+func NewCatalogueController(service ICatalogueService) CatalogueController {
+	return CatalogueController{
+		service,
+	}
+}
+
+func (c CatalogueController) Import(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	rows, err := common.ReadSpreadsheetFromRequest(w, r)
+	if err != nil {
+		common.WriteResponseFromError(w, err)
+		return
+	}
+	ctx := common.SetBoolToContext(r.Context(), OpeningBalanceOverrideKey{}, r.URL.Query().Get("override"))
+	result, err := c.service.Import(ctx, kind, rows)
+	common.WriteResponse(common.Result[CatalogueImportResult]{
+		Error:  err,
+		Writer: w,
+		Data:   result,
+	})
+}
+
+func (c CatalogueController) Export(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	format := r.URL.Query().Get("format")
+	if format != common.XlsxFormat {
+		format = common.CsvFormat
+	}
+	rows, err := c.service.Export(r.Context(), kind)
+	common.WriteSpreadsheetResponse(w, kind, format, rows, err)
+}
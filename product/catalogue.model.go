@@ -0,0 +1,290 @@
+package product
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+const (
+	CatalogueProducts     = "products"
+	CatalogueVariants     = "variants"
+	CatalogueTranslations = "translations"
+	CatalogueRecipes      = "recipes"
+	CatalogueStock        = "stock"
+	// CatalogueOpeningBalance is the stock of the warehouse batch by batch
+	CatalogueOpeningBalance = "opening-balance"
+)
+
+const (
+	catalogueProductTranslation = "product"
+	catalogueVariantTranslation = "variant"
+)
+
+// catalogueHeaders are the columns of every catalogue sheet, imports and exports share them
+// so an exported file can be imported as is
+var catalogueHeaders = map[string][]string{
+	CatalogueProducts: {
+		"name", "description", "image", "category_id", "is_ingredient", "is_archived",
+		"price", "standard_unit_id", "expires_in_days", "storage_class", "options", "sku", "barcode",
+	},
+	CatalogueVariants: {
+		"product", "options", "sku", "barcode", "price", "width_in_cm", "height_in_cm",
+		"depth_in_cm", "weight_in_g", "standard_unit_id", "expires_in_days", "storage_class", "is_archived",
+	},
+	CatalogueTranslations:   {"type", "key", "language_code", "name", "description"},
+	CatalogueRecipes:        {"result_sku", "ingredient_sku", "quantity", "unit_id"},
+	CatalogueStock:          {"sku", "quantity", "unit_id"},
+	CatalogueOpeningBalance: {"sku", "quantity", "unit_id", "expires_at", "cost", "lot_code"},
+}
+
+type CatalogueImportResult struct {
+	Kind string `json:"kind"`
+	Rows int    `json:"rows"`
+}
+
+type CatalogueTranslation struct {
+	Type         string
+	Key          string
+	LanguageCode string
+	Name         string
+	Description  string
+}
+
+type catalogueRow struct {
+	number int
+	cells  map[string]string
+	errors []common.ErrorDetails
+}
+
+func IsCatalogueKind(kind string) bool {
+	_, ok := catalogueHeaders[kind]
+	return ok
+}
+
+func isWarehouseCatalogueKind(kind string) bool {
+	return kind == CatalogueStock || kind == CatalogueOpeningBalance
+}
+
+// parseCatalogueRows maps every data row by the header names, row numbers match the
+// spreadsheet so the header is row 1, empty rows are skipped
+func parseCatalogueRows(kind string, rows [][]string) ([]*catalogueRow, error) {
+	if len(rows) < 2 {
+		return nil, common.NewBadRequestError("file has no rows to import", "empty_file")
+	}
+	columns := make(map[int]string)
+	found := make(map[string]bool)
+	for i, header := range rows[0] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		columns[i] = name
+		found[name] = true
+	}
+	missing := make([]common.ErrorDetails, 0)
+	for _, header := range catalogueHeaders[kind] {
+		if !found[header] {
+			missing = append(missing, common.ErrorDetails{Message: "column is missing", Field: header})
+		}
+	}
+	if len(missing) > 0 {
+		return nil, common.NewValidationError("invalid import file", missing...)
+	}
+	parsed := make([]*catalogueRow, 0, len(rows)-1)
+	for i, cells := range rows[1:] {
+		row := &catalogueRow{number: i + 2, cells: make(map[string]string)}
+		isEmpty := true
+		for j, cell := range cells {
+			value := strings.TrimSpace(cell)
+			row.cells[columns[j]] = value
+			isEmpty = isEmpty && value == ""
+		}
+		if !isEmpty {
+			parsed = append(parsed, row)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, common.NewBadRequestError("file has no rows to import", "empty_file")
+	}
+	return parsed, nil
+}
+
+func (r *catalogueRow) getString(column string) string {
+	return r.cells[column]
+}
+
+func (r *catalogueRow) getStringPtr(column string) *string {
+	value := r.cells[column]
+	return &value
+}
+
+func (r *catalogueRow) getInt(column string) int {
+	value := r.getIntPtr(column)
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func (r *catalogueRow) getIntPtr(column string) *int {
+	if r.cells[column] == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(r.cells[column])
+	if err != nil {
+		r.addError(column, column+" must be a whole number")
+		return nil
+	}
+	return &value
+}
+
+func (r *catalogueRow) getFloat(column string) float64 {
+	value := r.getFloatPtr(column)
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func (r *catalogueRow) getFloatPtr(column string) *float64 {
+	if r.cells[column] == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(r.cells[column], 64)
+	if err != nil {
+		r.addError(column, column+" must be a number")
+		return nil
+	}
+	return &value
+}
+
+func (r *catalogueRow) getBool(column string) bool {
+	if r.cells[column] == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(r.cells[column])
+	if err != nil {
+		r.addError(column, column+" must be true or false")
+	}
+	return value
+}
+
+func (r *catalogueRow) addError(field, message string) {
+	r.errors = append(r.errors, common.ErrorDetails{
+		Message: message,
+		Field:   "row " + strconv.Itoa(r.number) + ": " + field,
+	})
+}
+
+// addValidationError keeps the details of validation errors so every failing field of the row is reported
+func (r *catalogueRow) addValidationError(err error) {
+	if err == nil {
+		return
+	}
+	apiErr, ok := err.(*common.ApiError)
+	if !ok || len(apiErr.Errors) == 0 {
+		r.addError("", err.Error())
+		return
+	}
+	for _, detail := range apiErr.Errors {
+		r.addError(detail.Field, detail.Message)
+	}
+}
+
+func getCatalogueRowsError(rows []*catalogueRow) error {
+	details := make([]common.ErrorDetails, 0)
+	for _, row := range rows {
+		details = append(details, row.errors...)
+	}
+	if len(details) > 0 {
+		return common.NewValidationError("invalid import file", details...)
+	}
+	return nil
+}
+
+// getCatalogueRowError points an error raised while applying a row to that row
+func getCatalogueRowError(row *catalogueRow, err error) error {
+	row.addValidationError(err)
+	return common.NewValidationError("failed to import file", row.errors...)
+}
+
+// parseProductOptions reads options written as "size=small|large;flavor=vanilla",
+// the first value of every option is used for the default variant
+func parseProductOptions(value string) ([]ProductOption, bool) {
+	options := make([]ProductOption, 0)
+	if value == "" {
+		return options, true
+	}
+	for _, part := range strings.Split(value, ";") {
+		name, values, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, false
+		}
+		option := ProductOption{Name: name}
+		for _, optionValue := range strings.Split(values, "|") {
+			if optionValue = strings.TrimSpace(optionValue); optionValue != "" {
+				option.Values = append(option.Values, ProductOptionValue{Value: optionValue})
+			}
+		}
+		options = append(options, option)
+	}
+	return options, true
+}
+
+func formatProductOptions(options []ProductOption) string {
+	parts := make([]string, len(options))
+	for i, option := range options {
+		values := make([]string, len(option.Values))
+		for j, value := range option.Values {
+			values[j] = value.Value
+		}
+		parts[i] = option.Name + "=" + strings.Join(values, "|")
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseVariantOptions reads the option values of a variant written as "size=large;flavor=mango"
+func parseVariantOptions(value string) (map[string]string, bool) {
+	options := make(map[string]string)
+	for _, part := range strings.Split(value, ";") {
+		name, optionValue, ok := strings.Cut(part, "=")
+		name, optionValue = strings.TrimSpace(name), strings.TrimSpace(optionValue)
+		if !ok || name == "" || optionValue == "" {
+			return nil, false
+		}
+		options[name] = optionValue
+	}
+	return options, true
+}
+
+func formatVariantOptions(options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + options[name]
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatCatalogueFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatCatalogueFloatPtr(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatCatalogueFloat(*value)
+}
+
+func formatCatalogueIntPtr(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
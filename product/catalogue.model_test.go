@@ -0,0 +1,61 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCatalogueRows(t *testing.T) {
+	rows, err := parseCatalogueRows(CatalogueStock, [][]string{
+		{"SKU", " quantity ", "unit_id", "comment"},
+		{"sku-1", "2.5", "1"},
+		{"", "", ""},
+		{"sku-2", "two", "1", "ignored"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].number)
+	assert.Equal(t, 2.5, rows[0].getFloat("quantity"))
+	assert.Equal(t, 4, rows[1].number)
+	assert.Equal(t, 0.0, rows[1].getFloat("quantity"))
+	rowsErr := getCatalogueRowsError(rows)
+	assert.Error(t, rowsErr)
+	assert.Equal(t, "row 4: quantity", rowsErr.(*common.ApiError).Errors[0].Field)
+}
+
+func TestParseCatalogueRowsRequiresHeaders(t *testing.T) {
+	_, err := parseCatalogueRows(CatalogueRecipes, [][]string{{"result_sku", "quantity"}, {"a", "1"}})
+	assert.Error(t, err)
+	assert.Len(t, err.(*common.ApiError).Errors, 2)
+	_, err = parseCatalogueRows(CatalogueStock, [][]string{{"sku", "quantity", "unit_id"}})
+	assert.Error(t, err)
+}
+
+func TestCatalogueRowKeepsValidationDetails(t *testing.T) {
+	row := &catalogueRow{number: 3, cells: map[string]string{}}
+	row.addValidationError(ValidateRecipe(RecipeBase{}))
+	assert.NotEmpty(t, row.errors)
+	assert.Contains(t, row.errors[0].Field, "row 3: ")
+}
+
+func TestProductOptionsRoundTrip(t *testing.T) {
+	options, ok := parseProductOptions("size = small|large ;flavor=vanilla")
+	assert.True(t, ok)
+	assert.Equal(t, "size=small|large;flavor=vanilla", formatProductOptions(options))
+	options, ok = parseProductOptions("")
+	assert.True(t, ok)
+	assert.Empty(t, options)
+	_, ok = parseProductOptions("size")
+	assert.False(t, ok)
+}
+
+func TestVariantOptionsRoundTrip(t *testing.T) {
+	options, ok := parseVariantOptions("size=large; flavor=mango")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"size": "large", "flavor": "mango"}, options)
+	assert.Equal(t, "flavor=mango;size=large", formatVariantOptions(options))
+	_, ok = parseVariantOptions("size=")
+	assert.False(t, ok)
+}
@@ -0,0 +1,336 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"go.uber.org/zap"
+)
+
+type ICatalogueRepository interface {
+	GetProductIdsByName(ctx context.Context, names []string) (map[string]int, error)
+	GetProductVariantsBySku(ctx context.Context, skuList []string) (map[string]ProductVariant, error)
+	GetCatalogueProducts(ctx context.Context) ([]ProductInput, error)
+	GetCatalogueVariants(ctx context.Context) ([]ProductVariant, map[int]map[string]string, error)
+	GetCatalogueTranslations(ctx context.Context) ([]CatalogueTranslation, error)
+	GetCatalogueRecipes(ctx context.Context) ([]RecipeBase, error)
+	GetCatalogueStock(ctx context.Context, warehouseId int) ([]BatchInput, error)
+	GetCatalogueOpeningBalance(ctx context.Context, warehouseId int) ([]OpeningBalanceLine, error)
+}
+
+type CatalogueRepository struct {
+	*pgxpool.Pool
+}
+
+func NewCatalogueRepository(dbPool *pgxpool.Pool) ICatalogueRepository {
+	return &CatalogueRepository{dbPool}
+}
+
+// GetProductIdsByName looks products up by their default language name which is how catalogue files refer to them
+func (r *CatalogueRepository) GetProductIdsByName(ctx context.Context, names []string) (map[string]int, error) {
+	sql := `select product_id, name from product_translations where language_code = $1 and name = any($2)`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, common.DefaultLang, names)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get products by name", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get products")
+	}
+	defer rows.Close()
+	ids := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		ids[name] = id
+	}
+	return ids, nil
+}
+
+func (r *CatalogueRepository) GetProductVariantsBySku(ctx context.Context, skuList []string) (map[string]ProductVariant, error) {
+	sql := `select id, product_id, sku from product_variants where sku = any($1)`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, skuList)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get product variants by sku", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get product variants")
+	}
+	defer rows.Close()
+	variants := make(map[string]ProductVariant)
+	for rows.Next() {
+		var variant ProductVariant
+		if err := rows.Scan(&variant.Id, &variant.ProductId, &variant.Sku); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product variant", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		variants[variant.Sku] = variant
+	}
+	return variants, nil
+}
+
+// GetCatalogueProducts returns every product with the details of its default variant and its options
+func (r *CatalogueRepository) GetCatalogueProducts(ctx context.Context) ([]ProductInput, error) {
+	sql := `
+	select p.id, ptx.name, coalesce(ptx.description, ''), coalesce(p.image, ''), p.category_id,
+	p.is_ingredient, p.is_archived, pvar.price, pvar.standard_unit_id, pvar.expires_in_days,
+	pvar.storage_class, pvar.sku, coalesce(pvar.barcode, '')
+	from products p
+	join product_translations ptx on ptx.product_id = p.id and ptx.language_code = $1
+	join product_variants pvar on pvar.product_id = p.id and pvar.is_default
+	order by p.id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, common.DefaultLang)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export products", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export products")
+	}
+	defer rows.Close()
+	products := make([]ProductInput, 0)
+	for rows.Next() {
+		var product ProductInput
+		err := rows.Scan(
+			&product.Id, &product.Name, &product.Description, &product.Image, &product.CategoryId,
+			&product.IsIngredient, &product.IsArchived, &product.Price, &product.StandardUnitId,
+			&product.ExpiresInDays, &product.StorageClass, &product.Sku, &product.Barcode,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		products = append(products, product)
+	}
+	rows.Close()
+	options, err := r.getCatalogueProductOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, product := range products {
+		products[i].Options = options[*product.Id]
+	}
+	return products, nil
+}
+
+func (r *CatalogueRepository) getCatalogueProductOptions(ctx context.Context) (map[int][]ProductOption, error) {
+	sql := `
+	select popt.product_id, popt.id, popt.name, pvl.value from product_options popt
+	join product_option_values pvl on pvl.product_option_id = popt.id
+	where popt.language_code = $1
+	order by popt.product_id, popt.id, pvl.id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, common.DefaultLang)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export product options", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export product options")
+	}
+	defer rows.Close()
+	options := make(map[int][]ProductOption)
+	for rows.Next() {
+		var productId, optionId int
+		var option ProductOption
+		var value ProductOptionValue
+		if err := rows.Scan(&productId, &optionId, &option.Name, &value.Value); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product option", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		productOptions := options[productId]
+		last := len(productOptions) - 1
+		if last < 0 || *productOptions[last].Id != optionId {
+			option.Id = &optionId
+			productOptions = append(productOptions, option)
+			last++
+		}
+		productOptions[last].Values = append(productOptions[last].Values, value)
+		options[productId] = productOptions
+	}
+	return options, nil
+}
+
+// GetCatalogueVariants returns every variant besides the default ones which are part of the products sheet,
+// along with the option values of each variant by option name
+func (r *CatalogueRepository) GetCatalogueVariants(ctx context.Context) ([]ProductVariant, map[int]map[string]string, error) {
+	sql := `
+	select pvar.id, ptx.name, pvar.sku, coalesce(pvar.barcode, ''), pvar.price, pvar.width_in_cm,
+	pvar.height_in_cm, pvar.depth_in_cm, pvar.weight_in_g, pvar.standard_unit_id,
+	pvar.expires_in_days, pvar.storage_class, pvar.is_archived
+	from product_variants pvar
+	join product_translations ptx on ptx.product_id = pvar.product_id and ptx.language_code = $1
+	where not pvar.is_default
+	order by pvar.id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, common.DefaultLang)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export product variants", zap.Error(err))
+		return nil, nil, common.NewBadRequestFromMessage("Failed to export product variants")
+	}
+	defer rows.Close()
+	variants := make([]ProductVariant, 0)
+	for rows.Next() {
+		var variant ProductVariant
+		err := rows.Scan(
+			&variant.Id, &variant.ProductName, &variant.Sku, &variant.Barcode, &variant.Price,
+			&variant.WidthInCm, &variant.HeightInCm, &variant.DepthInCm, &variant.WeightInG,
+			&variant.StandardUnitId, &variant.ExpiresInDays, &variant.StorageClass, &variant.IsArchived,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product variant", zap.Error(err))
+			return nil, nil, common.NewInternalServerError()
+		}
+		variants = append(variants, variant)
+	}
+	rows.Close()
+	values, err := r.getCatalogueVariantValues(ctx)
+	return variants, values, err
+}
+
+func (r *CatalogueRepository) getCatalogueVariantValues(ctx context.Context) (map[int]map[string]string, error) {
+	sql := `
+	select pvv.product_variant_id, popt.name, pvl.value from product_variant_values pvv
+	join product_option_values pvl on pvl.id = pvv.product_option_value_id
+	join product_options popt on popt.id = pvl.product_option_id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export product variant values", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export product variant values")
+	}
+	defer rows.Close()
+	values := make(map[int]map[string]string)
+	for rows.Next() {
+		var variantId int
+		var optionName, value string
+		if err := rows.Scan(&variantId, &optionName, &value); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product variant value", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		if values[variantId] == nil {
+			values[variantId] = make(map[string]string)
+		}
+		values[variantId][optionName] = value
+	}
+	return values, nil
+}
+
+// GetCatalogueTranslations returns the product and variant names in every language besides the default one
+func (r *CatalogueRepository) GetCatalogueTranslations(ctx context.Context) ([]CatalogueTranslation, error) {
+	sql := `
+	select 'product', dptx.name, ptx.language_code, ptx.name, coalesce(ptx.description, '')
+	from product_translations ptx
+	join product_translations dptx on dptx.product_id = ptx.product_id and dptx.language_code = $1
+	where ptx.language_code <> $1
+	union all
+	select 'variant', pvar.sku, pvartx.language_code, pvartx.name, ''
+	from product_variant_translations pvartx
+	join product_variants pvar on pvar.id = pvartx.product_variant_id
+	where pvartx.language_code <> $1
+	order by 1, 2, 3
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, common.DefaultLang)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export translations", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export translations")
+	}
+	defer rows.Close()
+	translations := make([]CatalogueTranslation, 0)
+	for rows.Next() {
+		var translation CatalogueTranslation
+		err := rows.Scan(
+			&translation.Type, &translation.Key, &translation.LanguageCode,
+			&translation.Name, &translation.Description,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan translation", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		translations = append(translations, translation)
+	}
+	return translations, nil
+}
+
+func (r *CatalogueRepository) GetCatalogueRecipes(ctx context.Context) ([]RecipeBase, error) {
+	sql := `select result_variant_sku, recipe_variant_sku, quantity, unit_id from recipes order by id`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export recipes", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export recipes")
+	}
+	defer rows.Close()
+	recipes := make([]RecipeBase, 0)
+	for rows.Next() {
+		var recipe RecipeBase
+		err := rows.Scan(&recipe.ResultVariantSku, &recipe.RecipeVariantSku, &recipe.Quantity, &recipe.UnitId)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan recipe", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
+
+// GetCatalogueStock returns the quantity in stock of every sku in the warehouse
+func (r *CatalogueRepository) GetCatalogueStock(ctx context.Context, warehouseId int) ([]BatchInput, error) {
+	sql := `
+	select sku, sum(quantity), unit_id from batches
+	where warehouse_id = $1
+	group by sku, unit_id
+	having sum(quantity) > 0
+	order by sku
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, warehouseId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export stock", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export stock")
+	}
+	defer rows.Close()
+	stock := make([]BatchInput, 0)
+	for rows.Next() {
+		var input BatchInput
+		if err := rows.Scan(&input.Sku, &input.Quantity, &input.UnitId); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan stock", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		stock = append(stock, input)
+	}
+	return stock, nil
+}
+
+// GetCatalogueOpeningBalance returns every batch in stock in the warehouse, the cost is the price
+// of the variant per its standard unit which is the unit batches are kept in
+func (r *CatalogueRepository) GetCatalogueOpeningBalance(ctx context.Context, warehouseId int) ([]OpeningBalanceLine, error) {
+	sql := `
+	select b.sku, b.quantity, b.unit_id, b.expires_at, pvar.price, coalesce(b.lot_code, '') from batches b
+	join product_variants pvar on pvar.sku = b.sku
+	where b.warehouse_id = $1 and b.quantity > 0
+	order by b.sku, b.expires_at
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, warehouseId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to export opening balance", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to export opening balance")
+	}
+	defer rows.Close()
+	lines := make([]OpeningBalanceLine, 0)
+	for rows.Next() {
+		var line OpeningBalanceLine
+		var expiresAt time.Time
+		if err := rows.Scan(&line.Sku, &line.Quantity, &line.UnitId, &expiresAt, &line.Cost, &line.LotCode); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan opening balance", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		line.ExpiresAt = expiresAt.Format(time.DateOnly)
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
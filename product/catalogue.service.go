@@ -0,0 +1,382 @@
+package product
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+)
+
+type ICatalogueService interface {
+	Import(ctx context.Context, kind string, rows [][]string) (CatalogueImportResult, error)
+	Export(ctx context.Context, kind string) ([][]string, error)
+}
+
+type CatalogueService struct {
+	repo           ICatalogueRepository
+	productRepo    IProductRepo
+	productService IProductService
+	recipeService  IRecipeService
+	batchService   IBatchService
+}
+
+type catalogueImport func(ctx context.Context) error
+
+func NewCatalogueService(
+	repo ICatalogueRepository,
+	productRepo IProductRepo,
+	productService IProductService,
+	recipeService IRecipeService,
+	batchService IBatchService,
+) ICatalogueService {
+	return &CatalogueService{
+		repo,
+		productRepo,
+		productService,
+		recipeService,
+		batchService,
+	}
+}
+
+// Import validates every row before anything is written, then applies all rows in a single
+// transaction so a failing row leaves the catalogue untouched
+func (s *CatalogueService) Import(ctx context.Context, kind string, rows [][]string) (CatalogueImportResult, error) {
+	if !IsCatalogueKind(kind) {
+		return CatalogueImportResult{}, common.NewNotFoundError("catalogue sheet not found")
+	}
+	if isWarehouseCatalogueKind(kind) && warehouse.GetWarehouseId(ctx) == 0 {
+		return CatalogueImportResult{}, common.NewBadRequestError("warehouse is required", "warehouse_required")
+	}
+	parsedRows, err := parseCatalogueRows(kind, rows)
+	if err != nil {
+		return CatalogueImportResult{}, err
+	}
+	var apply catalogueImport
+	switch kind {
+	case CatalogueProducts:
+		apply = s.prepareProductsImport(parsedRows)
+	case CatalogueVariants:
+		apply, err = s.prepareVariantsImport(ctx, parsedRows)
+	case CatalogueTranslations:
+		apply, err = s.prepareTranslationsImport(ctx, parsedRows)
+	case CatalogueRecipes:
+		apply = s.prepareRecipesImport(parsedRows)
+	case CatalogueStock:
+		apply = s.prepareStockImport(parsedRows)
+	case CatalogueOpeningBalance:
+		apply = s.prepareOpeningBalanceImport(ctx, parsedRows)
+	}
+	if err != nil {
+		return CatalogueImportResult{}, err
+	}
+	if err := getCatalogueRowsError(parsedRows); err != nil {
+		return CatalogueImportResult{}, err
+	}
+	err = common.RunWithTransaction(ctx, s.repo.(*CatalogueRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		return apply(ctx)
+	})
+	if err != nil {
+		return CatalogueImportResult{}, err
+	}
+	return CatalogueImportResult{Kind: kind, Rows: len(parsedRows)}, nil
+}
+
+func (s *CatalogueService) prepareProductsImport(rows []*catalogueRow) catalogueImport {
+	inputs := make([]ProductInput, len(rows))
+	for i, row := range rows {
+		options, ok := parseProductOptions(row.getString("options"))
+		if !ok {
+			row.addError("options", "options must be written as name=value|value;name=value")
+		}
+		inputs[i] = ProductInput{
+			ProductBase: ProductBase{
+				Name:         row.getStringPtr("name"),
+				Description:  row.getString("description"),
+				Image:        row.getString("image"),
+				CategoryId:   row.getIntPtr("category_id"),
+				IsIngredient: row.getBool("is_ingredient"),
+				IsArchived:   row.getBool("is_archived"),
+			},
+			Price:          row.getFloat("price"),
+			StandardUnitId: row.getIntPtr("standard_unit_id"),
+			ExpiresInDays:  row.getInt("expires_in_days"),
+			StorageClass:   row.getString("storage_class"),
+			Options:        options,
+			Sku:            row.getString("sku"),
+			Barcode:        row.getString("barcode"),
+		}
+		row.addValidationError(ValidateProduct(inputs[i]))
+	}
+	return func(ctx context.Context) error {
+		for i, input := range inputs {
+			if err := s.productService.CreateProduct(ctx, input); err != nil {
+				return getCatalogueRowError(rows[i], err)
+			}
+		}
+		return nil
+	}
+}
+
+func (s *CatalogueService) prepareVariantsImport(ctx context.Context, rows []*catalogueRow) (catalogueImport, error) {
+	productIds, err := s.repo.GetProductIdsByName(ctx, getCatalogueColumn(rows, "product"))
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[int]Product)
+	inputs := make([]ProductVariantInput, len(rows))
+	for i, row := range rows {
+		productId, ok := productIds[row.getString("product")]
+		if !ok {
+			row.addError("product", "product does not exist")
+			continue
+		}
+		product, ok := products[productId]
+		if !ok {
+			if product, err = s.productRepo.GetProduct(ctx, productId); err != nil {
+				return nil, err
+			}
+			products[productId] = product
+		}
+		inputs[i] = ProductVariantInput{
+			ProductVariant: ProductVariant{ProductVariantBase: ProductVariantBase{
+				ProductId:      &productId,
+				Sku:            row.getString("sku"),
+				Barcode:        row.getString("barcode"),
+				Price:          row.getFloat("price"),
+				WidthInCm:      row.getFloatPtr("width_in_cm"),
+				HeightInCm:     row.getFloatPtr("height_in_cm"),
+				DepthInCm:      row.getFloatPtr("depth_in_cm"),
+				WeightInG:      row.getFloatPtr("weight_in_g"),
+				StandardUnitId: row.getIntPtr("standard_unit_id"),
+				ExpiresInDays:  row.getInt("expires_in_days"),
+				StorageClass:   row.getString("storage_class"),
+				IsArchived:     row.getBool("is_archived"),
+			}},
+			OptionValueIds: getCatalogueOptionValueIds(row, product),
+		}
+		row.addValidationError(ValidateProductVariant(inputs[i], len(product.Options), len(product.Options)))
+	}
+	return func(ctx context.Context) error {
+		for i, input := range inputs {
+			if err := s.productService.AddProductVariant(ctx, input); err != nil {
+				return getCatalogueRowError(rows[i], err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+func getCatalogueOptionValueIds(row *catalogueRow, product Product) []int {
+	values, ok := parseVariantOptions(row.getString("options"))
+	if !ok {
+		row.addError("options", "options must be written as name=value;name=value")
+		return nil
+	}
+	ids := make([]int, 0, len(values))
+	for name, value := range values {
+		option := product.Options[name]
+		valueId := common.FirstWhere[ProductOptionValue](option.Values, func(optionValue ProductOptionValue) bool {
+			return optionValue.Value == value
+		})
+		if valueId == nil {
+			row.addError("options", name+"="+value+" is not an option of the product")
+			continue
+		}
+		ids = append(ids, *valueId.Id)
+	}
+	return ids
+}
+
+func (s *CatalogueService) prepareTranslationsImport(ctx context.Context, rows []*catalogueRow) (catalogueImport, error) {
+	productIds, err := s.repo.GetProductIdsByName(ctx, getCatalogueColumn(rows, "key"))
+	if err != nil {
+		return nil, err
+	}
+	variants, err := s.repo.GetProductVariantsBySku(ctx, getCatalogueColumn(rows, "key"))
+	if err != nil {
+		return nil, err
+	}
+	applies := make([]catalogueImport, len(rows))
+	for i, row := range rows {
+		languageCode, name, description := row.getString("language_code"), row.getString("name"), row.getString("description")
+		row.addValidationError(validateCatalogueTranslation(languageCode, name, description))
+		switch row.getString("type") {
+		case catalogueProductTranslation:
+			productId, ok := productIds[row.getString("key")]
+			if !ok {
+				row.addError("key", "product does not exist")
+				continue
+			}
+			product := ProductInput{ProductBase: ProductBase{Id: &productId, Name: &name, Description: description}}
+			applies[i] = func(ctx context.Context) error {
+				return s.productRepo.TranslateProduct(ctx, product, languageCode)
+			}
+		case catalogueVariantTranslation:
+			variant, ok := variants[row.getString("key")]
+			if !ok {
+				row.addError("key", "product variant does not exist")
+				continue
+			}
+			variant.Name = name
+			applies[i] = func(ctx context.Context) error {
+				return s.productRepo.TranslateProductVariant(ctx, variant, languageCode)
+			}
+		default:
+			row.addError("type", "type must be "+catalogueProductTranslation+" or "+catalogueVariantTranslation)
+		}
+	}
+	return func(ctx context.Context) error {
+		for i, apply := range applies {
+			if err := apply(ctx); err != nil {
+				return getCatalogueRowError(rows[i], err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+func (s *CatalogueService) prepareRecipesImport(rows []*catalogueRow) catalogueImport {
+	recipes := make([]RecipeBase, len(rows))
+	for i, row := range rows {
+		recipes[i] = RecipeBase{
+			ResultVariantSku: row.getString("result_sku"),
+			RecipeVariantSku: row.getString("ingredient_sku"),
+			Quantity:         row.getFloat("quantity"),
+			UnitId:           row.getIntPtr("unit_id"),
+		}
+		row.addValidationError(ValidateRecipe(recipes[i]))
+	}
+	return func(ctx context.Context) error {
+		return s.recipeService.CreateRecipes(ctx, recipes)
+	}
+}
+
+func (s *CatalogueService) prepareStockImport(rows []*catalogueRow) catalogueImport {
+	inputs := make([]BatchInput, len(rows))
+	for i, row := range rows {
+		inputs[i] = BatchInput{
+			Sku:      row.getString("sku"),
+			Quantity: row.getFloat("quantity"),
+			UnitId:   row.getInt("unit_id"),
+			Reason:   transactions.TransactionReasonTypeAuditIncrease,
+			Comment:  "catalogue import",
+		}
+		row.addValidationError(ValidateBatchInputIncrement(inputs[i]))
+	}
+	return func(ctx context.Context) error {
+		return s.batchService.BulkIncrementBatch(ctx, inputs)
+	}
+}
+
+func (s *CatalogueService) prepareOpeningBalanceImport(ctx context.Context, rows []*catalogueRow) catalogueImport {
+	input := OpeningBalanceInput{
+		Override: common.GetBoolFromContext(ctx, OpeningBalanceOverrideKey{}),
+		Lines:    make([]OpeningBalanceLine, len(rows)),
+	}
+	lineKeys := make(map[string]bool)
+	for i, row := range rows {
+		input.Lines[i] = OpeningBalanceLine{
+			Sku:       row.getString("sku"),
+			Quantity:  row.getFloat("quantity"),
+			UnitId:    row.getInt("unit_id"),
+			ExpiresAt: row.getString("expires_at"),
+			Cost:      row.getFloat("cost"),
+			LotCode:   row.getString("lot_code"),
+		}
+		for _, detail := range validateOpeningBalanceLine(input.Lines[i], "", lineKeys) {
+			row.addError(detail.Field, detail.Message)
+		}
+	}
+	return func(ctx context.Context) error {
+		return s.batchService.ImportOpeningBalance(ctx, input)
+	}
+}
+
+func getCatalogueColumn(rows []*catalogueRow, column string) []string {
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = row.getString(column)
+	}
+	return values
+}
+
+func (s *CatalogueService) Export(ctx context.Context, kind string) ([][]string, error) {
+	if !IsCatalogueKind(kind) {
+		return nil, common.NewNotFoundError("catalogue sheet not found")
+	}
+	rows := [][]string{catalogueHeaders[kind]}
+	switch kind {
+	case CatalogueProducts:
+		products, err := s.repo.GetCatalogueProducts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			rows = append(rows, []string{
+				*product.Name, product.Description, product.Image, formatCatalogueIntPtr(product.CategoryId),
+				strconv.FormatBool(product.IsIngredient), strconv.FormatBool(product.IsArchived),
+				formatCatalogueFloat(product.Price), formatCatalogueIntPtr(product.StandardUnitId),
+				strconv.Itoa(product.ExpiresInDays), product.StorageClass, formatProductOptions(product.Options),
+				product.Sku, product.Barcode,
+			})
+		}
+	case CatalogueVariants:
+		variants, values, err := s.repo.GetCatalogueVariants(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			rows = append(rows, []string{
+				variant.ProductName, formatVariantOptions(values[*variant.Id]), variant.Sku, variant.Barcode,
+				formatCatalogueFloat(variant.Price), formatCatalogueFloatPtr(variant.WidthInCm),
+				formatCatalogueFloatPtr(variant.HeightInCm), formatCatalogueFloatPtr(variant.DepthInCm),
+				formatCatalogueFloatPtr(variant.WeightInG), formatCatalogueIntPtr(variant.StandardUnitId),
+				strconv.Itoa(variant.ExpiresInDays), variant.StorageClass, strconv.FormatBool(variant.IsArchived),
+			})
+		}
+	case CatalogueTranslations:
+		translations, err := s.repo.GetCatalogueTranslations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, translation := range translations {
+			rows = append(rows, []string{
+				translation.Type, translation.Key, translation.LanguageCode, translation.Name, translation.Description,
+			})
+		}
+	case CatalogueRecipes:
+		recipes, err := s.repo.GetCatalogueRecipes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, recipe := range recipes {
+			rows = append(rows, []string{
+				recipe.ResultVariantSku, recipe.RecipeVariantSku, formatCatalogueFloat(recipe.Quantity),
+				formatCatalogueIntPtr(recipe.UnitId),
+			})
+		}
+	case CatalogueStock:
+		stock, err := s.repo.GetCatalogueStock(ctx, warehouse.GetWarehouseId(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, input := range stock {
+			rows = append(rows, []string{input.Sku, formatCatalogueFloat(input.Quantity), strconv.Itoa(input.UnitId)})
+		}
+	case CatalogueOpeningBalance:
+		lines, err := s.repo.GetCatalogueOpeningBalance(ctx, warehouse.GetWarehouseId(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			rows = append(rows, []string{
+				line.Sku, formatCatalogueFloat(line.Quantity), strconv.Itoa(line.UnitId),
+				line.ExpiresAt, formatCatalogueFloat(line.Cost), line.LotCode,
+			})
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,88 @@
+package product
+
+import (
+	"net/http"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+type CategoryController struct {
+	service ICategoryService
+}
+
+func NewCategoryController(service ICategoryService) CategoryController {
+	return CategoryController{
+		service,
+	}
+}
+
+func (c CategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[CategoryInput](w, r.Body, func(input CategoryInput) {
+		err := c.service.CreateCategory(r.Context(), input)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Category created successfully",
+		})
+	})
+}
+
+func (c CategoryController) TranslateCategory(w http.ResponseWriter, r *http.Request) {
+	common.GetTranslatedBody[CategoryInput](w, r.Body, func(t common.Translation[CategoryInput]) {
+		err := c.service.TranslateCategory(r.Context(), t.Data, t.LanguageCode)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Category translated successfully",
+		})
+	})
+}
+
+func (c CategoryController) GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := c.service.GetCategories(r.Context())
+	common.WriteResponse(common.Result[[]Category]{
+		Error:  err,
+		Writer: w,
+		Data:   categories,
+	})
+}
+
+func (c CategoryController) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	category, err := c.service.GetCategory(r.Context(), id)
+	common.WriteResponse(common.Result[Category]{
+		Error:  err,
+		Writer: w,
+		Data:   category,
+	})
+}
+
+func (c CategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[CategoryInput](w, r.Body, func(input CategoryInput) {
+		err := c.service.UpdateCategory(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Category updated successfully",
+		})
+	})
+}
+
+func (c CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	err := c.service.DeleteCategory(r.Context(), id)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Error:   err,
+		Writer:  w,
+		Message: "Category deleted successfully",
+	})
+}
+
+func (c CategoryController) GetStockSummaryByCategory(w http.ResponseWriter, r *http.Request) {
+	summaries, err := c.service.GetStockSummaryByCategory(r.Context())
+	common.WriteResponse(common.Result[[]CategoryStockSummary]{
+		Error:  err,
+		Writer: w,
+		Data:   summaries,
+	})
+}
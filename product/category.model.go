@@ -1,6 +1,87 @@
 package product
 
 type Category struct {
-	Id   *int    `json:"id"`
-	Name *string `json:"name"`
+	Id       *int       `json:"id"`
+	Name     *string    `json:"name"`
+	ParentId *int       `json:"parentId,omitempty"`
+	Children []Category `json:"children,omitempty"`
+}
+
+type CategoryInput struct {
+	Id       *int   `json:"id,omitempty"`
+	Name     string `json:"name"`
+	ParentId *int   `json:"parentId,omitempty"`
+}
+
+type CategoryStockSummary struct {
+	Category   Category `json:"category"`
+	BatchCount int      `json:"batchCount"`
+	SkuCount   int      `json:"skuCount"`
+	TotalValue float64  `json:"totalValue"`
+}
+
+// CategoryStockLine sums the batches of one sku kept in the same unit
+type CategoryStockLine struct {
+	Category       Category
+	Sku            string
+	UnitId         int
+	StandardUnitId int
+	Price          float64
+	BatchCount     int
+	Quantity       float64
+}
+
+// buildCategoryStockSummaries values the lines at the price of their variant, so the quantities
+// must already be in the standard unit of the variant
+func buildCategoryStockSummaries(lines []CategoryStockLine) []CategoryStockSummary {
+	summaries := make([]CategoryStockSummary, 0)
+	summaryIndexes := make(map[int]int)
+	skusLookup := make(map[int]map[string]bool)
+	for _, line := range lines {
+		// products without a category share the key 0, category ids start at 1
+		categoryKey := 0
+		if line.Category.Id != nil {
+			categoryKey = *line.Category.Id
+		}
+		index, ok := summaryIndexes[categoryKey]
+		if !ok {
+			index = len(summaries)
+			summaryIndexes[categoryKey] = index
+			skusLookup[categoryKey] = make(map[string]bool)
+			summaries = append(summaries, CategoryStockSummary{Category: line.Category})
+		}
+		skusLookup[categoryKey][line.Sku] = true
+		summaries[index].BatchCount += line.BatchCount
+		summaries[index].SkuCount = len(skusLookup[categoryKey])
+		summaries[index].TotalValue += line.Quantity * line.Price
+	}
+	return summaries
+}
+
+// buildCategoryTree nests the categories under their parents, categories whose parent
+// isn't in the list become roots so a subtree can be built from a partial list
+func buildCategoryTree(categories []Category) []Category {
+	childrenLookup := make(map[int][]Category)
+	ids := make(map[int]bool, len(categories))
+	for _, category := range categories {
+		ids[*category.Id] = true
+	}
+	roots := make([]Category, 0)
+	for _, category := range categories {
+		if category.ParentId == nil || !ids[*category.ParentId] {
+			roots = append(roots, category)
+			continue
+		}
+		childrenLookup[*category.ParentId] = append(childrenLookup[*category.ParentId], category)
+	}
+	return attachChildren(roots, childrenLookup)
+}
+
+func attachChildren(categories []Category, childrenLookup map[int][]Category) []Category {
+	for i, category := range categories {
+		if children, ok := childrenLookup[*category.Id]; ok {
+			categories[i].Children = attachChildren(children, childrenLookup)
+		}
+	}
+	return categories
 }
@@ -0,0 +1,50 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCategory(id int, parentId *int) Category {
+	name := "category"
+	return Category{Id: &id, Name: &name, ParentId: parentId}
+}
+
+func TestBuildCategoryTreeNestsChildrenUnderParents(t *testing.T) {
+	food, drinks, juice, orangeJuice := 1, 2, 3, 4
+	tree := buildCategoryTree([]Category{
+		newTestCategory(orangeJuice, &juice),
+		newTestCategory(food, nil),
+		newTestCategory(juice, &drinks),
+		newTestCategory(drinks, nil),
+	})
+	assert.Len(t, tree, 2)
+	assert.Equal(t, food, *tree[0].Id)
+	assert.Empty(t, tree[0].Children)
+	assert.Equal(t, juice, *tree[1].Children[0].Id)
+	assert.Equal(t, orangeJuice, *tree[1].Children[0].Children[0].Id)
+}
+
+func TestBuildCategoryTreeTreatsMissingParentsAsRoots(t *testing.T) {
+	drinks, juice := 2, 3
+	tree := buildCategoryTree([]Category{newTestCategory(juice, &drinks)})
+	assert.Len(t, tree, 1)
+	assert.Equal(t, juice, *tree[0].Id)
+}
+
+func TestBuildCategoryStockSummariesGroupsLinesOfACategory(t *testing.T) {
+	dairy := newTestCategory(1, nil)
+	summaries := buildCategoryStockSummaries([]CategoryStockLine{
+		{Category: dairy, Sku: "milk", UnitId: 2, StandardUnitId: 2, Price: 2, BatchCount: 2, Quantity: 3},
+		{Category: dairy, Sku: "milk", UnitId: 1, StandardUnitId: 2, Price: 2, BatchCount: 1, Quantity: 1.5},
+		{Category: dairy, Sku: "cheese", UnitId: 2, StandardUnitId: 2, Price: 10, BatchCount: 1, Quantity: 1},
+		{Category: Category{}, Sku: "salt", UnitId: 2, StandardUnitId: 2, Price: 1, BatchCount: 1, Quantity: 4},
+	})
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, 4, summaries[0].BatchCount)
+	assert.Equal(t, 2, summaries[0].SkuCount)
+	assert.InDelta(t, 19.0, summaries[0].TotalValue, 1e-9)
+	assert.Nil(t, summaries[1].Category.Id)
+	assert.InDelta(t, 4.0, summaries[1].TotalValue, 1e-9)
+}
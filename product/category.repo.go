@@ -0,0 +1,231 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
+	zimutils "github.com/nayefradwi/zanobia_inventory_manager/zim_utils"
+	"go.uber.org/zap"
+)
+
+// categorySubtreeSql selects the ids of a category and all of its descendants, $1 is the root
+const categorySubtreeSql = `
+with recursive subtree as (
+	select id from categories where id = $1
+	union all
+	select c.id from categories c join subtree s on c.parent_id = s.id
+)
+select id from subtree
+`
+
+type ICategoryRepository interface {
+	CreateCategory(ctx context.Context, input CategoryInput) error
+	TranslateCategory(ctx context.Context, input CategoryInput, languageCode string) error
+	GetCategories(ctx context.Context) ([]Category, error)
+	GetCategorySubtree(ctx context.Context, id int) ([]Category, error)
+	UpdateCategory(ctx context.Context, input CategoryInput) error
+	IsInSubtree(ctx context.Context, rootId, id int) (bool, error)
+	LockCategoryPath(ctx context.Context, id, parentId int) error
+	DeleteCategory(ctx context.Context, id int) error
+	GetStockLinesByCategory(ctx context.Context) ([]CategoryStockLine, error)
+}
+
+type CategoryRepository struct {
+	*pgxpool.Pool
+}
+
+func NewCategoryRepository(dbPool *pgxpool.Pool) ICategoryRepository {
+	return &CategoryRepository{dbPool}
+}
+
+func (r *CategoryRepository) CreateCategory(ctx context.Context, input CategoryInput) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		sql := `INSERT INTO categories (parent_id) VALUES ($1) RETURNING id`
+		var id int
+		if err := tx.QueryRow(ctx, sql, input.ParentId).Scan(&id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to create category", zap.Error(err))
+			return common.NewBadRequestError("Failed to create category", zimutils.GetErrorCodeFromError(err))
+		}
+		input.Id = &id
+		return r.insertTranslation(ctx, input, common.DefaultLang)
+	})
+}
+
+func (r *CategoryRepository) insertTranslation(ctx context.Context, input CategoryInput, languageCode string) error {
+	sql := `INSERT INTO category_translations (category_id, name, language_code) VALUES ($1, $2, $3)`
+	op := common.GetOperator(ctx, r.Pool)
+	if _, err := op.Exec(ctx, sql, input.Id, input.Name, languageCode); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to insert category translation", zap.Error(err))
+		return common.NewBadRequestError("Failed to insert category translation", zimutils.GetErrorCodeFromError(err))
+	}
+	return nil
+}
+
+func (r *CategoryRepository) TranslateCategory(ctx context.Context, input CategoryInput, languageCode string) error {
+	return r.insertTranslation(ctx, input, languageCode)
+}
+
+func (r *CategoryRepository) GetCategories(ctx context.Context) ([]Category, error) {
+	sql := `
+	select c.id, ctgtx.name, c.parent_id from categories c
+	join category_translations ctgtx on ctgtx.category_id = c.id
+	where ctgtx.language_code = $1
+	order by ctgtx.name
+	`
+	return r.queryCategories(ctx, sql, common.GetLanguageParam(ctx))
+}
+
+func (r *CategoryRepository) GetCategorySubtree(ctx context.Context, id int) ([]Category, error) {
+	sql := `
+	select c.id, ctgtx.name, c.parent_id from categories c
+	join category_translations ctgtx on ctgtx.category_id = c.id
+	where c.id in (` + categorySubtreeSql + `) and ctgtx.language_code = $2
+	order by ctgtx.name
+	`
+	return r.queryCategories(ctx, sql, id, common.GetLanguageParam(ctx))
+}
+
+func (r *CategoryRepository) queryCategories(ctx context.Context, sql string, args ...interface{}) ([]Category, error) {
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, args...)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get categories", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get categories")
+	}
+	defer rows.Close()
+	categories := make([]Category, 0)
+	for rows.Next() {
+		var category Category
+		if err := rows.Scan(&category.Id, &category.Name, &category.ParentId); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan category", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// UpdateCategory renames the category in the requested language and moves it under its new parent
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, input CategoryInput) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		c, err := tx.Exec(ctx, `UPDATE categories SET parent_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, input.ParentId, input.Id)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to update category", zap.Error(err))
+			return common.NewBadRequestError("Failed to update category", zimutils.GetErrorCodeFromError(err))
+		}
+		if c.RowsAffected() == 0 {
+			return common.NewNotFoundError("Category not found")
+		}
+		sql := `INSERT INTO category_translations (category_id, name, language_code) VALUES ($1, $2, $3)
+		ON CONFLICT (category_id, language_code) DO UPDATE SET name = EXCLUDED.name`
+		if _, err := tx.Exec(ctx, sql, input.Id, input.Name, common.GetLanguageParam(ctx)); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to update category translation", zap.Error(err))
+			return common.NewBadRequestError("Failed to update category", zimutils.GetErrorCodeFromError(err))
+		}
+		return nil
+	})
+}
+
+func (r *CategoryRepository) IsInSubtree(ctx context.Context, rootId, id int) (bool, error) {
+	sql := `select exists (` + categorySubtreeSql + ` where id = $2)`
+	op := common.GetOperator(ctx, r.Pool)
+	var exists bool
+	if err := op.QueryRow(ctx, sql, rootId, id).Scan(&exists); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to check category subtree", zap.Error(err))
+		return false, common.NewInternalServerError()
+	}
+	return exists, nil
+}
+
+// LockCategoryPath locks the moved category and every ancestor of its new parent, any concurrent
+// move that could close a cycle with this one has to update one of these rows
+func (r *CategoryRepository) LockCategoryPath(ctx context.Context, id, parentId int) error {
+	sql := `
+	with recursive ancestors as (
+		select id, parent_id from categories where id = $2
+		union
+		select c.id, c.parent_id from categories c join ancestors a on c.id = a.parent_id
+	)
+	select id from categories where id = $1 or id in (select id from ancestors)
+	order by id for update
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, id, parentId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to lock categories", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to lock categories", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	return nil
+}
+
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, id int) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		var inUse bool
+		sql := `select exists (select 1 from categories where parent_id = $1)
+		or exists (select 1 from products where category_id = $1)`
+		if err := tx.QueryRow(ctx, sql, id).Scan(&inUse); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to check category usage", zap.Error(err))
+			return common.NewInternalServerError()
+		}
+		if inUse {
+			return common.NewBadRequestError("Category still has subcategories or products", "category_in_use")
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM category_translations WHERE category_id = $1`, id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to delete category translations", zap.Error(err))
+			return common.NewBadRequestFromMessage("Failed to delete category")
+		}
+		c, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to delete category", zap.Error(err))
+			return common.NewBadRequestFromMessage("Failed to delete category")
+		}
+		if c.RowsAffected() == 0 {
+			return common.NewNotFoundError("Category not found")
+		}
+		return nil
+	})
+}
+
+// GetStockLinesByCategory groups the batches of the current warehouse by the category of their product,
+// sku and unit, products without a category are grouped under a category without an id
+func (r *CategoryRepository) GetStockLinesByCategory(ctx context.Context) ([]CategoryStockLine, error) {
+	sql := `
+	select p.category_id, ctgtx.name, b.sku, b.unit_id, pvar.standard_unit_id, pvar.price, count(b.id), sum(b.quantity)
+	from batches b
+	join product_variants pvar on pvar.sku = b.sku
+	join products p on p.id = pvar.product_id
+	left join category_translations ctgtx on ctgtx.category_id = p.category_id and ctgtx.language_code = $2
+	where b.warehouse_id = $1
+	group by p.category_id, ctgtx.name, b.sku, b.unit_id, pvar.standard_unit_id, pvar.price
+	order by ctgtx.name, p.category_id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, warehouse.GetWarehouseId(ctx), common.GetLanguageParam(ctx))
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get stock lines by category", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get stock summary by category")
+	}
+	defer rows.Close()
+	lines := make([]CategoryStockLine, 0)
+	for rows.Next() {
+		var line CategoryStockLine
+		err := rows.Scan(
+			&line.Category.Id, &line.Category.Name, &line.Sku, &line.UnitId, &line.StandardUnitId,
+			&line.Price, &line.BatchCount, &line.Quantity,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan stock line", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,117 @@
+package product
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/unit"
+)
+
+type ICategoryService interface {
+	CreateCategory(ctx context.Context, input CategoryInput) error
+	TranslateCategory(ctx context.Context, input CategoryInput, languageCode string) error
+	GetCategories(ctx context.Context) ([]Category, error)
+	GetCategory(ctx context.Context, id int) (Category, error)
+	UpdateCategory(ctx context.Context, input CategoryInput) error
+	DeleteCategory(ctx context.Context, id int) error
+	GetStockSummaryByCategory(ctx context.Context) ([]CategoryStockSummary, error)
+}
+
+type CategoryService struct {
+	repo        ICategoryRepository
+	unitService unit.IUnitService
+}
+
+func NewCategoryService(repo ICategoryRepository, unitService unit.IUnitService) ICategoryService {
+	return &CategoryService{repo: repo, unitService: unitService}
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, input CategoryInput) error {
+	if err := ValidateCategory(input); err != nil {
+		return err
+	}
+	return s.repo.CreateCategory(ctx, input)
+}
+
+func (s *CategoryService) TranslateCategory(ctx context.Context, input CategoryInput, languageCode string) error {
+	if err := ValidateCategory(input); err != nil {
+		return err
+	}
+	if input.Id == nil {
+		return common.NewBadRequestFromMessage("category id is required")
+	}
+	return s.repo.TranslateCategory(ctx, input, languageCode)
+}
+
+func (s *CategoryService) GetCategories(ctx context.Context) ([]Category, error) {
+	categories, err := s.repo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+func (s *CategoryService) GetCategory(ctx context.Context, id int) (Category, error) {
+	categories, err := s.repo.GetCategorySubtree(ctx, id)
+	if err != nil {
+		return Category{}, err
+	}
+	for _, category := range buildCategoryTree(categories) {
+		if *category.Id == id {
+			return category, nil
+		}
+	}
+	return Category{}, common.NewNotFoundError("Category not found")
+}
+
+func (s *CategoryService) UpdateCategory(ctx context.Context, input CategoryInput) error {
+	if err := ValidateCategory(input); err != nil {
+		return err
+	}
+	if input.Id == nil {
+		return common.NewBadRequestFromMessage("category id is required")
+	}
+	return common.RunWithTransaction(ctx, s.repo.(*CategoryRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		if input.ParentId != nil {
+			if err := s.repo.LockCategoryPath(ctx, *input.Id, *input.ParentId); err != nil {
+				return err
+			}
+			isDescendant, err := s.repo.IsInSubtree(ctx, *input.Id, *input.ParentId)
+			if err != nil {
+				return err
+			}
+			if isDescendant {
+				return common.NewBadRequestError("A category can't be moved under itself or one of its subcategories", "category_cycle")
+			}
+		}
+		return s.repo.UpdateCategory(ctx, input)
+	})
+}
+
+func (s *CategoryService) DeleteCategory(ctx context.Context, id int) error {
+	return s.repo.DeleteCategory(ctx, id)
+}
+
+func (s *CategoryService) GetStockSummaryByCategory(ctx context.Context) ([]CategoryStockSummary, error) {
+	lines, err := s.repo.GetStockLinesByCategory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if line.UnitId == line.StandardUnitId {
+			continue
+		}
+		converted, err := s.unitService.ConvertUnit(ctx, unit.ConvertUnitInput{
+			ToUnitId:   &line.StandardUnitId,
+			FromUnitId: &line.UnitId,
+			Quantity:   line.Quantity,
+			Sku:        line.Sku,
+		})
+		if err != nil {
+			return nil, err
+		}
+		lines[i].Quantity = converted.Quantity
+	}
+	return buildCategoryStockSummaries(lines), nil
+}
@@ -2,6 +2,8 @@ package product
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
@@ -40,9 +42,13 @@ func (c ProductController) TranslateProduct(w http.ResponseWriter, r *http.Reque
 }
 
 func (c ProductController) GetProducts(w http.ResponseWriter, r *http.Request) {
-	isArchive := r.URL.Query().Get("isArchive") == "true"
-	isIngredient := r.URL.Query().Get("isIngredient") == "true"
-	products, err := c.service.GetProducts(r.Context(), isArchive, isIngredient)
+	categoryId, _ := strconv.Atoi(r.URL.Query().Get("categoryId"))
+	filter := ProductFilter{
+		IsArchive:    r.URL.Query().Get("isArchive") == "true",
+		IsIngredient: r.URL.Query().Get("isIngredient") == "true",
+		CategoryId:   categoryId,
+	}
+	products, err := c.service.GetProducts(r.Context(), filter)
 	common.WriteResponse(common.Result[common.PaginatedResponse[ProductBase]]{
 		Error:  err,
 		Writer: w,
@@ -186,16 +192,36 @@ func (c ProductController) UnarchiveProductVariant(w http.ResponseWriter, r *htt
 	)
 }
 
-func (c ProductController) SearchProductVariantByName(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	variantsPage, err := c.service.SearchProductVariantByName(r.Context(), name)
-	common.WriteResponse(common.Result[common.PaginatedResponse[ProductVariant]]{
+func (c ProductController) SearchProductVariants(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	categoryId, _ := strconv.Atoi(query.Get("categoryId"))
+	term := query.Get("term")
+	if term == "" {
+		term = query.Get("name")
+	}
+	filter := ProductVariantSearchFilter{
+		Term:         strings.TrimSpace(term),
+		CategoryId:   categoryId,
+		IsArchived:   getOptionalBoolQueryParam(r, "isArchived"),
+		IsIngredient: getOptionalBoolQueryParam(r, "isIngredient"),
+		InStock:      query.Get("inStock") == "true",
+	}
+	variantsPage, err := c.service.SearchProductVariants(r.Context(), filter)
+	common.WriteResponse(common.Result[common.PaginatedResponse[ProductVariantSearchResult]]{
 		Error:  err,
 		Writer: w,
 		Data:   variantsPage,
 	})
 }
 
+func getOptionalBoolQueryParam(r *http.Request, key string) *bool {
+	value, err := strconv.ParseBool(r.URL.Query().Get(key))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
 func (c ProductController) GetProductVariantBySku(w http.ResponseWriter, r *http.Request) {
 	sku := chi.URLParam(r, "sku")
 	withRecipe := common.GetBoolQueryParam(r, "withRecipe")
@@ -217,3 +243,14 @@ func (c ProductController) AddProductOptionToProduct(w http.ResponseWriter, r *h
 		})
 	})
 }
+
+func (c ProductController) GenerateProductVariants(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[VariantGenerationInput](w, r.Body, func(input VariantGenerationInput) {
+		result, err := c.service.GenerateProductVariants(r.Context(), input)
+		common.WriteResponse(common.Result[VariantGenerationResult]{
+			Error:  err,
+			Writer: w,
+			Data:   result,
+		})
+	})
+}
@@ -46,6 +46,13 @@ type ProductBase struct {
 	IsIngredient bool    `json:"isIngredient"`
 }
 
+type ProductFilter struct {
+	IsArchive    bool
+	IsIngredient bool
+	// CategoryId matches the category and all of its subcategories, 0 means any category
+	CategoryId int
+}
+
 func (p ProductBase) GetCursorValue() []string {
 	return []string{strconv.Itoa(*p.Id)}
 }
@@ -59,11 +66,15 @@ type Product struct {
 
 type ProductInput struct {
 	ProductBase
-	ExpiresInDays         int             `json:"expiresInDays"`
-	StandardUnitId        *int            `json:"standardUnitId,omitempty"`
-	Price                 float64         `json:"price"`
-	Options               []ProductOption `json:"options,omitempty"`
-	ProductVariants       []ProductVariant
+	ExpiresInDays   int             `json:"expiresInDays"`
+	StorageClass    string          `json:"storageClass"`
+	StandardUnitId  *int            `json:"standardUnitId,omitempty"`
+	Price           float64         `json:"price"`
+	Options         []ProductOption `json:"options,omitempty"`
+	ProductVariants []ProductVariant
+	// Sku and Barcode are given to the default variant, a sku is generated when it is empty
+	Sku                   string `json:"sku,omitempty"`
+	Barcode               string `json:"barcode,omitempty"`
 	skuOptionValuesLookup map[string]OptionValueSet
 }
 
@@ -72,6 +83,7 @@ type ProductVariantBase struct {
 	ProductId      *int     `json:"productId,omitempty"`
 	Name           string   `json:"name"`
 	Sku            string   `json:"sku,omitempty"`
+	Barcode        string   `json:"barcode,omitempty"`
 	Image          string   `json:"image,omitempty"`
 	Price          float64  `json:"price,omitempty"`
 	WidthInCm      *float64 `json:"widthInCm,omitempty"`
@@ -82,6 +94,7 @@ type ProductVariantBase struct {
 	IsArchived     bool     `json:"isArchived"`
 	IsDefault      bool     `json:"isDefault"`
 	ExpiresInDays  int      `json:"expiresInDays,omitempty"`
+	StorageClass   string   `json:"storageClass,omitempty"`
 }
 
 type ProductVariant struct {
@@ -101,6 +114,9 @@ type ProductVariantUpdate struct {
 	DepthInCm  *float64 `json:"depthInCm,omitempty"`
 	WeightInG  *float64 `json:"weightInG,omitempty"`
 	IsArchived bool     `json:"isArchived"`
+	Barcode    string   `json:"barcode,omitempty"`
+	// StorageClass is kept when empty
+	StorageClass string `json:"storageClass,omitempty"`
 }
 
 type ProductVariantInput struct {
@@ -155,7 +171,10 @@ func (p ProductInput) generateProductVariant() ([]ProductVariant, map[string]Opt
 }
 
 func (p ProductInput) createProductVariant(value string, isDefault bool) ProductVariant {
-	uuid, _ := common.GenerateUuid()
+	uuid := p.Sku
+	if uuid == "" {
+		uuid, _ = common.GenerateUuid()
+	}
 	return ProductVariant{
 		ProductVariantBase: ProductVariantBase{
 			Price:          p.Price,
@@ -164,8 +183,10 @@ func (p ProductInput) createProductVariant(value string, isDefault bool) Product
 			Image:          p.Image,
 			StandardUnitId: p.StandardUnitId,
 			ExpiresInDays:  p.ExpiresInDays,
+			StorageClass:   p.StorageClass,
 			Name:           value,
 			Sku:            uuid,
+			Barcode:        p.Barcode,
 		},
 	}
 }
@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -15,7 +16,7 @@ type IProductRepo interface {
 	CreateProduct(ctx context.Context, product ProductInput) error
 	AddProductVariant(ctx context.Context, input ProductVariantInput) error
 	TranslateProduct(ctx context.Context, product ProductInput, languageCode string) error
-	GetProducts(ctx context.Context, paginationParams common.PaginationParams, isArchive, isIngredient bool) ([]ProductBase, error)
+	GetProducts(ctx context.Context, paginationParams common.PaginationParams, filter ProductFilter) ([]ProductBase, error)
 	GetProduct(ctx context.Context, id int) (Product, error)
 	GetProductVariantsOfProduct(ctx context.Context, productId int) ([]ProductVariant, error)
 	GetProductVariant(ctx context.Context, productVariantId int) (ProductVariant, error)
@@ -33,8 +34,11 @@ type IProductRepo interface {
 	UpdateProductArchiveStatus(ctx context.Context, id int, isArchived bool) error
 	UpdateProductVariantArchiveStatus(ctx context.Context, id int, isArchived bool) error
 	GetProductVariantBySku(ctx context.Context, sku string) (ProductVariant, error)
-	SearchProductVariantsByName(ctx context.Context, paginationParams common.PaginationParams, name string) ([]ProductVariant, error)
+	SearchProductVariants(ctx context.Context, paginationParams common.PaginationParams, filter ProductVariantSearchFilter) ([]ProductVariantSearchResult, error)
 	AddProductOption(ctx context.Context, input ProductOptionInput) error
+	AddGeneratedProductVariants(ctx context.Context, inputs []ProductVariantInput) error
+	GetProductVariantCombinations(ctx context.Context, productId int) ([]VariantCombination, error)
+	TranslateProductVariant(ctx context.Context, productVariant ProductVariant, languageCode string) error
 }
 
 type ProductRepo struct {
@@ -150,15 +154,15 @@ func (r *ProductRepo) insertProductVariant(ctx context.Context, productId *int,
 	sql := `INSERT INTO product_variants
 	(
 		product_id, price, sku, is_archived, is_default, image,
-		standard_unit_id, expires_in_days
+		standard_unit_id, expires_in_days, barcode, storage_class
 	) values (
-		$1, $2, $3, $4, $5, $6, $7, $8
+		$1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10
 	) RETURNING id`
 	var id int
 	err := op.QueryRow(
 		ctx, sql, productId, productVariant.Price, productVariant.Sku, productVariant.IsArchived,
 		productVariant.IsDefault, productVariant.Image, productVariant.StandardUnitId,
-		productVariant.ExpiresInDays,
+		productVariant.ExpiresInDays, productVariant.Barcode, productVariant.StorageClass,
 	).Scan(&id)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to insert product variant", zap.Error(err))
@@ -262,11 +266,23 @@ func (r *ProductRepo) addProductOptionValue(ctx context.Context, optionId int, p
 func (r *ProductRepo) GetProducts(
 	ctx context.Context,
 	paginationParams common.PaginationParams,
-	isArchive bool,
-	isIngredient bool,
+	filter ProductFilter,
 ) ([]ProductBase, error) {
 	op := common.GetOperator(ctx, r.Pool)
 	languageCode := common.GetLanguageParam(ctx)
+	conditions := []string{
+		"is_archived = $1",
+		"and",
+		"ptx.language_code = $2",
+		"and",
+		"is_ingredient = $3",
+	}
+	args := []interface{}{filter.IsArchive, languageCode, filter.IsIngredient}
+	if filter.CategoryId != 0 {
+		subtreeSql := strings.ReplaceAll(categorySubtreeSql, "$1", "$4")
+		conditions = append(conditions, "and", "p.category_id in ("+subtreeSql+")")
+		args = append(args, filter.CategoryId)
+	}
 	sqlBuilder := common.NewPaginationQueryBuilder(
 		`
 		select p.id, ptx.name, ptx.description, p.image, p.is_archived, p.category_id
@@ -276,17 +292,11 @@ func (r *ProductRepo) GetProducts(
 	)
 	rows, err := sqlBuilder.
 		WithOperator(op).
-		WithConditions([]string{
-			"is_archived = $1",
-			"and",
-			"ptx.language_code = $2",
-			"and",
-			"is_ingredient = $3",
-		}).
+		WithConditions(conditions).
 		WithCursorKeys([]string{"p.id"}).
 		WithParams(paginationParams).
 		Build().
-		Query(ctx, isArchive, languageCode, isIngredient)
+		Query(ctx, args...)
 
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to get products", zap.Error(err))
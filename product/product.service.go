@@ -6,13 +6,14 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 	"go.uber.org/zap"
 )
 
 type IProductService interface {
 	CreateProduct(ctx context.Context, product ProductInput) error
 	TranslateProduct(ctx context.Context, product ProductInput, languageCode string) error
-	GetProducts(ctx context.Context, isArchive bool, isIngredient bool) (common.PaginatedResponse[ProductBase], error)
+	GetProducts(ctx context.Context, filter ProductFilter) (common.PaginatedResponse[ProductBase], error)
 	GetProduct(ctx context.Context, id int) (Product, error)
 	GetProductVariant(ctx context.Context, productVariantId int) (ProductVariant, error)
 	AddProductVariant(ctx context.Context, input ProductVariantInput) error
@@ -26,9 +27,10 @@ type IProductService interface {
 	DeleteProduct(ctx context.Context, id int) error
 	UpdateProductArchiveStatus(ctx context.Context, id int, isArchive bool) error
 	UpdateProductVariantArchiveStatus(ctx context.Context, id int, isArchive bool) error
-	SearchProductVariantByName(ctx context.Context, name string) (common.PaginatedResponse[ProductVariant], error)
+	SearchProductVariants(ctx context.Context, filter ProductVariantSearchFilter) (common.PaginatedResponse[ProductVariantSearchResult], error)
 	GetProductVariantBySku(ctx context.Context, sku string, getRecipe bool) (ProductVariant, error)
 	AddProductOption(ctx context.Context, input ProductOptionInput) error
+	GenerateProductVariants(ctx context.Context, input VariantGenerationInput) (VariantGenerationResult, error)
 }
 
 type ProductService struct {
@@ -59,9 +61,9 @@ func (s *ProductService) TranslateProduct(ctx context.Context, product ProductIn
 	return s.repo.TranslateProduct(ctx, product, languageCode)
 }
 
-func (s *ProductService) GetProducts(ctx context.Context, isArchive bool, isIngredient bool) (common.PaginatedResponse[ProductBase], error) {
+func (s *ProductService) GetProducts(ctx context.Context, filter ProductFilter) (common.PaginatedResponse[ProductBase], error) {
 	paginationParams := common.GetPaginationParams(ctx)
-	products, err := s.repo.GetProducts(ctx, paginationParams, isArchive, isIngredient)
+	products, err := s.repo.GetProducts(ctx, paginationParams, filter)
 	if err != nil {
 		return common.CreateEmptyPaginatedResponse[ProductBase](paginationParams.PageSize), err
 	}
@@ -194,6 +196,11 @@ func (s *ProductService) UpdateProductVariantDetails(ctx context.Context, update
 			Field:   "price",
 		})
 	}
+	if update.StorageClass != "" && !warehouse.IsTemperatureClass(update.StorageClass) {
+		return common.NewValidationError("invalid product variant",
+			warehouse.ValidateTemperatureClass(update.StorageClass, "storageClass"),
+		)
+	}
 	return s.repo.UpdateProductVariantDetails(ctx, update)
 }
 
@@ -260,24 +267,32 @@ func (s *ProductService) UpdateProductVariantArchiveStatus(ctx context.Context,
 	return s.repo.UpdateProductVariantArchiveStatus(ctx, id, isArchive)
 }
 
-func (s *ProductService) SearchProductVariantByName(
+func (s *ProductService) SearchProductVariants(
 	ctx context.Context,
-	name string,
-) (common.PaginatedResponse[ProductVariant], error) {
+	filter ProductVariantSearchFilter,
+) (common.PaginatedResponse[ProductVariantSearchResult], error) {
 	paginationParams := common.GetPaginationParams(ctx)
-	productVariants, err := s.repo.SearchProductVariantsByName(ctx, paginationParams, name)
+	if buildPrefixQuery(filter.Term) == "" {
+		return common.CreateEmptyPaginatedResponse[ProductVariantSearchResult](paginationParams.PageSize),
+			common.NewBadRequestError("search term is required", "search_term_required")
+	}
+	if filter.InStock && warehouse.GetWarehouseId(ctx) == 0 {
+		return common.CreateEmptyPaginatedResponse[ProductVariantSearchResult](paginationParams.PageSize),
+			common.NewBadRequestError("warehouse is required to filter by stock", "warehouse_required")
+	}
+	results, err := s.repo.SearchProductVariants(ctx, paginationParams, filter)
 	if err != nil {
-		return common.CreateEmptyPaginatedResponse[ProductVariant](paginationParams.PageSize), err
+		return common.CreateEmptyPaginatedResponse[ProductVariantSearchResult](paginationParams.PageSize), err
 	}
-	if len(productVariants) == 0 {
-		return common.CreateEmptyPaginatedResponse[ProductVariant](paginationParams.PageSize), nil
+	if len(results) == 0 {
+		return common.CreateEmptyPaginatedResponse[ProductVariantSearchResult](paginationParams.PageSize), nil
 	}
-	first, last := productVariants[0], productVariants[len(productVariants)-1]
-	return common.CreatePaginatedResponse[ProductVariant](
+	first, last := results[0], results[len(results)-1]
+	return common.CreatePaginatedResponse[ProductVariantSearchResult](
 		paginationParams.PageSize,
 		last,
 		first,
-		productVariants,
+		results,
 	), nil
 }
 
@@ -2,11 +2,14 @@ package product
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 	"github.com/nayefradwi/zanobia_inventory_manager/unit"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 	zimutils "github.com/nayefradwi/zanobia_inventory_manager/zim_utils"
 	"go.uber.org/zap"
 )
@@ -48,7 +51,8 @@ func (r *ProductRepo) GetProductVariant(ctx context.Context, productVariantId in
 	select pvar.id, pvar.product_id, pvartx.name, pvar.sku, pvar.image, pvar.price,
 	pvar.width_in_cm, pvar.height_in_cm, pvar.depth_in_cm, pvar.weight_in_g,
 	pvar.is_archived, pvar.is_default, pvar.expires_in_days, 
-	utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient
+	utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient,
+	coalesce(pvar.barcode, ''), pvar.storage_class
 	from product_variants pvar 
 	join product_variant_translations pvartx on pvar.id = pvartx.product_variant_id
 	join unit_translations utx on utx.unit_id = pvar.standard_unit_id
@@ -70,7 +74,7 @@ func (r *ProductRepo) parseProductVariantRow(ctx context.Context, row pgx.Row) (
 		&productVariant.Image, &productVariant.Price, &productVariant.WidthInCm, &productVariant.HeightInCm,
 		&productVariant.DepthInCm, &productVariant.WeightInG, &productVariant.IsArchived, &productVariant.IsDefault,
 		&productVariant.ExpiresInDays, &unit.Id, &unit.Name, &unit.Symbol, &productVariant.ProductName,
-		&productVariant.IsIngredient,
+		&productVariant.IsIngredient, &productVariant.Barcode, &productVariant.StorageClass,
 	)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to scan product variant", zap.Error(err))
@@ -92,6 +96,63 @@ func (r *ProductRepo) AddProductVariant(ctx context.Context, input ProductVarian
 	return err
 }
 
+func (r *ProductRepo) AddGeneratedProductVariants(ctx context.Context, inputs []ProductVariantInput) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		for _, input := range inputs {
+			id, err := r.addProductVariant(ctx, input.ProductVariant.ProductId, input.ProductVariant)
+			if err != nil {
+				return err
+			}
+			input.ProductVariant.Id = &id
+			if err := r.addProductVariantValues(ctx, input.ProductVariant, input.OptionValues); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetProductVariantCombinations returns the default language name and option values of every variant
+// of the product, the default variant is created without option values so it only has a name
+func (r *ProductRepo) GetProductVariantCombinations(ctx context.Context, productId int) ([]VariantCombination, error) {
+	sql := `
+	select pvar.id, pvartx.name, pvv.product_option_value_id from product_variants pvar
+	join product_variant_translations pvartx on pvartx.product_variant_id = pvar.id and pvartx.language_code = $2
+	left join product_variant_values pvv on pvv.product_variant_id = pvar.id
+	where pvar.product_id = $1
+	order by pvar.id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, productId, common.DefaultLang)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get product variant combinations", zap.Error(err))
+		return []VariantCombination{}, common.NewBadRequestFromMessage("Failed to get product variant combinations")
+	}
+	defer rows.Close()
+	combinations := make([]VariantCombination, 0)
+	for rows.Next() {
+		var combination VariantCombination
+		var valueId *int
+		if err := rows.Scan(&combination.VariantId, &combination.Name, &valueId); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product variant combination", zap.Error(err))
+			return []VariantCombination{}, common.NewInternalServerError()
+		}
+		last := len(combinations) - 1
+		if last < 0 || combinations[last].VariantId != combination.VariantId {
+			combinations = append(combinations, combination)
+			last++
+		}
+		if valueId != nil {
+			combinations[last].OptionValueIds = append(combinations[last].OptionValueIds, *valueId)
+		}
+	}
+	return combinations, nil
+}
+
+func (r *ProductRepo) TranslateProductVariant(ctx context.Context, productVariant ProductVariant, languageCode string) error {
+	return r.insertProductVariantTranslation(ctx, productVariant.ProductId, productVariant, languageCode)
+}
+
 func (r *ProductRepo) GetUnitIdOfProductVariantBySku(ctx context.Context, sku string) (int, error) {
 	sql := `
 		select standard_unit_id from product_variants where sku = $1
@@ -178,12 +239,13 @@ func (r *ProductRepo) GetProductSelectedValues(ctx context.Context, productId in
 func (r *ProductRepo) UpdateProductVariantDetails(ctx context.Context, update ProductVariantUpdate) error {
 	sql := `
 	update product_variants set price = $1, width_in_cm = $2, height_in_cm = $3, depth_in_cm = $4,
-	weight_in_g = $5, is_archived = $6 where id = $7
+	weight_in_g = $5, is_archived = $6, barcode = NULLIF($7, ''),
+	storage_class = coalesce(NULLIF($9, ''), storage_class) where id = $8
 	`
 	op := common.GetOperator(ctx, r.Pool)
 	_, err := op.Exec(ctx, sql, update.Price, update.WidthInCm,
 		update.HeightInCm, update.DepthInCm, update.WeightInG,
-		update.IsArchived, update.Id,
+		update.IsArchived, update.Barcode, update.Id, update.StorageClass,
 	)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to update product variant details", zap.Error(err))
@@ -290,7 +352,8 @@ func (r *ProductRepo) GetProductVariantBySku(ctx context.Context, sku string) (P
 	select pvar.id, pvar.product_id, pvartx.name, pvar.sku, pvar.image, pvar.price,
 	pvar.width_in_cm, pvar.height_in_cm, pvar.depth_in_cm, pvar.weight_in_g,
 	pvar.is_archived, pvar.is_default, pvar.expires_in_days, 
-	utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient
+	utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient,
+	coalesce(pvar.barcode, ''), pvar.storage_class
 	from product_variants pvar 
 	join product_variant_translations pvartx on pvar.id = pvartx.product_variant_id
 	join unit_translations utx on utx.unit_id = pvar.standard_unit_id AND utx.language_code = pvartx.language_code
@@ -304,64 +367,84 @@ func (r *ProductRepo) GetProductVariantBySku(ctx context.Context, sku string) (P
 	return r.parseProductVariantRow(ctx, row)
 }
 
-func (r *ProductRepo) SearchProductVariantsByName(
+const searchProductVariantsSql = `
+select pvar.id, pvar.product_id, pvartx.name, pvar.sku, pvar.image, pvar.price,
+pvar.width_in_cm, pvar.height_in_cm, pvar.depth_in_cm, pvar.weight_in_g,
+pvar.is_archived, pvar.is_default, pvar.expires_in_days,
+utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient,
+coalesce(pvar.barcode, ''), pvar.storage_class, s.rank
+from search_product_variants($1, $2) s
+join product_variants pvar on pvar.id = s.product_variant_id
+join product_variant_translations pvartx on pvar.id = pvartx.product_variant_id
+join unit_translations utx on utx.unit_id = pvar.standard_unit_id AND utx.language_code = pvartx.language_code
+join product_translations ptx on ptx.product_id = pvar.product_id AND ptx.language_code = pvartx.language_code
+join products p on p.id = pvar.product_id
+`
+
+func (r *ProductRepo) SearchProductVariants(
 	ctx context.Context,
 	paginationParams common.PaginationParams,
-	name string,
-) ([]ProductVariant, error) {
-	searchTerm := "%" + name + "%"
+	filter ProductVariantSearchFilter,
+) ([]ProductVariantSearchResult, error) {
 	op := common.GetOperator(ctx, r.Pool)
-	lang := common.GetLanguageParam(ctx)
+	conditions := []string{"pvartx.language_code = $3"}
+	args := []interface{}{filter.Term, buildPrefixQuery(filter.Term), common.GetLanguageParam(ctx)}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, "and", strings.ReplaceAll(condition, "$n", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.CategoryId != 0 {
+		addCondition("p.category_id in ("+strings.ReplaceAll(categorySubtreeSql, "$1", "$n")+")", filter.CategoryId)
+	}
+	if filter.IsArchived != nil {
+		addCondition("pvar.is_archived = $n", *filter.IsArchived)
+	}
+	if filter.IsIngredient != nil {
+		addCondition("p.is_ingredient = $n", *filter.IsIngredient)
+	}
+	if filter.InStock {
+		addCondition(
+			"exists (select 1 from batches b where b.sku = pvar.sku and b.warehouse_id = $n and b.quantity > 0)",
+			warehouse.GetWarehouseId(ctx),
+		)
+	}
 	rows, err := common.NewPaginationQueryBuilder(
-		`
-	select pvar.id, pvar.product_id, pvartx.name, pvar.sku, pvar.image, pvar.price,
-	pvar.width_in_cm, pvar.height_in_cm, pvar.depth_in_cm, pvar.weight_in_g,
-	pvar.is_archived, pvar.is_default, pvar.expires_in_days, 
-	utx.unit_id, utx.name, utx.symbol, ptx.name product_name, p.is_ingredient
-	from product_variants pvar 
-	join product_variant_translations pvartx on pvar.id = pvartx.product_variant_id
-	join unit_translations utx on utx.unit_id = pvar.standard_unit_id AND utx.language_code = pvartx.language_code
-	join product_translations ptx on ptx.product_id = pvar.product_id AND ptx.language_code = pvartx.language_code
-	join products p on p.id = pvar.product_id
-	`,
-		[]string{"pvar.id DESC"},
+		searchProductVariantsSql,
+		[]string{"s.rank DESC", "pvar.id DESC"},
 	).
 		WithOperator(op).
-		WithConditions([]string{
-			"pvartx.language_code = $1",
-			"AND",
-			"(LOWER(pvartx.name) LIKE LOWER($2) OR LOWER(ptx.name) LIKE LOWER($2))",
-		}).
-		WithCursorKeys([]string{"pvar.id"}).
-		WithCompareSymbols(">", ">=", "<").
+		WithBaseArgCount(2).
+		WithConditions(conditions).
+		WithCursorKeys([]string{"s.rank", "pvar.id"}).
+		WithCompareSymbols("<", "<=", ">").
 		WithParams(paginationParams).
 		Build().
-		Query(ctx, lang, searchTerm)
+		Query(ctx, args...)
 
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to search product variants", zap.Error(err))
-		return []ProductVariant{}, common.NewBadRequestFromMessage("Failed to search product variants")
+		return []ProductVariantSearchResult{}, common.NewBadRequestFromMessage("Failed to search product variants")
 	}
 	defer rows.Close()
-	productVariants := make([]ProductVariant, 0)
+	results := make([]ProductVariantSearchResult, 0)
 	for rows.Next() {
-		var productVariant ProductVariant
+		var result ProductVariantSearchResult
 		var unit unit.Unit
 		err := rows.Scan(
-			&productVariant.Id, &productVariant.ProductId, &productVariant.Name, &productVariant.Sku,
-			&productVariant.Image, &productVariant.Price, &productVariant.WidthInCm, &productVariant.HeightInCm,
-			&productVariant.DepthInCm, &productVariant.WeightInG, &productVariant.IsArchived, &productVariant.IsDefault,
-			&productVariant.ExpiresInDays, &unit.Id, &unit.Name, &unit.Symbol, &productVariant.ProductName,
-			&productVariant.IsIngredient,
+			&result.Id, &result.ProductId, &result.Name, &result.Sku,
+			&result.Image, &result.Price, &result.WidthInCm, &result.HeightInCm,
+			&result.DepthInCm, &result.WeightInG, &result.IsArchived, &result.IsDefault,
+			&result.ExpiresInDays, &unit.Id, &unit.Name, &unit.Symbol, &result.ProductName,
+			&result.IsIngredient, &result.Barcode, &result.StorageClass, &result.Rank,
 		)
 		if err != nil {
 			common.LoggerFromCtx(ctx).Error("failed to scan product variant", zap.Error(err))
 			return nil, common.NewBadRequestError("failed to get product variant", zimutils.GetErrorCodeFromError(err))
 		}
-		productVariant.StandardUnit = &unit
-		productVariants = append(productVariants, productVariant)
+		result.StandardUnit = &unit
+		results = append(results, result)
 	}
-	return productVariants, nil
+	return results, nil
 }
 
 func (r *ProductRepo) AddProductOption(ctx context.Context, input ProductOptionInput) error {
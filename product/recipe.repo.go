@@ -16,6 +16,7 @@ type IRecipeRepository interface {
 	DeleteRecipe(ctx context.Context, id int) error
 	GetRecipeOfProductVariantSku(ctx context.Context, sku string) ([]Recipe, error)
 	GetRecipesLookUpMapFromSkus(ctx context.Context, skuList []string) (map[string]Recipe, []string, error)
+	GetIngredientStandardUnits(ctx context.Context, skuList []string) (map[string]int, error)
 }
 type RecipeRepository struct {
 	*pgxpool.Pool
@@ -164,3 +165,25 @@ func (r *RecipeRepository) GetRecipesLookUpMapFromSkus(ctx context.Context, skuL
 	return recipeMap, recipeSkuList, nil
 
 }
+
+func (r *RecipeRepository) GetIngredientStandardUnits(ctx context.Context, skuList []string) (map[string]int, error) {
+	sql := `SELECT sku, standard_unit_id FROM product_variants WHERE sku = ANY($1)`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, skuList)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get ingredient standard units", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get ingredient standard units")
+	}
+	defer rows.Close()
+	units := make(map[string]int)
+	for rows.Next() {
+		var sku string
+		var unitId int
+		if err := rows.Scan(&sku, &unitId); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan ingredient standard unit", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		units[sku] = unitId
+	}
+	return units, nil
+}
@@ -36,6 +36,9 @@ func (s *RecipeService) CreateRecipes(ctx context.Context, recipes []RecipeBase)
 	if len(recipes) == 0 {
 		return common.NewBadRequestFromMessage("cannot create empty recipes")
 	}
+	if err := s.validateRecipeUnits(ctx, recipes); err != nil {
+		return err
+	}
 	return s.repo.CreateRecipes(ctx, recipes)
 }
 
@@ -43,9 +46,34 @@ func (s *RecipeService) AddIngredientToRecipe(ctx context.Context, recipe Recipe
 	if err := ValidateRecipe(recipe); err != nil {
 		return err
 	}
+	if err := s.validateRecipeUnits(ctx, []RecipeBase{recipe}); err != nil {
+		return err
+	}
 	return s.repo.AddIngredientToRecipe(ctx, recipe)
 }
 
+func (s *RecipeService) validateRecipeUnits(ctx context.Context, recipes []RecipeBase) error {
+	skuList := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		skuList = append(skuList, recipe.RecipeVariantSku)
+	}
+	standardUnits, err := s.repo.GetIngredientStandardUnits(ctx, skuList)
+	if err != nil {
+		return err
+	}
+	for _, recipe := range recipes {
+		standardUnitId, ok := standardUnits[recipe.RecipeVariantSku]
+		if !ok {
+			return common.NewNotFoundError("ingredient " + recipe.RecipeVariantSku + " not found")
+		}
+		err := s.unitService.ValidateConvertible(ctx, recipe.RecipeVariantSku, standardUnitId, *recipe.UnitId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *RecipeService) DeleteRecipe(ctx context.Context, id int) error {
 	return s.repo.DeleteRecipe(ctx, id)
 }
@@ -76,6 +104,7 @@ func (s *RecipeService) getCostOfRecipe(ctx context.Context, recipe Recipe) (flo
 		FromUnitId: recipe.Unit.Id,
 		ToUnitId:   recipe.IngredientStandardUnit.Id,
 		Quantity:   recipe.Quantity,
+		Sku:        recipe.RecipeVariantSku,
 	})
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to convert unit", zap.Error(err))
@@ -0,0 +1,41 @@
+package product
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ProductVariantSearchFilter narrows a search, nil flags and a 0 category match everything
+type ProductVariantSearchFilter struct {
+	Term         string
+	CategoryId   int
+	IsArchived   *bool
+	IsIngredient *bool
+	// InStock only matches variants with a positive batch quantity in the current warehouse
+	InStock bool
+}
+
+type ProductVariantSearchResult struct {
+	ProductVariant
+	Rank float64 `json:"rank"`
+}
+
+func (r ProductVariantSearchResult) GetCursorValue() []string {
+	return []string{
+		strconv.FormatFloat(r.Rank, 'f', -1, 64),
+		strconv.Itoa(*r.Id),
+	}
+}
+
+// buildPrefixQuery turns a free text term into a to_tsquery expression where every word
+// is matched as a prefix, characters that have a meaning in tsquery syntax are dropped
+func buildPrefixQuery(term string) string {
+	words := strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,21 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPrefixQuery(t *testing.T) {
+	assert.Equal(t, "choc:* & milk:*", buildPrefixQuery("  choc milk "))
+	assert.Equal(t, "a:* & b:*", buildPrefixQuery("a & !b:*"))
+	assert.Equal(t, "حليب:* & كامل:*", buildPrefixQuery("حليب - كامل"))
+	assert.Equal(t, "", buildPrefixQuery(" ()|& "))
+}
+
+func TestProductVariantSearchResult_GetCursorValue(t *testing.T) {
+	id := 7
+	result := ProductVariantSearchResult{Rank: 1.254321}
+	result.Id = &id
+	assert.Equal(t, []string{"1.254321", "7"}, result.GetCursorValue())
+}
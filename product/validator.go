@@ -2,6 +2,7 @@ package product
 
 import (
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 )
 
 func ValidateProduct(product ProductInput) error {
@@ -12,6 +13,7 @@ func ValidateProduct(product ProductInput) error {
 		common.ValidateNotZero(product.Price, "price"),
 		common.ValidateIdPtr(product.StandardUnitId, "standardUnitId"),
 		common.ValidateNotZero(product.ExpiresInDays, "expiresInDays"),
+		warehouse.ValidateTemperatureClass(product.StorageClass, "storageClass"),
 		validateProductOptions(product.Options),
 	)
 	errors := make([]common.ErrorDetails, 0)
@@ -25,6 +27,25 @@ func ValidateProduct(product ProductInput) error {
 	}
 	return nil
 }
+func ValidateCategory(input CategoryInput) error {
+	validationResults := []common.ErrorDetails{
+		common.ValidateAlphanuemericName(input.Name, "name"),
+	}
+	if input.ParentId != nil {
+		validationResults = append(validationResults, common.ValidateIdPtr(input.ParentId, "parentId"))
+	}
+	errors := make([]common.ErrorDetails, 0)
+	for _, result := range validationResults {
+		if len(result.Message) > 0 {
+			errors = append(errors, result)
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid category input", errors...)
+	}
+	return nil
+}
+
 func validateProductOptions(options []ProductOption) common.ErrorDetails {
 	if len(options) == 0 {
 		return common.ErrorDetails{}
@@ -99,6 +120,10 @@ func ValidateRecipe(recipe RecipeBase) error {
 	if len(qtyValidation.Message) > 0 {
 		return common.NewValidationError("invalid recipe input", qtyValidation)
 	}
+	unitValidation := common.ValidateIdPtr(recipe.UnitId, "unitId")
+	if len(unitValidation.Message) > 0 {
+		return common.NewValidationError("invalid recipe input", unitValidation)
+	}
 	return nil
 }
 
@@ -119,6 +144,7 @@ func ValidateProductVariant(input ProductVariantInput, min, max int) error {
 		common.ValidateIdPtr(input.ProductVariant.StandardUnitId, "standardUnitId"),
 		common.ValidateIdPtr(input.ProductVariant.ProductId, "productId"),
 		common.ValidateNotZero(input.ProductVariant.ExpiresInDays, "expiresInDays"),
+		warehouse.ValidateTemperatureClass(input.ProductVariant.StorageClass, "storageClass"),
 		ValidateProductVariantSelectedValues(input.OptionValueIds, min, max),
 	)
 	errors := make([]common.ErrorDetails, 0)
@@ -133,6 +159,23 @@ func ValidateProductVariant(input ProductVariantInput, min, max int) error {
 	return nil
 }
 
+func ValidateVariantGenerationInput(input VariantGenerationInput) error {
+	details := []common.ErrorDetails{common.ValidateId(input.ProductId, "productId")}
+	for _, adjustment := range input.Adjustments {
+		details = append(details, common.ValidateId(adjustment.OptionValueId, "adjustments"))
+	}
+	errors := make([]common.ErrorDetails, 0)
+	for _, detail := range details {
+		if len(detail.Message) > 0 {
+			errors = append(errors, detail)
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid variant generation input", errors...)
+	}
+	return nil
+}
+
 func ValidateProductVariantSelectedValues(valueIds []int, min, max int) common.ErrorDetails {
 	if details := common.ValidateSliceSize[int](valueIds, "variantValues", min, max); details.Message != "" {
 		return details
@@ -177,3 +220,26 @@ func ValidateProductOptionInput(input ProductOptionInput) error {
 	}
 	return nil
 }
+
+func validateCatalogueTranslation(languageCode, name, description string) error {
+	details := make([]common.ErrorDetails, 0)
+	for _, detail := range []common.ErrorDetails{
+		common.ValidateStringLength(languageCode, "language_code", 2, 2),
+		common.ValidateAlphanuemericName(name, "name"),
+		common.ValidateStringLength(description, "description", 0, 255),
+	} {
+		if len(detail.Message) > 0 {
+			details = append(details, detail)
+		}
+	}
+	if languageCode == common.DefaultLang {
+		details = append(details, common.ErrorDetails{
+			Message: "default language names are set by the products and variants sheets",
+			Field:   "language_code",
+		})
+	}
+	if len(details) > 0 {
+		return common.NewValidationError("invalid translation", details...)
+	}
+	return nil
+}
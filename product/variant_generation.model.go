@@ -0,0 +1,149 @@
+package product
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxGeneratedVariants = 500
+
+// OptionValueAdjustment is added on top of the default variant for every generated
+// variant that has the option value, e.g. size=large adds 2.00 to the price
+type OptionValueAdjustment struct {
+	OptionValueId int     `json:"optionValueId"`
+	Price         float64 `json:"price,omitempty"`
+	WidthInCm     float64 `json:"widthInCm,omitempty"`
+	HeightInCm    float64 `json:"heightInCm,omitempty"`
+	DepthInCm     float64 `json:"depthInCm,omitempty"`
+	WeightInG     float64 `json:"weightInG,omitempty"`
+}
+
+type VariantGenerationInput struct {
+	ProductId   int                     `json:"productId"`
+	Adjustments []OptionValueAdjustment `json:"adjustments,omitempty"`
+	// DryRun previews the variants that would be created without creating them
+	DryRun bool `json:"dryRun"`
+}
+
+type VariantCombination struct {
+	VariantId      int
+	Name           string
+	OptionValueIds []int
+}
+
+type VariantGenerationResult struct {
+	DryRun        bool             `json:"dryRun"`
+	ExistingCount int              `json:"existingCount"`
+	Variants      []ProductVariant `json:"variants"`
+}
+
+// generateOptionValueCombinations returns the cartesian product of the option values,
+// options are walked in name order so the result is stable
+func generateOptionValueCombinations(options map[string]ProductOption) []OptionValueSet {
+	if len(options) == 0 {
+		return []OptionValueSet{}
+	}
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	combinations := []OptionValueSet{{}}
+	for _, name := range names {
+		next := make([]OptionValueSet, 0, len(combinations)*len(options[name].Values))
+		for _, combination := range combinations {
+			for _, value := range options[name].Values {
+				set := make(OptionValueSet, len(combination), len(combination)+1)
+				copy(set, combination)
+				next = append(next, append(set, value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func getOptionValueIdsKey(ids []int) string {
+	sorted := make([]int, len(ids))
+	copy(sorted, ids)
+	sort.Ints(sorted)
+	keys := make([]string, len(sorted))
+	for i, id := range sorted {
+		keys[i] = strconv.Itoa(id)
+	}
+	return strings.Join(keys, ",")
+}
+
+func (set OptionValueSet) getIds() []int {
+	ids := make([]int, len(set))
+	for i, value := range set {
+		ids[i] = *value.Id
+	}
+	return ids
+}
+
+func (set OptionValueSet) getName() string {
+	values := make([]ProductOptionValue, len(set))
+	copy(values, set)
+	return GenerateName(values)
+}
+
+func countGeneratedCombinations(options map[string]ProductOption) int {
+	count := 1
+	for _, option := range options {
+		count *= len(option.Values)
+		if count > maxGeneratedVariants {
+			return count
+		}
+	}
+	return count
+}
+
+// applyAdjustments builds a variant for the option values from the default variant
+func applyAdjustments(
+	defaultVariant ProductVariant,
+	set OptionValueSet,
+	adjustments map[int]OptionValueAdjustment,
+) ProductVariant {
+	variant := ProductVariant{
+		ProductVariantBase: ProductVariantBase{
+			ProductId:      defaultVariant.ProductId,
+			Name:           set.getName(),
+			Image:          defaultVariant.Image,
+			Price:          defaultVariant.Price,
+			WidthInCm:      defaultVariant.WidthInCm,
+			HeightInCm:     defaultVariant.HeightInCm,
+			DepthInCm:      defaultVariant.DepthInCm,
+			WeightInG:      defaultVariant.WeightInG,
+			StandardUnitId: defaultVariant.StandardUnitId,
+			ExpiresInDays:  defaultVariant.ExpiresInDays,
+			StorageClass:   defaultVariant.StorageClass,
+		},
+		ProductName:  defaultVariant.ProductName,
+		IsIngredient: defaultVariant.IsIngredient,
+	}
+	for _, value := range set {
+		adjustment, ok := adjustments[*value.Id]
+		if !ok {
+			continue
+		}
+		variant.Price += adjustment.Price
+		variant.WidthInCm = adjustDimension(variant.WidthInCm, adjustment.WidthInCm)
+		variant.HeightInCm = adjustDimension(variant.HeightInCm, adjustment.HeightInCm)
+		variant.DepthInCm = adjustDimension(variant.DepthInCm, adjustment.DepthInCm)
+		variant.WeightInG = adjustDimension(variant.WeightInG, adjustment.WeightInG)
+	}
+	return variant
+}
+
+func adjustDimension(value *float64, delta float64) *float64 {
+	if delta == 0 {
+		return value
+	}
+	adjusted := delta
+	if value != nil {
+		adjusted += *value
+	}
+	return &adjusted
+}
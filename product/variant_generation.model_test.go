@@ -0,0 +1,67 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOption(name string, firstId int, values ...string) ProductOption {
+	option := ProductOption{Name: name}
+	for i, value := range values {
+		id := firstId + i
+		option.Values = append(option.Values, ProductOptionValue{Id: &id, Value: value})
+	}
+	return option
+}
+
+func TestGenerateOptionValueCombinations(t *testing.T) {
+	combinations := generateOptionValueCombinations(map[string]ProductOption{
+		"size":   newTestOption("size", 1, "small", "large"),
+		"flavor": newTestOption("flavor", 3, "vanilla", "chocolate", "mango"),
+	})
+	assert.Len(t, combinations, 6)
+	assert.Equal(t, []int{3, 1}, combinations[0].getIds())
+	assert.Equal(t, []int{5, 2}, combinations[5].getIds())
+	keys := make(map[string]bool)
+	for _, combination := range combinations {
+		keys[getOptionValueIdsKey(combination.getIds())] = true
+	}
+	assert.Len(t, keys, 6)
+	assert.Empty(t, generateOptionValueCombinations(map[string]ProductOption{}))
+}
+
+func TestOptionValueSetGetNameDoesNotReorderSet(t *testing.T) {
+	set := generateOptionValueCombinations(map[string]ProductOption{
+		"size":   newTestOption("size", 1, "small"),
+		"flavor": newTestOption("flavor", 2, "vanilla"),
+	})[0]
+	assert.Equal(t, GenerateName([]ProductOptionValue{{Value: "vanilla"}, {Value: "small"}}), set.getName())
+	assert.Equal(t, "vanilla", set[0].Value)
+}
+
+func TestApplyAdjustments(t *testing.T) {
+	productId, unitId, width := 1, 2, 10.0
+	defaultVariant := ProductVariant{ProductVariantBase: ProductVariantBase{
+		ProductId: &productId, Price: 5, StandardUnitId: &unitId, WidthInCm: &width, ExpiresInDays: 3,
+	}}
+	set := generateOptionValueCombinations(map[string]ProductOption{
+		"size":   newTestOption("size", 1, "large"),
+		"flavor": newTestOption("flavor", 2, "mango"),
+	})[0]
+	variant := applyAdjustments(defaultVariant, set, map[int]OptionValueAdjustment{
+		1: {OptionValueId: 1, Price: 2, WidthInCm: 5, WeightInG: 100},
+		2: {OptionValueId: 2, Price: 0.5},
+	})
+	assert.Equal(t, 7.5, variant.Price)
+	assert.Equal(t, 15.0, *variant.WidthInCm)
+	assert.Equal(t, 100.0, *variant.WeightInG)
+	assert.Nil(t, variant.HeightInCm)
+	assert.Equal(t, 10.0, width)
+	assert.Equal(t, 3, variant.ExpiresInDays)
+	assert.False(t, variant.IsDefault)
+}
+
+func TestGetOptionValueIdsKeyIgnoresOrder(t *testing.T) {
+	assert.Equal(t, getOptionValueIdsKey([]int{3, 1, 2}), getOptionValueIdsKey([]int{2, 3, 1}))
+}
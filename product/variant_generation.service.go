@@ -0,0 +1,112 @@
+package product
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+func (s *ProductService) GenerateProductVariants(ctx context.Context, input VariantGenerationInput) (VariantGenerationResult, error) {
+	if err := ValidateVariantGenerationInput(input); err != nil {
+		return VariantGenerationResult{}, err
+	}
+	product, err := s.repo.GetProduct(ctx, input.ProductId)
+	if err != nil {
+		return VariantGenerationResult{}, err
+	}
+	if product.Id == nil {
+		return VariantGenerationResult{}, common.NewNotFoundError("product not found")
+	}
+	if len(product.Options) == 0 {
+		return VariantGenerationResult{}, common.NewBadRequestFromMessage("product has no options")
+	}
+	if countGeneratedCombinations(product.Options) > maxGeneratedVariants {
+		return VariantGenerationResult{}, common.NewBadRequestError(
+			"product options produce too many variants to generate", "too_many_variants",
+		)
+	}
+	adjustments, err := getAdjustmentsByOptionValue(product.Options, input.Adjustments)
+	if err != nil {
+		return VariantGenerationResult{}, err
+	}
+	defaultVariant, err := s.getDefaultProductVariant(ctx, *product.Id)
+	if err != nil {
+		return VariantGenerationResult{}, err
+	}
+	existing, err := s.repo.GetProductVariantCombinations(ctx, *product.Id)
+	if err != nil {
+		return VariantGenerationResult{}, err
+	}
+	existingKeys, existingNames := make(map[string]bool), make(map[string]bool)
+	for _, combination := range existing {
+		existingNames[combination.Name] = true
+		if len(combination.OptionValueIds) > 0 {
+			existingKeys[getOptionValueIdsKey(combination.OptionValueIds)] = true
+		}
+	}
+	inputs := make([]ProductVariantInput, 0)
+	for _, set := range generateOptionValueCombinations(product.Options) {
+		if existingKeys[getOptionValueIdsKey(set.getIds())] || existingNames[set.getName()] {
+			continue
+		}
+		variant := applyAdjustments(defaultVariant, set, adjustments)
+		if variant.Price < 0 {
+			return VariantGenerationResult{}, common.NewValidationError("invalid variant adjustments", common.ErrorDetails{
+				Message: "price of " + variant.Name + " cannot be negative",
+				Field:   "adjustments",
+			})
+		}
+		if !input.DryRun {
+			variant.Sku, _ = common.GenerateUuid()
+		}
+		inputs = append(inputs, ProductVariantInput{ProductVariant: variant, OptionValues: set})
+	}
+	result := VariantGenerationResult{
+		DryRun:        input.DryRun,
+		ExistingCount: len(existing),
+		Variants: common.Map[ProductVariantInput, ProductVariant](inputs, func(input ProductVariantInput) ProductVariant {
+			return input.ProductVariant
+		}),
+	}
+	if input.DryRun || len(inputs) == 0 {
+		return result, nil
+	}
+	return result, s.repo.AddGeneratedProductVariants(ctx, inputs)
+}
+
+func (s *ProductService) getDefaultProductVariant(ctx context.Context, productId int) (ProductVariant, error) {
+	variants, err := s.repo.GetProductVariantsOfProduct(ctx, productId)
+	if err != nil {
+		return ProductVariant{}, err
+	}
+	defaultVariant := common.FirstWhere[ProductVariant](variants, func(variant ProductVariant) bool {
+		return variant.IsDefault
+	})
+	if defaultVariant == nil || defaultVariant.Id == nil {
+		return ProductVariant{}, common.NewBadRequestFromMessage("product has no default variant")
+	}
+	return s.repo.GetProductVariant(ctx, *defaultVariant.Id)
+}
+
+func getAdjustmentsByOptionValue(
+	options map[string]ProductOption,
+	adjustments []OptionValueAdjustment,
+) (map[int]OptionValueAdjustment, error) {
+	valueIds := make(map[int]bool)
+	for _, option := range options {
+		for _, value := range option.Values {
+			valueIds[*value.Id] = true
+		}
+	}
+	adjustmentsByValue := make(map[int]OptionValueAdjustment, len(adjustments))
+	for _, adjustment := range adjustments {
+		if !valueIds[adjustment.OptionValueId] {
+			return nil, common.NewValidationError("invalid variant adjustments", common.ErrorDetails{
+				Message: "option value does not belong to the product",
+				Field:   "adjustments",
+			})
+		}
+		adjustmentsByValue[adjustment.OptionValueId] = adjustment
+	}
+	return adjustmentsByValue, nil
+}
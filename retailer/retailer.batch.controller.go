@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
 )
 
 type RetailerBatchController struct {
@@ -38,6 +39,28 @@ func (c RetailerBatchController) DecrementBatch(w http.ResponseWriter, r *http.R
 	})
 }
 
+func (c RetailerBatchController) SetBatchQuality(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[product.BatchQualityInput](w, r.Body, func(input product.BatchQualityInput) {
+		err := c.service.SetBatchQuality(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch quality status changed successfully",
+		})
+	})
+}
+
+func (c RetailerBatchController) ReleaseBatch(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[product.BatchReleaseInput](w, r.Body, func(input product.BatchReleaseInput) {
+		err := c.service.ReleaseBatch(r.Context(), input)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Batch released successfully",
+		})
+	})
+}
+
 func (c RetailerBatchController) BulkIncrementBatch(w http.ResponseWriter, r *http.Request) {
 	common.ParseBody[[]RetailerBatchInput](w, r.Body, func(inputs []RetailerBatchInput) {
 		err := c.service.BulkIncrementBatch(r.Context(), inputs)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
 	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
 	"go.uber.org/zap"
 )
@@ -17,6 +18,9 @@ func (s *RetailerBatchService) BulkDecrementBatch(ctx context.Context, inputs []
 	if err := ValidateBatchInputsDecrement(inputs); err != nil {
 		return err
 	}
+	if err := authorizeBatchInputs(ctx, inputs); err != nil {
+		return err
+	}
 	bulkBatchUpdateInfo, err := s.repo.GetBulkBatchUpdateInfo(ctx, inputs)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to process batch decrement", zap.Error(err))
@@ -43,6 +47,7 @@ func (s *RetailerBatchService) processBulkBatchDecrement(
 	bulkBatchUpdateUnitOfWork := BulkRetailerBatchUpdateUnitOfWork{
 		BatchUpdateRequestLookup: batchUpdateRequestLookup,
 		BatchTransactionHistory:  transactionHistory,
+		FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
 	}
 	return s.processBulkBatchUnitOfWork(ctx, bulkBatchUpdateUnitOfWork)
 }
@@ -62,6 +67,9 @@ func (s *RetailerBatchService) createDecrementBatchesUpdateRequest(
 		if !ok {
 			return nil, nil, common.NewBadRequestFromMessage("batch to update not found")
 		}
+		if !product.CanDecrementBatch(batchBase.Quality, batchInput.Reason) {
+			return nil, nil, product.GetBatchNotAvailableError(batchBase.Quality)
+		}
 		batchVariantMetaInfo, ok := bulkUpdateBatchInfo.BatchVariantMetaInfoLookup[batchInput.Sku]
 		if !ok {
 			return nil, nil, common.NewBadRequestFromMessage("variant meta info not found")
@@ -82,6 +90,7 @@ func (s *RetailerBatchService) createDecrementBatchesUpdateRequest(
 			Reason:     convertedBatchInput.Reason,
 			Sku:        convertedBatchInput.Sku,
 			ModifiedBy: convertedBatchInput.Quantity,
+			Version:    batchBase.Version,
 		}
 		transactionCommand := transactions.CreateRetailerTransactionCommand{
 			RetailerBatchId: *batchBase.Id,
@@ -52,7 +52,7 @@ func (r *RetailerBatchRepository) extractBatchInfoFromInputs(inputs []RetailerBa
 	batchToCreateLookup = make(map[string]RetailerBatchInput)
 	for _, input := range inputs {
 		if input.Id == nil {
-			batchToCreateLookup[input.Sku] = input
+			batchToCreateLookup[getRetailerBatchCreateKey(input)] = input
 		} else {
 			ids = append(ids, *input.Id)
 			batchToUpdateLookup[input.Sku] = input
@@ -77,7 +77,9 @@ func (r *RetailerBatchRepository) getBatchesBasedOnSkuListAndIds(
 		batches.retailer_id as retailer_id,
 		batches.sku as batch_sku,
 		batches.quantity as batch_qty,
-		batches.unit_id as batch_unit_id
+		batches.unit_id as batch_unit_id,
+		batches.version as batch_version,
+		batches.quality_status as batch_quality_status
 	from
 		retailer_batches as batches
 	where
@@ -132,8 +134,10 @@ func (r *RetailerBatchRepository) parseBatchBasesLookupFromResults(
 		var batchSku *string
 		var batchQty *float64
 		var batchUnitId *int
+		var batchVersion *int
+		var batchQualityStatus *string
 		err := rows.Scan(
-			&batchId, &RetailerId, &batchSku, &batchQty, &batchUnitId,
+			&batchId, &RetailerId, &batchSku, &batchQty, &batchUnitId, &batchVersion, &batchQualityStatus,
 		)
 		if err != nil {
 			common.GetLogger().Error("Failed to scan batch bases", zap.Error(err))
@@ -143,6 +147,7 @@ func (r *RetailerBatchRepository) parseBatchBasesLookupFromResults(
 			batchSku != nil &&
 			batchQty != nil &&
 			batchUnitId != nil &&
+			batchVersion != nil &&
 			RetailerId != nil {
 			batch := RetailerBatchBase{
 				Id:         batchId,
@@ -150,6 +155,10 @@ func (r *RetailerBatchRepository) parseBatchBasesLookupFromResults(
 				Sku:        *batchSku,
 				Quantity:   *batchQty,
 				UnitId:     *batchUnitId,
+				Version:    *batchVersion,
+			}
+			if batchQualityStatus != nil {
+				batch.Quality.Status = *batchQualityStatus
 			}
 			batchBasesLookup[batch.Sku] = batch
 		}
@@ -205,32 +214,57 @@ func (r *RetailerBatchRepository) processBulkBatchUnitOfWork(
 	// create update sql batches
 	// create create sql batches
 	op := common.GetOperator(ctx, r.Pool)
+	fencingToken := bulkBatchUpdateUnitOfWork.FencingToken
+	updatesStart := transactionsBatch.Len()
 	for _, batchUpdateRequest := range bulkBatchUpdateUnitOfWork.BatchUpdateRequestLookup {
 		transactionsBatch.Queue(
-			"UPDATE retailer_batches SET quantity = $1 WHERE id = $2 and retailer_id = $3",
+			`UPDATE retailer_batches SET quantity = $1, fencing_token = $4, version = version + 1
+			WHERE id = $2 and retailer_id = $3 and fencing_token <= $4 and version = $5`,
 			batchUpdateRequest.NewValue,
 			batchUpdateRequest.BatchId,
 			batchUpdateRequest.RetailerId,
+			fencingToken,
+			batchUpdateRequest.Version,
 		)
 	}
+	// received goods are added to the batch of the same sku and expiry date when the retailer has an available one
+	createsStart := transactionsBatch.Len()
 	for _, batchCreateRequest := range bulkBatchUpdateUnitOfWork.BatchCreateRequestLookup {
 		transactionsBatch.Queue(
-			"INSERT INTO retailer_batches (sku, retailer_id, quantity, unit_id, expires_at) VALUES ($1, $2, $3, $4, $5)",
+			`INSERT INTO retailer_batches (sku, retailer_id, quantity, unit_id, expires_at, manufactured_at, fencing_token)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (sku, retailer_id, expires_at) DO UPDATE SET
+				quantity = retailer_batches.quantity + EXCLUDED.quantity,
+				fencing_token = EXCLUDED.fencing_token,
+				version = retailer_batches.version + 1,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE retailer_batches.fencing_token <= EXCLUDED.fencing_token AND retailer_batches.quality_status = 'available'`,
 			batchCreateRequest.BatchSku,
 			batchCreateRequest.RetailerId,
 			batchCreateRequest.Quantity,
 			batchCreateRequest.UnitId,
 			common.GetUtcDateOnlyStringFromTime(batchCreateRequest.ExpiryDate),
+			batchCreateRequest.ManufacturedAt,
+			fencingToken,
 		)
 	}
+	createsEnd := transactionsBatch.Len()
 	results := op.SendBatch(ctx, transactionsBatch)
 	defer results.Close()
 	for i := 0; i < transactionsBatch.Len(); i++ {
-		_, err := results.Exec()
+		tag, err := results.Exec()
 		if err != nil {
 			common.LoggerFromCtx(ctx).Error("Failed to process bulk batch unit of work", zap.Error(err))
 			return common.NewBadRequestFromMessage("Failed to process bulk batch unit of work")
 		}
+		// goods are never added to a held batch, it has to be released first
+		if i >= createsStart && i < createsEnd && tag.RowsAffected() == 0 {
+			return common.NewConflictError("A retailer batch with the same expiry date is not available to receive goods")
+		}
+		if i >= updatesStart && tag.RowsAffected() == 0 {
+			common.LoggerFromCtx(ctx).Warn("retailer batch update rejected by version or fencing token", zap.Int64("fencing_token", fencingToken))
+			return common.NewConflictError("Batch was modified by another request, please try again")
+		}
 	}
 	return nil
 }
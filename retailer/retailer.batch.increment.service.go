@@ -6,6 +6,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
 	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
 )
 
@@ -17,6 +18,9 @@ func (s *RetailerBatchService) BulkIncrementBatch(ctx context.Context, inputs []
 	if err := ValidateBatchInputsIncrement(inputs); err != nil {
 		return err
 	}
+	if err := authorizeBatchInputs(ctx, inputs); err != nil {
+		return err
+	}
 	bulkBatchUpdateInfo, err := s.repo.GetBulkBatchUpdateInfo(ctx, inputs)
 	if err != nil {
 		return common.NewBadRequestFromMessage("failed to process batch increment")
@@ -48,6 +52,7 @@ func (s *RetailerBatchService) processBulkBatchIncrement(
 		BatchUpdateRequestLookup: batchUpdateRequestLookup,
 		BatchCreateRequestLookup: batchCreateRequestLookup,
 		BatchTransactionHistory:  transactionHistory,
+		FencingToken:             common.GetFencingToken(bulkBatchUpdateInfo.locks),
 	}
 	return s.processBulkBatchUnitOfWork(ctx, bulkBatchUpdateUnitOfWork)
 }
@@ -84,6 +89,7 @@ func (s *RetailerBatchService) createIncrementBatchesUpdateRequest(
 			Reason:     convertedBatchInput.Reason,
 			Sku:        convertedBatchInput.Sku,
 			ModifiedBy: convertedBatchInput.Quantity,
+			Version:    batchBase.Version,
 		}
 		transactionCommand := transactions.CreateRetailerTransactionCommand{
 			RetailerBatchId: *batchBase.Id,
@@ -119,14 +125,24 @@ func (s *RetailerBatchService) createBatchCreateRequest(
 		if err != nil {
 			return nil, nil, err
 		}
+		expiryDate, err := product.GetBatchExpiryDate(
+			batchInput.ExpiresAt,
+			batchInput.ManufacturedAt,
+			batchVariantMetaInfo.ExpiresInDays,
+			time.Now(),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
 		totalCost := batchVariantMetaInfo.Cost * convertedBatchInput.Quantity
-		expiryDate := time.Now().AddDate(0, 0, batchVariantMetaInfo.ExpiresInDays)
-		batchCreateRequestLookup[convertedBatchInput.Sku] = RetailerBatchCreateRequest{
-			BatchSku:   convertedBatchInput.Sku,
-			RetailerId: *convertedBatchInput.RetailerId,
-			Quantity:   convertedBatchInput.Quantity,
-			UnitId:     batchVariantMetaInfo.UnitId,
-			ExpiryDate: expiryDate,
+		createKey := getRetailerBatchCreateKey(convertedBatchInput)
+		batchCreateRequestLookup[createKey] = RetailerBatchCreateRequest{
+			BatchSku:       convertedBatchInput.Sku,
+			RetailerId:     *convertedBatchInput.RetailerId,
+			Quantity:       convertedBatchInput.Quantity,
+			UnitId:         batchVariantMetaInfo.UnitId,
+			ExpiryDate:     expiryDate,
+			ManufacturedAt: product.GetBatchManufactureDate(batchInput.ManufacturedAt),
 		}
 		transactionCommand := transactions.CreateRetailerTransactionCommand{
 			RetailerId: *convertedBatchInput.RetailerId,
@@ -10,13 +10,15 @@ import (
 )
 
 type RetailerBatchInput struct {
-	Id         *int    `json:"id,omitempty"`
-	RetailerId *int    `json:"retailerId,omitempty"`
-	Sku        string  `json:"Sku,omitempty"`
-	Quantity   float64 `json:"quantity"`
-	UnitId     int     `json:"unitId"`
-	Reason     string  `json:"reason,omitempty"`
-	Comment    string  `json:"comment,omitempty"`
+	Id             *int       `json:"id,omitempty"`
+	RetailerId     *int       `json:"retailerId,omitempty"`
+	Sku            string     `json:"Sku,omitempty"`
+	Quantity       float64    `json:"quantity"`
+	UnitId         int        `json:"unitId"`
+	Reason         string     `json:"reason,omitempty"`
+	Comment        string     `json:"comment,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	ManufacturedAt *time.Time `json:"manufacturedAt,omitempty"`
 }
 
 type RetailerBatchFromWarehouseInput struct {
@@ -30,12 +32,15 @@ type RetailerBatchFromWarehouseInput struct {
 }
 
 type RetailerBatchBase struct {
-	Id         *int      `json:"id,omitempty"`
-	RetailerId *int      `json:"retailerId,omitempty"`
-	Sku        string    `json:"sku"`
-	Quantity   float64   `json:"quantity"`
-	UnitId     int       `json:"unitId,omitempty"`
-	ExpiresAt  time.Time `json:"expiresAt"`
+	Id             *int                 `json:"id,omitempty"`
+	RetailerId     *int                 `json:"retailerId,omitempty"`
+	Sku            string               `json:"sku"`
+	Quantity       float64              `json:"quantity"`
+	UnitId         int                  `json:"unitId,omitempty"`
+	ExpiresAt      time.Time            `json:"expiresAt"`
+	ManufacturedAt *time.Time           `json:"manufacturedAt,omitempty"`
+	Quality        product.BatchQuality `json:"quality"`
+	Version        int                  `json:"version,omitempty"`
 }
 
 type RetailerBatch struct {
@@ -56,6 +61,11 @@ func (b RetailerBatchBase) SetExpiresAt(expiresAt time.Time) RetailerBatchBase {
 	return b
 }
 
+// getRetailerBatchCreateKey keeps inputs apart when they go to different retailers or expiry dates
+func getRetailerBatchCreateKey(input RetailerBatchInput) string {
+	return strconv.Itoa(*input.RetailerId) + ":" + product.GetBatchCreateKey(input.Sku, input.ExpiresAt, input.ManufacturedAt)
+}
+
 func ValidateBatchInputsIncrement(inputs []RetailerBatchInput) error {
 	if len(inputs) == 0 {
 		return common.NewValidationError(
@@ -0,0 +1,36 @@
+package retailer
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
+	"go.uber.org/zap"
+)
+
+func (r *RetailerBatchRepository) UpdateRetailerBatchQuality(
+	ctx context.Context,
+	batchBase RetailerBatchBase,
+	quality product.BatchQuality,
+	fencingToken int64,
+) error {
+	sql := `
+	UPDATE retailer_batches SET quality_status = $1, quality_reason = $2, quality_changed_by = $3,
+	quality_changed_at = CURRENT_TIMESTAMP, fencing_token = $4, version = version + 1
+	WHERE id = $5 and retailer_id = $6 and fencing_token <= $4 and version = $7
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	tag, err := op.Exec(ctx, sql,
+		quality.Status, quality.Reason, quality.ChangedBy, fencingToken,
+		batchBase.Id, batchBase.RetailerId, batchBase.Version,
+	)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to update retailer batch quality", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to update retailer batch quality")
+	}
+	if tag.RowsAffected() == 0 {
+		common.LoggerFromCtx(ctx).Warn("retailer batch quality update rejected by version or fencing token", zap.Int64("fencing_token", fencingToken))
+		return common.NewConflictError("Batch was modified by another request, please try again")
+	}
+	return nil
+}
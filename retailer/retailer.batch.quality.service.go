@@ -0,0 +1,68 @@
+package retailer
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
+	"github.com/nayefradwi/zanobia_inventory_manager/user"
+)
+
+func (s *RetailerBatchService) SetBatchQuality(ctx context.Context, input product.BatchQualityInput) error {
+	if err := product.ValidateBatchQualityInput(input); err != nil {
+		return err
+	}
+	if !user.GetUserFromContext(ctx).HasPermissionInRetailer(user.HasRetailerBatchControlPermission, input.RetailerId) {
+		return common.NewForbiddenError("User does not have permission", user.HasRetailerBatchControlPermission)
+	}
+	return s.updateBatchQuality(ctx, input.BatchId, input.RetailerId, input.Status, input.Reason)
+}
+
+func (s *RetailerBatchService) ReleaseBatch(ctx context.Context, input product.BatchReleaseInput) error {
+	if err := product.ValidateBatchReleaseInput(input); err != nil {
+		return err
+	}
+	// retailer managers can hold batches but releasing one needs the permission itself
+	if !user.GetUserFromContext(ctx).HasExactPermissionInRetailer(user.CanReleaseBatchPermission, input.RetailerId) {
+		return common.NewForbiddenError("User does not have permission", user.CanReleaseBatchPermission)
+	}
+	return s.updateBatchQuality(ctx, input.BatchId, input.RetailerId, product.BatchQualityAvailable, input.Reason)
+}
+
+func (s *RetailerBatchService) updateBatchQuality(
+	ctx context.Context,
+	batchId int,
+	retailerId int,
+	status string,
+	reason string,
+) error {
+	return common.RunWithTransaction(ctx, s.repo.(*RetailerBatchRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		batchBase, err := s.repo.GetRetailerBatchBaseById(ctx, &batchId, retailerId)
+		if err != nil {
+			return err
+		}
+		bulkBatchUpdateInfo, err := s.lockBatchUpdateRequest(ctx, BulkRetailerBatchUpdateInfo{
+			Ids:     []int{batchId},
+			SkuList: []string{batchBase.Sku},
+		})
+		defer s.unlockBatchUpdateRequest(ctx, bulkBatchUpdateInfo)
+		if err != nil {
+			return err
+		}
+		// read again now that no other request can change the batch
+		batchBase, err = s.repo.GetRetailerBatchBaseById(ctx, &batchId, retailerId)
+		if err != nil {
+			return err
+		}
+		if err := product.GetBatchQualityChangeError(batchBase.Quality, status); err != nil {
+			return err
+		}
+		changedBy := user.GetUserFromContext(ctx).Id
+		return s.repo.UpdateRetailerBatchQuality(ctx, batchBase, product.BatchQuality{
+			Status:    status,
+			Reason:    reason,
+			ChangedBy: &changedBy,
+		}, common.GetFencingToken(bulkBatchUpdateInfo.locks))
+	})
+}
@@ -0,0 +1,31 @@
+package retailer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/product"
+	"github.com/nayefradwi/zanobia_inventory_manager/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseBatch_RetailerManagerWithoutPermissionIsForbidden(t *testing.T) {
+	manager := user.User{
+		Id: 1,
+		Permissions: map[string]user.PermissionClaim{
+			user.HasRetailerControlPermission:      {},
+			user.HasRetailerBatchControlPermission: {},
+		},
+		Retailers: []user.UserRetailer{{RetailerId: 3}},
+	}
+	ctx := context.WithValue(context.Background(), common.UserKey{}, manager)
+	service := &RetailerBatchService{}
+
+	err := service.ReleaseBatch(ctx, product.BatchReleaseInput{BatchId: 5, RetailerId: 3, Reason: "lab passed"})
+	apiErr, ok := err.(*common.ApiError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+	assert.Equal(t, user.CanReleaseBatchPermission, apiErr.Code)
+}
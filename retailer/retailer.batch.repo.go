@@ -22,6 +22,7 @@ type IRetailerBatchRepository interface {
 	DeleteBatchesOfRetailer(ctx context.Context, retailerId int) error
 	GetBulkBatchUpdateInfo(ctx context.Context, inputs []RetailerBatchInput) (BulkRetailerBatchUpdateInfo, error)
 	GetBatches(ctx context.Context, params common.PaginationParams) ([]RetailerBatch, error)
+	UpdateRetailerBatchQuality(ctx context.Context, batchBase RetailerBatchBase, quality product.BatchQuality, fencingToken int64) error
 }
 
 type RetailerBatchRepository struct {
@@ -50,7 +51,7 @@ func (r *RetailerBatchRepository) CreateRetailerBatch(ctx context.Context, input
 func (r *RetailerBatchRepository) UpdateRetailerBatch(ctx context.Context, base RetailerBatchBase) error {
 	updatedAt := time.Now().UTC()
 	op := common.GetOperator(ctx, r.Pool)
-	sql := `UPDATE retailer_batches SET quantity = $1, updated_at = $2 WHERE id = $3 and retailer_id = $4`
+	sql := `UPDATE retailer_batches SET quantity = $1, updated_at = $2, version = version + 1 WHERE id = $3 and retailer_id = $4`
 	_, err := op.Exec(ctx, sql, base.Quantity, updatedAt, base.Id, base.RetailerId)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("Failed to update retailer batch", zap.Error(err))
@@ -61,12 +62,13 @@ func (r *RetailerBatchRepository) UpdateRetailerBatch(ctx context.Context, base
 
 func (r *RetailerBatchRepository) GetRetailerBatchBaseById(ctx context.Context, id *int, retailerId int) (RetailerBatchBase, error) {
 	op := common.GetOperator(ctx, r.Pool)
-	sql := `SELECT id, sku, quantity, unit_id, expires_at, retailer_id FROM retailer_batches WHERE id = $1 AND retailer_id = $2`
+	sql := `SELECT id, sku, quantity, unit_id, expires_at, retailer_id, version, quality_status FROM retailer_batches WHERE id = $1 AND retailer_id = $2`
 	row := op.QueryRow(ctx, sql, id, retailerId)
 	var retailerBatchBase RetailerBatchBase
 	err := row.Scan(
 		&retailerBatchBase.Id, &retailerBatchBase.Sku, &retailerBatchBase.Quantity,
 		&retailerBatchBase.UnitId, &retailerBatchBase.ExpiresAt, &retailerBatchBase.RetailerId,
+		&retailerBatchBase.Version, &retailerBatchBase.Quality.Status,
 	)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("Failed to get retailer batch base", zap.Error(err))
@@ -184,7 +186,8 @@ func (r *RetailerBatchRepository) GetBatches(
 }
 
 const baseBatchListingSql = `
-select b.id, b.sku, b.quantity, b.expires_at, utx.unit_id, utx.name, utx.symbol,
+select b.id, b.sku, b.quantity, b.expires_at, b.manufactured_at,
+b.quality_status, coalesce(b.quality_reason, ''), b.quality_changed_by, b.quality_changed_at, utx.unit_id, utx.name, utx.symbol,
 pvartx.name, pvar.id, pvar.price, pvar.product_id, ptx.name,
 rtx.retailer_id, rtx.name
 from retailer_batches b
@@ -204,7 +207,8 @@ func (r *RetailerBatchRepository) parseRetailerBatchRows(rows pgx.Rows) ([]Retai
 		var productVariantBase product.ProductVariantBase
 		var unit unit.Unit
 		err := rows.Scan(
-			&retailerBatch.Id, &retailerBatch.Sku, &retailerBatch.Quantity, &retailerBatch.ExpiresAt,
+			&retailerBatch.Id, &retailerBatch.Sku, &retailerBatch.Quantity, &retailerBatch.ExpiresAt, &retailerBatch.ManufacturedAt,
+			&retailerBatch.Quality.Status, &retailerBatch.Quality.Reason, &retailerBatch.Quality.ChangedBy, &retailerBatch.Quality.ChangedAt,
 			&unit.Id, &unit.Name, &unit.Symbol,
 			&productVariantBase.Name, &productVariantBase.Id, &productVariantBase.Price,
 			&productVariantBase.ProductId, &retailerBatch.ProductName, &retailerBatch.RetailerId, &retailerBatch.RetailerName,
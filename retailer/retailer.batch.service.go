@@ -8,6 +8,7 @@ import (
 	"github.com/nayefradwi/zanobia_inventory_manager/product"
 	"github.com/nayefradwi/zanobia_inventory_manager/transactions"
 	"github.com/nayefradwi/zanobia_inventory_manager/unit"
+	"github.com/nayefradwi/zanobia_inventory_manager/user"
 )
 
 type IRetailerBatchService interface {
@@ -19,6 +20,8 @@ type IRetailerBatchService interface {
 	SearchBatchesBySku(ctx context.Context, retailerId int, sku string) (common.PaginatedResponse[RetailerBatch], error)
 	DeleteBatchesOfRetailer(ctx context.Context, retailerId int) error
 	GetBatches(ctx context.Context) (common.PaginatedResponse[RetailerBatch], error)
+	SetBatchQuality(ctx context.Context, input product.BatchQualityInput) error
+	ReleaseBatch(ctx context.Context, input product.BatchReleaseInput) error
 }
 
 type RetailerBatchService struct {
@@ -111,6 +114,7 @@ func (s *RetailerBatchService) convertBatchInput(
 		ToUnitId:   &batchVariantMetaInfo.UnitId,
 		Quantity:   batchInput.Quantity,
 		FromUnitId: &batchInput.UnitId,
+		Sku:        batchInput.Sku,
 	}
 	conversionOutput, err := s.unitService.ConvertUnit(ctx, convertInput)
 	if err != nil {
@@ -140,3 +144,14 @@ func (s *RetailerBatchService) processBulkBatchUnitOfWork(
 			pgxBatch,
 		)
 }
+
+// authorizeBatchInputs makes sure staff only adjust batches of the retailers they are assigned to
+func authorizeBatchInputs(ctx context.Context, inputs []RetailerBatchInput) error {
+	currentUser := user.GetUserFromContext(ctx)
+	for _, input := range inputs {
+		if !currentUser.HasPermissionInRetailer(user.HasRetailerBatchControlPermission, *input.RetailerId) {
+			return common.NewForbiddenError("User does not have permission", user.HasRetailerBatchControlPermission)
+		}
+	}
+	return nil
+}
@@ -25,18 +25,21 @@ type RetailerBatchUpdateRequest struct {
 	Reason     string
 	Sku        string
 	ModifiedBy float64
+	Version    int
 }
 
 type RetailerBatchCreateRequest struct {
-	BatchSku   string
-	RetailerId int
-	Quantity   float64
-	UnitId     int
-	ExpiryDate time.Time
+	BatchSku       string
+	RetailerId     int
+	Quantity       float64
+	UnitId         int
+	ExpiryDate     time.Time
+	ManufacturedAt *time.Time
 }
 
 type BulkRetailerBatchUpdateUnitOfWork struct {
 	BatchUpdateRequestLookup map[string]RetailerBatchUpdateRequest
 	BatchCreateRequestLookup map[string]RetailerBatchCreateRequest
 	BatchTransactionHistory  []transactions.CreateRetailerTransactionCommand
+	FencingToken             int64
 }
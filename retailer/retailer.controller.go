@@ -3,6 +3,8 @@ package retailer
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 )
 
@@ -100,3 +102,48 @@ func (c RetailerController) UpdateRetailer(w http.ResponseWriter, r *http.Reques
 		})
 	})
 }
+
+func (c RetailerController) AddUserToRetailer(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[RetailerUserInput](w, r.Body, func(input RetailerUserInput) {
+		err := c.service.AddUserToRetailer(r.Context(), input)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "User added to retailer successfully",
+		})
+	})
+}
+
+func (c RetailerController) RemoveUserFromRetailer(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	userId := common.GetIntURLParam(r, "userId")
+	err := c.service.RemoveUserFromRetailer(r.Context(), id, userId)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Error:   err,
+		Writer:  w,
+		Message: "User removed from retailer successfully",
+	})
+}
+
+func (c RetailerController) AddRetailerPermissions(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[RetailerPermissionInput](w, r.Body, func(input RetailerPermissionInput) {
+		err := c.service.AddRetailerPermissions(r.Context(), input)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Retailer permissions added successfully",
+		})
+	})
+}
+
+func (c RetailerController) RemoveRetailerPermission(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	userId := common.GetIntURLParam(r, "userId")
+	handle := chi.URLParam(r, "permissionHandle")
+	err := c.service.RemoveRetailerPermission(r.Context(), id, userId, handle)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Error:   err,
+		Writer:  w,
+		Message: "Retailer permission removed successfully",
+	})
+}
@@ -19,6 +19,17 @@ type RetailerContact struct {
 	Website  string `json:"website,omitempty"`
 }
 
+type RetailerUserInput struct {
+	RetailerId int `json:"retailerId"`
+	UserId     int `json:"userId"`
+}
+
+type RetailerPermissionInput struct {
+	RetailerId        int      `json:"retailerId"`
+	UserId            int      `json:"userId"`
+	PermissionHandles []string `json:"permissionHandles"`
+}
+
 func (r Retailer) GetCursorValue() []string {
 	return []string{strconv.Itoa(*r.Id)}
 }
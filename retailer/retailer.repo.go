@@ -20,6 +20,10 @@ type IRetailerRepository interface {
 	RemoveRetailerTranslations(ctx context.Context, retailerId int) error
 	UpdateRetailer(ctx context.Context, retailer Retailer) error
 	RemoveAllContactsOfRetailer(ctx context.Context, retailerId int) error
+	AddUserToRetailer(ctx context.Context, input RetailerUserInput) error
+	RemoveUserFromRetailer(ctx context.Context, retailerId, userId int) error
+	AddRetailerPermissions(ctx context.Context, input RetailerPermissionInput) error
+	RemoveRetailerPermission(ctx context.Context, retailerId, userId int, permissionHandle string) error
 }
 
 type RetailerRepo struct {
@@ -337,3 +341,53 @@ func (r RetailerRepo) removeAllContactInfoTranslationsOfRetailer(ctx context.Con
 	}
 	return nil
 }
+
+func (r *RetailerRepo) AddUserToRetailer(ctx context.Context, input RetailerUserInput) error {
+	sql := `INSERT INTO user_retailers (retailer_id, user_id) VALUES ($1, $2)`
+	_, err := r.Exec(ctx, sql, input.RetailerId, input.UserId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to add user to retailer", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to add user to retailer")
+	}
+	return nil
+}
+
+func (r *RetailerRepo) RemoveUserFromRetailer(ctx context.Context, retailerId, userId int) error {
+	sql := `DELETE FROM user_retailers WHERE retailer_id = $1 AND user_id = $2`
+	c, err := r.Exec(ctx, sql, retailerId, userId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to remove user from retailer", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to remove user from retailer")
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("User does not belong to the retailer")
+	}
+	return nil
+}
+
+func (r *RetailerRepo) AddRetailerPermissions(ctx context.Context, input RetailerPermissionInput) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		sql := `INSERT INTO user_retailer_permissions (user_id, retailer_id, permission_handle) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, retailer_id, permission_handle) DO NOTHING`
+		for _, handle := range input.PermissionHandles {
+			if _, err := tx.Exec(ctx, sql, input.UserId, input.RetailerId, handle); err != nil {
+				common.LoggerFromCtx(ctx).Error("Failed to add retailer permission", zap.Error(err))
+				return common.NewBadRequestFromMessage("Failed to add retailer permissions, the user must belong to the retailer")
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RetailerRepo) RemoveRetailerPermission(ctx context.Context, retailerId, userId int, permissionHandle string) error {
+	sql := `DELETE FROM user_retailer_permissions WHERE retailer_id = $1 AND user_id = $2 AND permission_handle = $3`
+	c, err := r.Exec(ctx, sql, retailerId, userId, permissionHandle)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to remove retailer permission", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to remove retailer permission")
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("User does not have this permission in the retailer")
+	}
+	return nil
+}
@@ -16,6 +16,10 @@ type IRetailerService interface {
 	RemoveRetailerContactInfo(ctx context.Context, id int) error
 	RemoveRetailer(ctx context.Context, id int) error
 	UpdateRetailer(ctx context.Context, retailer Retailer) error
+	AddUserToRetailer(ctx context.Context, input RetailerUserInput) error
+	RemoveUserFromRetailer(ctx context.Context, retailerId, userId int) error
+	AddRetailerPermissions(ctx context.Context, input RetailerPermissionInput) error
+	RemoveRetailerPermission(ctx context.Context, retailerId, userId int, permissionHandle string) error
 }
 
 type RetailerService struct {
@@ -102,3 +106,25 @@ func (s *RetailerService) UpdateRetailer(ctx context.Context, retailer Retailer)
 	}
 	return s.repo.UpdateRetailer(ctx, retailer)
 }
+
+func (s *RetailerService) AddUserToRetailer(ctx context.Context, input RetailerUserInput) error {
+	if input.RetailerId <= 0 || input.UserId <= 0 {
+		return common.NewBadRequestFromMessage("retailer id and user id are required")
+	}
+	return s.repo.AddUserToRetailer(ctx, input)
+}
+
+func (s *RetailerService) RemoveUserFromRetailer(ctx context.Context, retailerId, userId int) error {
+	return s.repo.RemoveUserFromRetailer(ctx, retailerId, userId)
+}
+
+func (s *RetailerService) AddRetailerPermissions(ctx context.Context, input RetailerPermissionInput) error {
+	if input.RetailerId <= 0 || input.UserId <= 0 || len(input.PermissionHandles) == 0 {
+		return common.NewBadRequestFromMessage("retailer id, user id and permission handles are required")
+	}
+	return s.repo.AddRetailerPermissions(ctx, input)
+}
+
+func (s *RetailerService) RemoveRetailerPermission(ctx context.Context, retailerId, userId int, permissionHandle string) error {
+	return s.repo.RemoveRetailerPermission(ctx, retailerId, userId, permissionHandle)
+}
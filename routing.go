@@ -20,9 +20,11 @@ func RegisterRoutes(provider *ServiceProvider) chi.Router {
 	registerUserRoutes(r, provider)
 	registerPermissionRoutes(r, provider)
 	authorizedRouter, _ := createSecureRouter(provider)
-	// TODO: validate user warehouse
 	registerRoleRoutes(authorizedRouter, provider)
+	registerServiceAccountRoutes(authorizedRouter, provider)
 	registerProductRoutes(authorizedRouter, provider)
+	registerCategoryRoutes(authorizedRouter, provider)
+	registerCatalogueRoutes(authorizedRouter, provider)
 	registerWarehouseRoutes(authorizedRouter, provider)
 	registerRetailerRoutes(authorizedRouter, provider)
 	registerTransactionRoutes(authorizedRouter, provider)
@@ -33,6 +35,7 @@ func RegisterRoutes(provider *ServiceProvider) chi.Router {
 
 func registerUserRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	userController := user.NewUserController(provider.services.userService)
+	roleController := user.NewRoleController(provider.services.roleService)
 	userRouter := chi.NewRouter()
 	userRouter.Group(func(r chi.Router) {
 		middleware := user.NewUserMiddleware(provider.services.userService)
@@ -40,21 +43,25 @@ func registerUserRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 		r.Use(common.AuthenticationHeaderMiddleware)
 		r.Use(middleware.SetUserFromHeader)
 		r.Get("/me", userController.GetUserByContext)
+		r.Post("/logout", userController.Logout)
 		r.Group(func(r chi.Router) {
 			r.With(adminMiddleware).Get("/", userController.GetAllUsers)
 			r.With(adminMiddleware).Post("/", userController.CreateUser)
 			r.With(adminMiddleware).Post("/ban", userController.BanUser)
+			r.With(adminMiddleware).Post("/{id}/roles", roleController.AssignRoleToUser)
+			r.With(adminMiddleware).Delete("/{id}/roles/{roleId}", roleController.UnassignRoleFromUser)
+			removePermissionRoute := fmt.Sprintf("/{id}/permissions/{%s}", user.PermissionHandleParam)
+			r.With(adminMiddleware).Delete(removePermissionRoute, userController.RemovePermission)
 		})
 	})
-	userRouter.Post("/initial-sys-admin", userController.InitiateSysAdminUser)
 	userRouter.Post("/login", userController.LoginUser)
+	userRouter.Post("/refresh", userController.RefreshToken)
 	mainRouter.Mount("/users", userRouter)
 }
 
 func registerPermissionRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	permissionController := user.NewPermissionController(provider.services.permissionService)
 	permissionRouter := chi.NewRouter()
-	permissionRouter.Post("/initial-permissions", permissionController.InitiateInitialPermissions)
 	permissionRouter.Group(func(r chi.Router) {
 		middleware := user.NewUserMiddleware(provider.services.userService)
 		r.Use(common.AuthenticationHeaderMiddleware)
@@ -76,9 +83,26 @@ func registerRoleRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	roleRouter.Use(userMiddleware.HasPermissions(user.SysAdminPermissionHandle))
 	roleRouter.Post("/", roleController.CreateRole)
 	roleRouter.Get("/", roleController.GetRoles)
+	roleRouter.Put("/{id}", roleController.UpdateRole)
+	roleRouter.Delete("/{id}", roleController.DeleteRole)
+	removePermissionRoute := fmt.Sprintf("/{id}/permissions/{%s}", user.PermissionHandleParam)
+	roleRouter.Delete(removePermissionRoute, roleController.RemovePermissionFromRole)
 	mainRouter.Mount("/roles", roleRouter)
 }
 
+func registerServiceAccountRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
+	serviceAccountRouter := chi.NewRouter()
+	serviceAccountController := user.NewServiceAccountController(provider.services.serviceAccountService)
+	userMiddleware := newUserMiddleWare(provider)
+	serviceAccountRouter.Use(userMiddleware.HasPermissions(user.SysAdminPermissionHandle))
+	serviceAccountRouter.Post("/", serviceAccountController.CreateServiceAccount)
+	serviceAccountRouter.Get("/", serviceAccountController.GetServiceAccounts)
+	serviceAccountRouter.Post("/{id}/keys", serviceAccountController.CreateApiKey)
+	serviceAccountRouter.Post("/{id}/keys/rotate", serviceAccountController.RotateApiKeys)
+	serviceAccountRouter.Delete("/{id}/keys/{keyId}", serviceAccountController.RevokeApiKey)
+	mainRouter.Mount("/service-accounts", serviceAccountRouter)
+}
+
 func registerProductRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	productRouter := chi.NewRouter()
 	productController := product.NewProductController(provider.services.productService)
@@ -101,6 +125,41 @@ func registerProductRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	mainRouter.Mount("/products", productRouter)
 }
 
+func registerCategoryRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
+	categoryRouter := chi.NewRouter()
+	categoryController := product.NewCategoryController(provider.services.categoryService)
+	categoryRouter.Group(func(r chi.Router) {
+		userMiddleware := newUserMiddleWare(provider)
+		r.Use(userMiddleware.HasPermissions(user.HasProductControlPermission))
+		r.Post("/", categoryController.CreateCategory)
+		r.Post("/translation", categoryController.TranslateCategory)
+		r.Put("/", categoryController.UpdateCategory)
+		r.Delete("/{id}", categoryController.DeleteCategory)
+	})
+	categoryRouter.Get("/", categoryController.GetCategories)
+	categoryRouter.Get("/{id}", categoryController.GetCategory)
+	mainRouter.Mount("/categories", categoryRouter)
+}
+
+func registerCatalogueRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
+	catalogueRouter := chi.NewRouter()
+	catalogueController := product.NewCatalogueController(provider.services.catalogueService)
+	userMiddleware := newUserMiddleWare(provider)
+	catalogueRouter.Group(func(r chi.Router) {
+		r.Use(userMiddleware.RequireWarehouse)
+		r.Use(userMiddleware.HasWarehousePermissions(user.HasBatchControlPermission))
+		// the warehouse sheets are matched before the generic kind so they require a warehouse
+		r.Post("/import/{kind:stock|opening-balance}", catalogueController.Import)
+		r.Get("/export/{kind:stock|opening-balance}", catalogueController.Export)
+	})
+	catalogueRouter.Group(func(r chi.Router) {
+		r.Use(userMiddleware.HasPermissions(user.HasProductControlPermission))
+		r.Post("/import/{kind}", catalogueController.Import)
+		r.Get("/export/{kind}", catalogueController.Export)
+	})
+	mainRouter.Mount("/catalogue", catalogueRouter)
+}
+
 func registerUnitRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	unitRouter := chi.NewRouter()
 	unitController := unit.NewUnitController(provider.services.unitService)
@@ -126,6 +185,10 @@ func registerUnitConversions(mainRouter *chi.Mux, provider *ServiceProvider) {
 	unitConversionRouter.With(adminPermissionMiddleware).Post("/", unitConversionController.CreateConversion)
 	unitConversionRouter.Post("/convert", unitConversionController.ConvertUnit)
 	unitConversionRouter.Get("/", unitConversionController.GetAllUnitConversions)
+	unitConversionRouter.
+		With(userMiddleware.HasPermissions(user.HasProductControlPermission)).
+		Post("/products", unitConversionController.CreateProductConversion)
+	unitConversionRouter.Get("/products/{sku}", unitConversionController.GetProductConversions)
 	mainRouter.Mount("/unit-conversions", unitConversionRouter)
 }
 
@@ -138,6 +201,7 @@ func registerProductVariantRoutes(mainRouter *chi.Mux, provider *ServiceProvider
 		deleteProductMiddleware := userMiddleware.HasPermissions(user.CanDeleteProductPermission)
 		r.Use(controlProductMiddleware)
 		r.Post("/", productController.CreateProductVariant)
+		r.Post("/generate", productController.GenerateProductVariants)
 		r.Put("/", productController.UpdateProductVariantDetails)
 		r.Put("/sku", productController.UpdateProductVariantSku)
 		r.Put("/{id}/archive", productController.ArchiveProductVariant)
@@ -147,7 +211,7 @@ func registerProductVariantRoutes(mainRouter *chi.Mux, provider *ServiceProvider
 	})
 	productVariantRouter.Get("/{id}", productController.GetProductVariant)
 	productVariantRouter.Get("/sku/{sku}", productController.GetProductVariantBySku)
-	productVariantRouter.Post("/search", productController.SearchProductVariantByName)
+	productVariantRouter.Post("/search", productController.SearchProductVariants)
 	registerRecipeRoutes(productVariantRouter, provider)
 	registerBatchesRoutes(productVariantRouter, provider)
 	mainRouter.Mount("/product-variants", productVariantRouter)
@@ -168,9 +232,11 @@ func registerRecipeRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 func registerBatchesRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	batchRouter := chi.NewRouter()
 	batchController := product.NewBatchController(provider.services.batchService)
+	userMiddleware := newUserMiddleWare(provider)
+	batchRouter.Use(userMiddleware.RequireWarehouse)
 	batchRouter.Group(func(r chi.Router) {
 		newUserMiddleWare := newUserMiddleWare(provider)
-		controlBatchMiddleware := newUserMiddleWare.HasPermissions(user.HasBatchControlPermission)
+		controlBatchMiddleware := newUserMiddleWare.HasWarehousePermissions(user.HasBatchControlPermission)
 		r.Use(controlBatchMiddleware)
 		r.Post("/batch/stock", batchController.IncrementBatch)
 		r.Delete("/batch/stock", batchController.DecrementBatch)
@@ -178,9 +244,20 @@ func registerBatchesRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 		r.Delete("/stock", batchController.BulkDecrementBatch)
 		r.Post("/batch/stock/with-recipe", batchController.IncrementBatchWithRecipe)
 		r.Post("/stock/with-recipe", batchController.BulkIncrementBatchWithRecipe)
+		r.Post("/opening-balance", batchController.ImportOpeningBalance)
+		r.Post("/batch/split", batchController.SplitBatch)
+		r.Post("/batch/merge", batchController.MergeBatches)
+		r.With(newUserMiddleWare.HasWarehousePermissions(user.CanRedateBatchPermission)).Put("/batch/expiry", batchController.RedateBatch)
+		r.Put("/batch/quality", batchController.SetBatchQuality)
+		r.Put("/batch/location", batchController.MoveBatch)
+		r.Post("/pick-list", batchController.CreatePickList)
+		r.Post("/pick-list/confirm", batchController.ConfirmPick)
+		r.With(newUserMiddleWare.HasWarehousePermissions(user.CanReleaseBatchPermission)).Put("/batch/quality/release", batchController.ReleaseBatch)
 	})
 	batchRouter.Get("/", batchController.GetBatches)
 	batchRouter.Get("/search", batchController.SearchBatchesBySku)
+	categoryController := product.NewCategoryController(provider.services.categoryService)
+	batchRouter.Get("/categories", categoryController.GetStockSummaryByCategory)
 	batchRouter.Get("/{id}", batchController.GetBatchById)
 	mainRouter.Mount("/batches", batchRouter)
 }
@@ -193,10 +270,18 @@ func registerWarehouseRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 		adminMiddleware := middleware.HasPermissions(user.SysAdminPermissionHandle)
 		r.Use(adminMiddleware)
 		r.Post("/user", warehouseController.AddUserToWarehouse)
+		r.Post("/user/permissions", warehouseController.AddWarehousePermissions)
+		r.Delete("/{id}/users/{userId}/permissions/{permissionHandle}", warehouseController.RemoveWarehousePermission)
 		r.Put("/", warehouseController.UpdateWarehouse)
 		r.Post("/", warehouseController.CreateWarehouse)
 	})
-	warehouseRouter.Get("/current", warehouseController.GetCurrentWarehouse)
+	userMiddleware := newUserMiddleWare(provider)
+	warehouseRouter.With(userMiddleware.RequireWarehouse).Get("/current", warehouseController.GetCurrentWarehouse)
+	warehouseRouter.Group(func(r chi.Router) {
+		r.Use(userMiddleware.RequireWarehouse)
+		r.Get("/locations", warehouseController.GetLocations)
+		r.With(userMiddleware.HasWarehousePermissions(user.HasLocationControlPermission)).Post("/locations", warehouseController.CreateLocation)
+	})
 	warehouseRouter.Get("/", warehouseController.GetWarehouses)
 	mainRouter.Mount("/warehouses", warehouseRouter)
 }
@@ -206,20 +291,27 @@ func registerRetailerRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	retailerRouter := chi.NewRouter()
 	retailerController := retailer.NewRetailerController(provider.services.retailerService)
 	batchController := retailer.NewRetailerBatchController(provider.services.retailerBatchService)
-	retailerRouter.Post("/", retailerController.CreateRetailer)
-	retailerRouter.Post("/{id}/contacts", retailerController.AddRetailerContacts)
-	retailerRouter.Post("/{id}/contact", retailerController.AddRetailerContactInfo)
-	retailerRouter.Get("/", retailerController.GetRetailers)
-	retailerRouter.Get("/{id}", retailerController.GetRetailer)
-	retailerRouter.Delete("/contact/{id}", retailerController.RemoveRetailerContactInfo)
-	retailerRouter.
-		With(middleware.HasPermissions(
-			user.SysAdminPermissionHandle,
-		)).
-		Delete("/{id}", retailerController.RemoveRetailer)
-	retailerRouter.Put("/", retailerController.UpdateRetailer)
-	retailerRouter.Get("/{id}/batches", batchController.GetBatchesOfRetailer)
-	retailerRouter.Get("/{id}/batches/search", batchController.SearchBatchesBySku)
+	retailerRouter.Group(func(r chi.Router) {
+		r.Use(middleware.HasPermissions(user.HasRetailerControlPermission))
+		r.Post("/", retailerController.CreateRetailer)
+		r.Post("/{id}/contacts", retailerController.AddRetailerContacts)
+		r.Post("/{id}/contact", retailerController.AddRetailerContactInfo)
+		r.Get("/", retailerController.GetRetailers)
+		r.Delete("/contact/{id}", retailerController.RemoveRetailerContactInfo)
+		r.Put("/", retailerController.UpdateRetailer)
+	})
+	retailerRouter.Group(func(r chi.Router) {
+		r.Use(middleware.HasPermissions(user.SysAdminPermissionHandle))
+		r.Delete("/{id}", retailerController.RemoveRetailer)
+		r.Post("/user", retailerController.AddUserToRetailer)
+		r.Delete("/{id}/users/{userId}", retailerController.RemoveUserFromRetailer)
+		r.Post("/user/permissions", retailerController.AddRetailerPermissions)
+		r.Delete("/{id}/users/{userId}/permissions/{permissionHandle}", retailerController.RemoveRetailerPermission)
+	})
+	retailerAccessMiddleware := middleware.RequireRetailerAccess()
+	retailerRouter.With(retailerAccessMiddleware).Get("/{id}", retailerController.GetRetailer)
+	retailerRouter.With(retailerAccessMiddleware).Get("/{id}/batches", batchController.GetBatchesOfRetailer)
+	retailerRouter.With(retailerAccessMiddleware).Get("/{id}/batches/search", batchController.SearchBatchesBySku)
 	registerRetailerBatchRoutes(retailerRouter, provider)
 	mainRouter.Mount("/retailers", retailerRouter)
 }
@@ -227,11 +319,15 @@ func registerRetailerRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 func registerRetailerBatchRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 	batchRouter := chi.NewRouter()
 	batchController := retailer.NewRetailerBatchController(provider.services.retailerBatchService)
+	userMiddleware := newUserMiddleWare(provider)
+	// stock changes are checked per retailer in the service since the retailer ids come in the body
 	batchRouter.Post("/batch/stock", batchController.IncrementBatch)
 	batchRouter.Delete("/batch/stock", batchController.DecrementBatch)
 	batchRouter.Post("/stock", batchController.BulkIncrementBatch)
 	batchRouter.Delete("/stock", batchController.BulkDecrementBatch)
-	batchRouter.Get("/", batchController.GetBatches)
+	batchRouter.Put("/batch/quality", batchController.SetBatchQuality)
+	batchRouter.Put("/batch/quality/release", batchController.ReleaseBatch)
+	batchRouter.With(userMiddleware.HasPermissions(user.HasRetailerControlPermission)).Get("/", batchController.GetBatches)
 	// batchRouter.Post("/batch/stock/from-warehouse", batchController.MoveFromWarehouseToRetailer)
 	// batchRouter.Delete("/batch/stock/to-warehouse", batchController.ReturnToWarehouseToRetailer)
 	mainRouter.Mount("/batches", batchRouter)
@@ -248,11 +344,19 @@ func registerTransactionRoutes(mainRouter *chi.Mux, provider *ServiceProvider) {
 		r.Post("/initiate-reasons", transactionController.InitiateAllReasons)
 	})
 	transactionRouter.Get("/reasons", transactionController.GetTransactionReasons)
-	transactionRouter.Get("/retailer/{id}", transactionController.GetTransactionsOfRetailer)
-	transactionRouter.Get("/retailer/{id}/batch/{batchId}", transactionController.GetTransactionsOfRetailerBatch)
-	transactionRouter.Get("/sku/{sku}", transactionController.GetTransactionsOfSku)
-	transactionRouter.Get("/batch/{id}", transactionController.GetTransactionsOfBatch)
-	transactionRouter.Get("/warehouse", transactionController.GetTransactionsOfMyWarehouse)
+	transactionRouter.Group(func(r chi.Router) {
+		userMiddleware := newUserMiddleWare(provider)
+		r.Use(userMiddleware.RequireRetailerAccess())
+		r.Get("/retailer/{id}", transactionController.GetTransactionsOfRetailer)
+		r.Get("/retailer/{id}/batch/{batchId}", transactionController.GetTransactionsOfRetailerBatch)
+	})
+	transactionRouter.Group(func(r chi.Router) {
+		userMiddleware := newUserMiddleWare(provider)
+		r.Use(userMiddleware.RequireWarehouse)
+		r.Get("/sku/{sku}", transactionController.GetTransactionsOfSku)
+		r.Get("/batch/{id}", transactionController.GetTransactionsOfBatch)
+		r.Get("/warehouse", transactionController.GetTransactionsOfMyWarehouse)
+	})
 	mainRouter.Mount("/transactions", transactionRouter)
 }
 
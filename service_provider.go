@@ -12,6 +12,7 @@ import (
 	"github.com/nayefradwi/zanobia_inventory_manager/user"
 	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
 var connections systemConnections
@@ -21,32 +22,38 @@ type systemConnections struct {
 	redisClient *redis.Client
 }
 type systemRepositories struct {
-	userRepository          user.IUserRepository
-	permissionRepository    user.IPermissionRepository
-	roleRepository          user.IRoleRepository
-	unitRepository          unit.IUnitRepository
-	warehouseRepository     warehouse.IWarehouseRepository
-	productRepository       product.IProductRepo
-	recipeRepository        product.IRecipeRepository
-	batchRepository         product.IBatchRepository
-	retailerRepository      retailer.IRetailerRepository
-	retailerBatchRepository retailer.IRetailerBatchRepository
-	transactionRepository   transactions.ITransactionRepository
+	userRepository           user.IUserRepository
+	permissionRepository     user.IPermissionRepository
+	roleRepository           user.IRoleRepository
+	serviceAccountRepository user.IServiceAccountRepository
+	unitRepository           unit.IUnitRepository
+	warehouseRepository      warehouse.IWarehouseRepository
+	productRepository        product.IProductRepo
+	categoryRepository       product.ICategoryRepository
+	catalogueRepository      product.ICatalogueRepository
+	recipeRepository         product.IRecipeRepository
+	batchRepository          product.IBatchRepository
+	retailerRepository       retailer.IRetailerRepository
+	retailerBatchRepository  retailer.IRetailerBatchRepository
+	transactionRepository    transactions.ITransactionRepository
 }
 
 type systemServices struct {
-	userService          user.IUserService
-	permissionService    user.IPermissionService
-	roleService          user.IRoleService
-	unitService          unit.IUnitService
-	warehouseService     warehouse.IWarehouseService
-	lockingService       common.IDistributedLockingService
-	productService       product.IProductService
-	recipeService        product.IRecipeService
-	batchService         product.IBatchService
-	retailerService      retailer.IRetailerService
-	retailerBatchService retailer.IRetailerBatchService
-	transactionService   transactions.ITransactionService
+	userService           user.IUserService
+	permissionService     user.IPermissionService
+	roleService           user.IRoleService
+	serviceAccountService user.IServiceAccountService
+	unitService           unit.IUnitService
+	warehouseService      warehouse.IWarehouseService
+	lockingService        common.IDistributedLockingService
+	productService        product.IProductService
+	categoryService       product.ICategoryService
+	catalogueService      product.ICatalogueService
+	recipeService         product.IRecipeService
+	batchService          product.IBatchService
+	retailerService       retailer.IRetailerService
+	retailerBatchService  retailer.IRetailerBatchService
+	transactionService    transactions.ITransactionService
 }
 type ServiceProvider struct {
 	services systemServices
@@ -55,13 +62,16 @@ type ServiceProvider struct {
 func (s *ServiceProvider) initiate(config ApiConfig) {
 	connections = s.setUpConnections(config)
 	repositories := s.registerRepositories(connections)
-	s.registerServices(repositories)
+	s.registerServices(config, repositories)
 }
 
 func (s *ServiceProvider) setUpConnections(config ApiConfig) systemConnections {
 	ctx := context.Background()
 	dbPool := common.ConnectDatabasePool(ctx, config.DbConnectionUrl)
-	redisClient := common.ConnectRedis(ctx, config.RedisUrl)
+	var redisClient *redis.Client
+	if config.RedisUrl != "" || config.LockingBackend == RedisLockingBackend {
+		redisClient = common.ConnectRedis(ctx, config.RedisUrl)
+	}
 	return systemConnections{
 		dbPool:      dbPool,
 		redisClient: redisClient,
@@ -72,45 +82,59 @@ func (s *ServiceProvider) registerRepositories(connections systemConnections) sy
 	userRepo := user.NewUserRepository(connections.dbPool)
 	permssionRepo := user.NewPermissionRepository(connections.dbPool)
 	roleRepo := user.NewRoleRepository(connections.dbPool)
+	serviceAccountRepo := user.NewServiceAccountRepository(connections.dbPool)
 	unitRepo := unit.NewUnitRepository(connections.dbPool)
 	warehouseRepo := warehouse.NewWarehouseRepository(connections.dbPool)
 	productRepo := product.NewProductRepository(connections.dbPool)
+	categoryRepo := product.NewCategoryRepository(connections.dbPool)
+	catalogueRepo := product.NewCatalogueRepository(connections.dbPool)
 	recipeRepo := product.NewRecipeRepository(connections.dbPool)
 	batchRepo := product.NewBatchRepository(connections.dbPool)
 	retailerRepo := retailer.NewRetailerRepository(connections.dbPool)
 	retailerBatchRepo := retailer.NewRetailerBatchRepository(connections.dbPool)
 	transactionRepo := transactions.NewTransactionRepository(connections.dbPool)
 	return systemRepositories{
-		userRepository:          userRepo,
-		permissionRepository:    permssionRepo,
-		roleRepository:          roleRepo,
-		unitRepository:          unitRepo,
-		warehouseRepository:     warehouseRepo,
-		productRepository:       productRepo,
-		recipeRepository:        recipeRepo,
-		batchRepository:         batchRepo,
-		retailerRepository:      retailerRepo,
-		retailerBatchRepository: retailerBatchRepo,
-		transactionRepository:   transactionRepo,
+		userRepository:           userRepo,
+		permissionRepository:     permssionRepo,
+		roleRepository:           roleRepo,
+		serviceAccountRepository: serviceAccountRepo,
+		unitRepository:           unitRepo,
+		warehouseRepository:      warehouseRepo,
+		productRepository:        productRepo,
+		categoryRepository:       categoryRepo,
+		catalogueRepository:      catalogueRepo,
+		recipeRepository:         recipeRepo,
+		batchRepository:          batchRepo,
+		retailerRepository:       retailerRepo,
+		retailerBatchRepository:  retailerBatchRepo,
+		transactionRepository:    transactionRepo,
 	}
 }
 
-func (s *ServiceProvider) registerServices(repositories systemRepositories) {
-	lockingService := common.CreateNewRedisLockService(connections.redisClient)
+func (s *ServiceProvider) registerServices(config ApiConfig, repositories systemRepositories) {
+	lockingService := s.createLockingService(config.LockingBackend)
+	tokenRevocationService := common.NewPostgresTokenRevocationService(connections.dbPool)
+	common.SetTokenRevocationService(tokenRevocationService)
 	userServiceInput := user.UserServiceInput{
-		Repository:       repositories.userRepository,
-		SysAdminEmail:    RegisteredApiConfig.InitialSysAdminEmail,
-		SysAdminPassword: RegisteredApiConfig.InitialSysAdminPass,
+		Repository:             repositories.userRepository,
+		TokenRevocationService: tokenRevocationService,
+		SysAdminEmail:          RegisteredApiConfig.InitialSysAdminEmail,
+		SysAdminPassword:       RegisteredApiConfig.InitialSysAdminPass,
 	}
 	userService := user.NewUserService(userServiceInput)
 	permissionService := user.NewPermissionService(repositories.permissionRepository)
 	roleService := user.NewRoleService(repositories.roleRepository)
-	unitService := unit.NewUnitService(repositories.unitRepository)
+	serviceAccountService := user.NewServiceAccountService(repositories.serviceAccountRepository)
+	common.SetApiKeyAuthenticator(serviceAccountService)
+	cacheInvalidationService := s.createCacheInvalidationService()
+	unitService := unit.NewUnitService(repositories.unitRepository, cacheInvalidationService)
 	unitService.SetupUnitsMap(context.Background())
 	unitService.SetupUnitConversionsMap(context.Background())
+	unitService.SubscribeToInvalidation(context.Background())
 	warehouseService := warehouse.NewWarehouseService(repositories.warehouseRepository)
 	recipeService := product.NewRecipeService(repositories.recipeRepository, unitService)
 	productService := product.NewProductService(repositories.productRepository, recipeService)
+	categoryService := product.NewCategoryService(repositories.categoryRepository, unitService)
 	transactionService := transactions.NewTransactionService(repositories.transactionRepository)
 	batchService := product.NewBatchService(
 		repositories.batchRepository,
@@ -119,6 +143,7 @@ func (s *ServiceProvider) registerServices(repositories systemRepositories) {
 		unitService,
 		recipeService,
 		transactionService,
+		warehouseService,
 	)
 	retailerBatchService := retailer.NewRetailerBatchService(
 		repositories.retailerBatchRepository,
@@ -129,24 +154,73 @@ func (s *ServiceProvider) registerServices(repositories systemRepositories) {
 		batchService,
 	)
 	retailerService := retailer.NewRetailerService(repositories.retailerRepository, retailerBatchService)
+	catalogueService := product.NewCatalogueService(
+		repositories.catalogueRepository,
+		repositories.productRepository,
+		productService,
+		recipeService,
+		batchService,
+	)
 	s.services = systemServices{
-		userService:          userService,
-		permissionService:    permissionService,
-		roleService:          roleService,
-		unitService:          unitService,
-		warehouseService:     warehouseService,
-		lockingService:       lockingService,
-		productService:       productService,
-		recipeService:        recipeService,
-		batchService:         batchService,
-		retailerService:      retailerService,
-		retailerBatchService: retailerBatchService,
-		transactionService:   transactionService,
+		userService:           userService,
+		permissionService:     permissionService,
+		roleService:           roleService,
+		serviceAccountService: serviceAccountService,
+		unitService:           unitService,
+		warehouseService:      warehouseService,
+		lockingService:        lockingService,
+		productService:        productService,
+		categoryService:       categoryService,
+		catalogueService:      catalogueService,
+		recipeService:         recipeService,
+		batchService:          batchService,
+		retailerService:       retailerService,
+		retailerBatchService:  retailerBatchService,
+		transactionService:    transactionService,
 	}
 }
 
+func (s *ServiceProvider) createLockingService(backend string) common.IDistributedLockingService {
+	lockingService := s.createLockingBackend(backend)
+	// every backend counts fencing tokens on its own, so after a restart or a backend
+	// switch the counter has to start above the tokens already stored on batches
+	ctx := context.Background()
+	fencingToken, err := common.GetPersistedFencingToken(ctx, connections.dbPool)
+	if err != nil {
+		common.GetLogger().Panic("failed to read persisted fencing tokens", zap.Error(err))
+	}
+	if err := lockingService.SeedFencingToken(ctx, fencingToken); err != nil {
+		common.GetLogger().Panic("failed to seed fencing tokens", zap.Error(err))
+	}
+	return lockingService
+}
+
+func (s *ServiceProvider) createLockingBackend(backend string) common.IDistributedLockingService {
+	switch backend {
+	case PostgresLockingBackend:
+		return common.CreateNewPostgresLockService(connections.dbPool)
+	case InMemoryLockingBackend:
+		common.GetLogger().Warn("in memory locks only work with a single instance")
+		return common.CreateNewInMemoryLockService()
+	case RedisLockingBackend:
+		return common.CreateNewRedisLockService(connections.redisClient)
+	default:
+		common.GetLogger().Panic("unknown locking backend", zap.String("backend", backend))
+		return nil
+	}
+}
+
+func (s *ServiceProvider) createCacheInvalidationService() common.ICacheInvalidationService {
+	if connections.redisClient == nil {
+		return common.LocalCacheInvalidationService{}
+	}
+	return common.NewRedisCacheInvalidationService(connections.redisClient)
+}
+
 func cleanUp() {
 	connections.dbPool.Close()
-	connections.redisClient.Close()
+	if connections.redisClient != nil {
+		connections.redisClient.Close()
+	}
 	common.CleanUp()
 }
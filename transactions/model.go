@@ -10,19 +10,26 @@ import (
 )
 
 const (
-	TransactionReasonTypeSold          = "sold"
-	TransactionReasonTypeBought        = "bought"
-	TransactionReasonTypeExpired       = "expired"
-	TransactionReasonTypeDamaged       = "damaged"
-	TransactionReasonTypeLost          = "lost"
-	TransactionReasonTypeFound         = "found"
-	TransactionReasonTypeReturn        = "return"
-	TransactionReasonTypeAuditIncrease = "auditIncrease"
-	TransactionReasonTypeAuditDecrease = "auditDecrease"
-	TransactionReasonTypeRecipeUse     = "recipeUse"
-	TransactionReasonTypeProduced      = "produced"
-	TransactionReasonTypeTransferIn    = "transferIn"
-	TransactionReasonTypeTransferOut   = "transferOut"
+	TransactionReasonTypeSold           = "sold"
+	TransactionReasonTypeBought         = "bought"
+	TransactionReasonTypeExpired        = "expired"
+	TransactionReasonTypeDamaged        = "damaged"
+	TransactionReasonTypeLost           = "lost"
+	TransactionReasonTypeFound          = "found"
+	TransactionReasonTypeReturn         = "return"
+	TransactionReasonTypeAuditIncrease  = "auditIncrease"
+	TransactionReasonTypeAuditDecrease  = "auditDecrease"
+	TransactionReasonTypeRecipeUse      = "recipeUse"
+	TransactionReasonTypeProduced       = "produced"
+	TransactionReasonTypeTransferIn     = "transferIn"
+	TransactionReasonTypeTransferOut    = "transferOut"
+	TransactionReasonTypeOpeningBalance = "openingBalance"
+	TransactionReasonTypeSplitOut       = "splitOut"
+	TransactionReasonTypeSplitIn        = "splitIn"
+	TransactionReasonTypeMergeOut       = "mergeOut"
+	TransactionReasonTypeMergeIn        = "mergeIn"
+	TransactionReasonTypeRedated        = "redated"
+	TransactionReasonTypeLocationMove   = "locationMove"
 )
 
 type TransactionReason struct {
@@ -178,4 +185,39 @@ var initalTransactionReasons = []TransactionReason{
 		Description: "Produced",
 		IsPositive:  true,
 	},
+	{
+		Name:        TransactionReasonTypeOpeningBalance,
+		Description: "Opening balance",
+		IsPositive:  true,
+	},
+	{
+		Name:        TransactionReasonTypeSplitOut,
+		Description: "Split into another batch",
+		IsPositive:  false,
+	},
+	{
+		Name:        TransactionReasonTypeSplitIn,
+		Description: "Split from another batch",
+		IsPositive:  true,
+	},
+	{
+		Name:        TransactionReasonTypeMergeOut,
+		Description: "Merged into another batch",
+		IsPositive:  false,
+	},
+	{
+		Name:        TransactionReasonTypeMergeIn,
+		Description: "Merged from another batch",
+		IsPositive:  true,
+	},
+	{
+		Name:        TransactionReasonTypeRedated,
+		Description: "Expiry date changed",
+		IsPositive:  false,
+	},
+	{
+		Name:        TransactionReasonTypeLocationMove,
+		Description: "Moved to another location",
+		IsPositive:  false,
+	},
 }
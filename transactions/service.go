@@ -109,7 +109,18 @@ func (r *TransactionService) CreateRetailerTransactionHistoryBatches(
 }
 
 func (r *TransactionService) InitiateAllReasons(ctx context.Context) error {
+	existingReasons, err := r.repo.GetTransactionReasons(ctx)
+	if err != nil {
+		return err
+	}
+	existingReasonNames := make(map[string]bool, len(existingReasons))
+	for _, reason := range existingReasons {
+		existingReasonNames[reason.Name] = true
+	}
 	for _, reason := range initalTransactionReasons {
+		if existingReasonNames[reason.Name] {
+			continue
+		}
 		if err := r.repo.CreateTransactionReason(ctx, reason); err != nil {
 			common.LoggerFromCtx(ctx).Error(
 				"failed to initiate reason",
@@ -3,6 +3,7 @@ package unit
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 )
 
@@ -94,3 +95,24 @@ func (c UnitController) GetAllUnitConversions(w http.ResponseWriter, r *http.Req
 		Data:   c.service.GetAllUnitConversions(r.Context()),
 	})
 }
+
+func (c UnitController) CreateProductConversion(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[ProductUnitConversion](w, r.Body, func(conversion ProductUnitConversion) {
+		err := c.service.CreateProductConversion(r.Context(), conversion)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Writer:  w,
+			Message: "Created product conversion successfully",
+		})
+	})
+}
+
+func (c UnitController) GetProductConversions(w http.ResponseWriter, r *http.Request) {
+	sku := chi.URLParam(r, "sku")
+	conversions, err := c.service.GetProductConversions(r.Context(), sku)
+	common.WriteResponse(common.Result[[]UnitConversion]{
+		Error:  err,
+		Writer: w,
+		Data:   conversions,
+	})
+}
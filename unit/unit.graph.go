@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"math"
+	"sort"
+)
+
+const conversionFactorTolerance = 0.001
+
+type conversionGraph map[int]map[int]float64
+
+func newConversionGraph(conversions map[string]UnitConversion) conversionGraph {
+	graph := make(conversionGraph)
+	for _, conversion := range conversions {
+		if conversion.FromUnitId == nil || conversion.ToUnitId == nil {
+			continue
+		}
+		graph.addEdge(*conversion.FromUnitId, *conversion.ToUnitId, conversion.ConversionFactor)
+	}
+	for _, conversion := range conversions {
+		if conversion.FromUnitId == nil || conversion.ToUnitId == nil || conversion.ConversionFactor == 0 {
+			continue
+		}
+		if _, ok := graph[*conversion.ToUnitId][*conversion.FromUnitId]; ok {
+			continue
+		}
+		graph.addEdge(*conversion.ToUnitId, *conversion.FromUnitId, 1/conversion.ConversionFactor)
+	}
+	return graph
+}
+
+func (g conversionGraph) addEdge(fromUnitId, toUnitId int, factor float64) {
+	edges, ok := g[fromUnitId]
+	if !ok {
+		edges = make(map[int]float64)
+		g[fromUnitId] = edges
+	}
+	edges[toUnitId] = factor
+}
+
+// findFactor walks the graph breadth first so the composed factor comes from
+// the path with the fewest hops, which keeps rounding errors to a minimum.
+func (g conversionGraph) findFactor(fromUnitId, toUnitId int) (float64, bool) {
+	if fromUnitId == toUnitId {
+		return 1, true
+	}
+	factors := map[int]float64{fromUnitId: 1}
+	queue := []int{fromUnitId}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.sortedNeighbours(current) {
+			if _, visited := factors[next]; visited {
+				continue
+			}
+			factors[next] = factors[current] * g[current][next]
+			if next == toUnitId {
+				return factors[next], true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return 0, false
+}
+
+func (g conversionGraph) sortedNeighbours(unitId int) []int {
+	neighbours := make([]int, 0, len(g[unitId]))
+	for neighbour := range g[unitId] {
+		neighbours = append(neighbours, neighbour)
+	}
+	sort.Ints(neighbours)
+	return neighbours
+}
+
+func isConsistentFactor(expected, actual float64) bool {
+	if expected == 0 {
+		return actual == 0
+	}
+	return math.Abs(expected-actual)/math.Abs(expected) <= conversionFactorTolerance
+}
@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestConversionGraphResolvesTransitiveConversions(t *testing.T) {
+	kilograms, grams, tablespoon := 1, 2, 3
+	graph := newConversionGraph(map[string]UnitConversion{
+		"2-1": {ToUnitId: intPtr(grams), FromUnitId: intPtr(kilograms), ConversionFactor: 1000},
+		"2-3": {ToUnitId: intPtr(grams), FromUnitId: intPtr(tablespoon), ConversionFactor: 15},
+	})
+	factor, ok := graph.findFactor(kilograms, tablespoon)
+	assert.True(t, ok)
+	assert.InDelta(t, 1000.0/15, factor, 1e-9)
+	factor, ok = graph.findFactor(tablespoon, kilograms)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.015, factor, 1e-9)
+}
+
+func TestConversionGraphMissingPath(t *testing.T) {
+	graph := newConversionGraph(map[string]UnitConversion{
+		"2-1": {ToUnitId: intPtr(2), FromUnitId: intPtr(1), ConversionFactor: 1000},
+	})
+	_, ok := graph.findFactor(1, 3)
+	assert.False(t, ok)
+}
+
+func TestIsConsistentFactor(t *testing.T) {
+	assert.True(t, isConsistentFactor(1.0/15, 0.066667))
+	assert.False(t, isConsistentFactor(1.0/15, 0.67))
+}
@@ -1,9 +1,10 @@
 package unit
 
 type Unit struct {
-	Id     *int   `json:"id,omitempty"`
-	Name   string `json:"name"`
-	Symbol string `json:"symbol"`
+	Id        *int   `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Symbol    string `json:"symbol"`
+	Dimension string `json:"dimension,omitempty"`
 }
 
 type UnitConversion struct {
@@ -13,6 +14,11 @@ type UnitConversion struct {
 	ConversionFactor float64 `json:"conversionFactor"`
 }
 
+type ProductUnitConversion struct {
+	UnitConversion
+	Sku string `json:"sku"`
+}
+
 type UnitConversionInput struct {
 	ToUnitName       string  `json:"toUnitName"`
 	FromUnitName     string  `json:"fromUnitName"`
@@ -23,6 +29,7 @@ type ConvertUnitInput struct {
 	ToUnitId   *int    `json:"toUnitId"`
 	FromUnitId *int    `json:"fromUnitId"`
 	Quantity   float64 `json:"quantity"`
+	Sku        string  `json:"sku,omitempty"`
 }
 
 type ConvertUnitOutput struct {
@@ -30,6 +37,15 @@ type ConvertUnitOutput struct {
 	Quantity float64 `json:"quantity"`
 }
 
+const (
+	DimensionMass   = "mass"
+	DimensionVolume = "volume"
+	DimensionCount  = "count"
+	DimensionLength = "length"
+)
+
+var unitDimensions = []string{DimensionMass, DimensionVolume, DimensionCount, DimensionLength}
+
 const (
 	Grams       = "grams"
 	Kilograms   = "kilograms"
@@ -46,87 +62,79 @@ const (
 
 var initialUnits = []Unit{
 	{
-		Name:   Grams,
-		Symbol: "g",
+		Name:      Grams,
+		Symbol:    "g",
+		Dimension: DimensionMass,
 	},
 	{
-		Name:   Kilograms,
-		Symbol: "kg",
+		Name:      Kilograms,
+		Symbol:    "kg",
+		Dimension: DimensionMass,
 	},
 	{
-		Name:   Milliliters,
-		Symbol: "ml",
+		Name:      Milliliters,
+		Symbol:    "ml",
+		Dimension: DimensionVolume,
 	},
 	{
-		Name:   Liters,
-		Symbol: "L",
+		Name:      Liters,
+		Symbol:    "L",
+		Dimension: DimensionVolume,
 	},
 	{
-		Name:   Piece,
-		Symbol: "pc",
+		Name:      Piece,
+		Symbol:    "pc",
+		Dimension: DimensionCount,
 	},
 	{
-		Name:   Tablespoon,
-		Symbol: "tbsp",
+		Name:      Tablespoon,
+		Symbol:    "tbsp",
+		Dimension: DimensionVolume,
 	},
 	{
-		Name:   Teaspoon,
-		Symbol: "tsp",
+		Name:      Teaspoon,
+		Symbol:    "tsp",
+		Dimension: DimensionVolume,
 	},
 
 	{
-		Name:   Jar,
-		Symbol: "jar",
+		Name:      Jar,
+		Symbol:    "jar",
+		Dimension: DimensionCount,
 	},
 
 	{
-		Name:   Carton,
-		Symbol: "carton",
+		Name:      Carton,
+		Symbol:    "carton",
+		Dimension: DimensionCount,
 	},
 
 	{
-		Name:   Bottle,
-		Symbol: "bottle",
+		Name:      Bottle,
+		Symbol:    "bottle",
+		Dimension: DimensionCount,
 	},
 
 	{
-		Name:   Box,
-		Symbol: "box",
+		Name:      Box,
+		Symbol:    "box",
+		Dimension: DimensionCount,
 	},
 }
 
+// inverse and transitive conversions are derived by the conversion graph,
+// conversions across dimensions are product specific
 var initialConversions = []UnitConversionInput{
 	{
 		ToUnitName:       Kilograms,
 		FromUnitName:     Grams,
 		ConversionFactor: 0.001,
 	},
-	{
-		ToUnitName:       Grams,
-		FromUnitName:     Kilograms,
-		ConversionFactor: 1000,
-	},
 	{
 		ToUnitName:       Liters,
 		FromUnitName:     Milliliters,
 		ConversionFactor: 0.001,
 	},
-	{
-		ToUnitName:       Milliliters,
-		FromUnitName:     Liters,
-		ConversionFactor: 1000,
-	},
-
-	{
-		ToUnitName:       Grams,
-		FromUnitName:     Tablespoon,
-		ConversionFactor: 15,
-	},
-	{
-		ToUnitName:       Grams,
-		FromUnitName:     Teaspoon,
-		ConversionFactor: 5,
-	},
 	{
 		ToUnitName:       Milliliters,
 		FromUnitName:     Tablespoon,
@@ -137,104 +145,4 @@ var initialConversions = []UnitConversionInput{
 		FromUnitName:     Teaspoon,
 		ConversionFactor: 5,
 	},
-	{
-		ToUnitName:       Liters,
-		FromUnitName:     Tablespoon,
-		ConversionFactor: 0.015,
-	},
-	{
-		ToUnitName:       Liters,
-		FromUnitName:     Teaspoon,
-		ConversionFactor: 0.005,
-	},
-	{
-		ToUnitName:       Kilograms,
-		FromUnitName:     Tablespoon,
-		ConversionFactor: 0.015,
-	},
-	{
-		ToUnitName:       Kilograms,
-		FromUnitName:     Teaspoon,
-		ConversionFactor: 0.005,
-	},
-	{
-		ToUnitName:       Tablespoon,
-		FromUnitName:     Grams,
-		ConversionFactor: 0.67,
-	},
-	{
-		ToUnitName:       Teaspoon,
-		FromUnitName:     Grams,
-		ConversionFactor: 0.2,
-	},
-	{
-		ToUnitName:       Tablespoon,
-		FromUnitName:     Milliliters,
-		ConversionFactor: 0.67,
-	},
-	{
-		ToUnitName:       Teaspoon,
-		FromUnitName:     Milliliters,
-		ConversionFactor: 0.2,
-	},
-	{
-		ToUnitName:       Tablespoon,
-		FromUnitName:     Liters,
-		ConversionFactor: 670,
-	},
-	{
-		ToUnitName:       Teaspoon,
-		FromUnitName:     Liters,
-		ConversionFactor: 200,
-	},
-	{
-		ToUnitName:       Tablespoon,
-		FromUnitName:     Kilograms,
-		ConversionFactor: 670,
-	},
-	{
-		ToUnitName:       Teaspoon,
-		FromUnitName:     Kilograms,
-		ConversionFactor: 200,
-	},
-	{
-		ToUnitName:       Liters,
-		FromUnitName:     Kilograms,
-		ConversionFactor: 1,
-	},
-	{
-		ToUnitName:       Kilograms,
-		FromUnitName:     Liters,
-		ConversionFactor: 1,
-	},
-	{
-		ToUnitName:       Milliliters,
-		FromUnitName:     Kilograms,
-		ConversionFactor: 1000,
-	},
-	{
-		ToUnitName:       Kilograms,
-		FromUnitName:     Milliliters,
-		ConversionFactor: 0.001,
-	},
-	{
-		ToUnitName:       Milliliters,
-		FromUnitName:     Grams,
-		ConversionFactor: 1,
-	},
-	{
-		ToUnitName:       Grams,
-		FromUnitName:     Milliliters,
-		ConversionFactor: 1,
-	},
-	{
-		ToUnitName:       Liters,
-		FromUnitName:     Grams,
-		ConversionFactor: 0.001,
-	},
-	{
-		ToUnitName:       Grams,
-		FromUnitName:     Liters,
-		ConversionFactor: 1000,
-	},
 }
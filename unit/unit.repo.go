@@ -19,6 +19,8 @@ type IUnitRepository interface {
 	GetUnitConversionByUnitId(ctx context.Context, toUnitId *int, fromUnitId *int) (UnitConversion, error)
 	TranslateUnit(ctx context.Context, unit Unit, languageCode string) error
 	GetUnitConversions(ctx context.Context) ([]UnitConversion, error)
+	AddProductUnitConversion(ctx context.Context, conversion ProductUnitConversion) error
+	GetProductUnitConversions(ctx context.Context, sku string) ([]UnitConversion, error)
 }
 
 type UnitRepository struct {
@@ -33,7 +35,7 @@ func (r *UnitRepository) CreateUnit(ctx context.Context, unit Unit) (int, error)
 	var id int
 	err := common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
 		var addErr error
-		id, addErr = r.addUnit(ctx)
+		id, addErr = r.addUnit(ctx, unit.Dimension)
 		if addErr != nil {
 			return addErr
 		}
@@ -65,10 +67,10 @@ func (r *UnitRepository) insertTranslation(ctx context.Context, unit Unit, langu
 	return nil
 }
 
-func (r *UnitRepository) addUnit(ctx context.Context) (int, error) {
-	sql := `INSERT INTO units DEFAULT VALUES RETURNING id`
+func (r *UnitRepository) addUnit(ctx context.Context, dimension string) (int, error) {
+	sql := `INSERT INTO units (dimension) VALUES ($1) RETURNING id`
 	op := common.GetOperator(ctx, r.Pool)
-	row := op.QueryRow(ctx, sql)
+	row := op.QueryRow(ctx, sql, dimension)
 	var id int
 	err := row.Scan(&id)
 	if err != nil {
@@ -79,7 +81,7 @@ func (r *UnitRepository) addUnit(ctx context.Context) (int, error) {
 }
 
 func (r *UnitRepository) GetAllUnits(ctx context.Context) ([]Unit, error) {
-	sql := `SELECT u.id, name, symbol FROM units u JOIN unit_translations utx on u.id = utx.unit_id where language_code = $1`
+	sql := `SELECT u.id, name, symbol, dimension FROM units u JOIN unit_translations utx on u.id = utx.unit_id where language_code = $1`
 	languageCode := common.GetLanguageParam(ctx)
 	rows, err := r.Query(ctx, sql, languageCode)
 	if err != nil {
@@ -90,7 +92,7 @@ func (r *UnitRepository) GetAllUnits(ctx context.Context) ([]Unit, error) {
 	units := make([]Unit, 0)
 	for rows.Next() {
 		var unit Unit
-		err := rows.Scan(&unit.Id, &unit.Name, &unit.Symbol)
+		err := rows.Scan(&unit.Id, &unit.Name, &unit.Symbol, &unit.Dimension)
 		if err != nil {
 			common.LoggerFromCtx(ctx).Error("failed to scan unit", zap.Error(err))
 			return nil, common.NewInternalServerError()
@@ -101,11 +103,11 @@ func (r *UnitRepository) GetAllUnits(ctx context.Context) ([]Unit, error) {
 }
 
 func (r *UnitRepository) GetUnitFromName(ctx context.Context, name string) (Unit, error) {
-	sql := `SELECT u.id, name, symbol FROM units u JOIN unit_translations utx on u.id = utx.unit_id WHERE name = $1 and language_code = $2`
+	sql := `SELECT u.id, name, symbol, dimension FROM units u JOIN unit_translations utx on u.id = utx.unit_id WHERE name = $1 and language_code = $2`
 	languageCode := common.GetLanguageParam(ctx)
 	row := r.QueryRow(ctx, sql, name, languageCode)
 	var unit Unit
-	err := row.Scan(&unit.Id, &unit.Name, &unit.Symbol)
+	err := row.Scan(&unit.Id, &unit.Name, &unit.Symbol, &unit.Dimension)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to scan unit", zap.Error(err))
 		return Unit{}, common.NewBadRequestError("Failed to get unit", zimutils.GetErrorCodeFromError(err))
@@ -130,11 +132,11 @@ func (r *UnitRepository) AddUnitConversion(ctx context.Context, conversion UnitC
 }
 
 func (r *UnitRepository) GetUnitById(ctx context.Context, id *int) (Unit, error) {
-	sql := `SELECT u.id, name, symbol FROM units u JOIN unit_translations utx on u.id = utx.unit_id WHERE u.id = $1 AND language_code = $2`
+	sql := `SELECT u.id, name, symbol, dimension FROM units u JOIN unit_translations utx on u.id = utx.unit_id WHERE u.id = $1 AND language_code = $2`
 	languageCode := common.GetLanguageParam(ctx)
 	row := r.QueryRow(ctx, sql, id, languageCode)
 	var unit Unit
-	err := row.Scan(&unit.Id, &unit.Name, &unit.Symbol)
+	err := row.Scan(&unit.Id, &unit.Name, &unit.Symbol, &unit.Dimension)
 	if err != nil {
 		common.LoggerFromCtx(ctx).Error("failed to scan unit", zap.Error(err))
 		return Unit{}, common.NewBadRequestError("Failed to get unit", zimutils.GetErrorCodeFromError(err))
@@ -182,3 +184,39 @@ func (r *UnitRepository) GetUnitConversions(ctx context.Context) ([]UnitConversi
 	}
 	return conversions, nil
 }
+
+func (r *UnitRepository) AddProductUnitConversion(ctx context.Context, conversion ProductUnitConversion) error {
+	sql := `INSERT INTO product_unit_conversions (sku, to_unit_id, from_unit_id, conversion_factor) VALUES ($1, $2, $3, $4)`
+	op := common.GetOperator(ctx, r.Pool)
+	c, err := op.Exec(ctx, sql, conversion.Sku, conversion.ToUnitId, conversion.FromUnitId, conversion.ConversionFactor)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to create product unit conversion", zap.Error(err))
+		return common.NewBadRequestError("Failed to create product unit conversion", zimutils.GetErrorCodeFromError(err))
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewInternalServerError()
+	}
+	return nil
+}
+
+func (r *UnitRepository) GetProductUnitConversions(ctx context.Context, sku string) ([]UnitConversion, error) {
+	sql := `SELECT id, to_unit_id, from_unit_id, conversion_factor FROM product_unit_conversions WHERE sku = $1`
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, sku)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get product unit conversions", zap.Error(err))
+		return nil, common.NewInternalServerError()
+	}
+	defer rows.Close()
+	conversions := make([]UnitConversion, 0)
+	for rows.Next() {
+		var conversion UnitConversion
+		err := rows.Scan(&conversion.Id, &conversion.ToUnitId, &conversion.FromUnitId, &conversion.ConversionFactor)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan product unit conversion", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		conversions = append(conversions, conversion)
+	}
+	return conversions, nil
+}
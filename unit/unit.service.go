@@ -2,9 +2,12 @@ package unit
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"sync"
 
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	zimutils "github.com/nayefradwi/zanobia_inventory_manager/zim_utils"
 	"go.uber.org/zap"
 )
 
@@ -20,28 +23,67 @@ type IUnitService interface {
 	SetupUnitsMap(ctx context.Context) error
 	GetUnitConversionKey(toUnitId int, fromUnitId int) string
 	GetAllUnitConversions(ctx context.Context) map[string]UnitConversion
+	CreateProductConversion(ctx context.Context, conversion ProductUnitConversion) error
+	GetProductConversions(ctx context.Context, sku string) ([]UnitConversion, error)
+	ValidateConvertible(ctx context.Context, sku string, toUnitId int, fromUnitId int) error
+	SubscribeToInvalidation(ctx context.Context)
 }
 
+const unitsInvalidationChannel = "units:invalidate"
+
 type UnitService struct {
-	repo               IUnitRepository
+	repo                IUnitRepository
+	invalidationService common.ICacheInvalidationService
+	// mu guards every cached map below, maps handed out of the lock are never mutated
+	mu                 sync.RWMutex
 	unitConversionsMap map[string]UnitConversion
 	unitsMap           map[int]Unit
+	conversionGraph    conversionGraph
+	resolvedFactorsMap map[string]float64
+	// product conversions are keyed by sku and override the global ones
+	productConversionsMap map[string]map[string]UnitConversion
 }
 
-func (s UnitService) GetUnitConversionKey(toUnitId int, fromUnitId int) string {
+func (s *UnitService) GetUnitConversionKey(toUnitId int, fromUnitId int) string {
 	return strconv.Itoa(toUnitId) + "-" + strconv.Itoa(fromUnitId)
 }
 
-func NewUnitService(repo IUnitRepository) IUnitService {
+func NewUnitService(repo IUnitRepository, invalidationService common.ICacheInvalidationService) IUnitService {
 	return &UnitService{
-		repo:               repo,
-		unitConversionsMap: make(map[string]UnitConversion),
-		unitsMap:           make(map[int]Unit),
+		repo:                  repo,
+		invalidationService:   invalidationService,
+		unitConversionsMap:    make(map[string]UnitConversion),
+		unitsMap:              make(map[int]Unit),
+		resolvedFactorsMap:    make(map[string]float64),
+		productConversionsMap: make(map[string]map[string]UnitConversion),
 	}
 }
 
-func (s UnitService) GetAllUnitConversions(ctx context.Context) map[string]UnitConversion {
-	return s.unitConversionsMap
+func (s *UnitService) GetAllUnitConversions(ctx context.Context) map[string]UnitConversion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	unitConversions := make(map[string]UnitConversion, len(s.unitConversionsMap))
+	for key, conversion := range s.unitConversionsMap {
+		unitConversions[key] = conversion
+	}
+	return unitConversions
+}
+
+func (s *UnitService) SubscribeToInvalidation(ctx context.Context) {
+	s.invalidationService.Subscribe(ctx, unitsInvalidationChannel, func(ctx context.Context) {
+		if err := s.SetupUnitsMap(ctx); err != nil {
+			common.GetLogger().Error("failed to refresh units map", zap.Error(err))
+		}
+		if err := s.SetupUnitConversionsMap(ctx); err != nil {
+			common.GetLogger().Error("failed to refresh unit conversions map", zap.Error(err))
+		}
+	})
+}
+
+func (s *UnitService) publishInvalidation(ctx context.Context) {
+	if err := s.invalidationService.Publish(ctx, unitsInvalidationChannel); err != nil {
+		common.LoggerFromCtx(ctx).Warn("other instances will keep stale units until restart", zap.Error(err))
+	}
 }
 
 func (s *UnitService) CreateUnit(ctx context.Context, unit Unit) error {
@@ -49,6 +91,9 @@ func (s *UnitService) CreateUnit(ctx context.Context, unit Unit) error {
 	if validationErr != nil {
 		return validationErr
 	}
+	if err := ValidateUnitDimension(unit.Dimension); err != nil {
+		return err
+	}
 	id, err := s.repo.CreateUnit(ctx, unit)
 	if err != nil {
 		return err
@@ -58,7 +103,10 @@ func (s *UnitService) CreateUnit(ctx context.Context, unit Unit) error {
 		"adding unit to cached map",
 		zap.String("unit_string", unit.Name),
 	)
+	s.mu.Lock()
 	s.unitsMap[id] = unit
+	s.mu.Unlock()
+	s.publishInvalidation(ctx)
 	return nil
 }
 
@@ -79,7 +127,12 @@ func (s *UnitService) CreateConversion(ctx context.Context, conversion UnitConve
 	if validationErr != nil {
 		return validationErr
 	}
-
+	if err := s.validateConversionDimensions(ctx, conversion); err != nil {
+		return err
+	}
+	if err := s.validateConversionConsistency(ctx, conversion); err != nil {
+		return err
+	}
 	err := s.repo.AddUnitConversion(ctx, conversion)
 	if err != nil {
 		return err
@@ -89,10 +142,109 @@ func (s *UnitService) CreateConversion(ctx context.Context, conversion UnitConve
 		"adding unit conversion to cached map",
 		zap.String("key", key),
 	)
-	s.unitConversionsMap[key] = conversion
+	s.addConversionToMap(conversion)
+	s.publishInvalidation(ctx)
 	return nil
 }
 
+func (s *UnitService) validateConversionDimensions(ctx context.Context, conversion UnitConversion) error {
+	toUnit, err := s.GetUnitById(ctx, conversion.ToUnitId)
+	if err != nil {
+		return err
+	}
+	fromUnit, err := s.GetUnitById(ctx, conversion.FromUnitId)
+	if err != nil {
+		return err
+	}
+	if toUnit.Dimension != fromUnit.Dimension {
+		return common.NewBadRequestFromMessage(
+			fmt.Sprintf("Cannot convert %s to %s globally, add a product conversion instead", fromUnit.Dimension, toUnit.Dimension),
+		)
+	}
+	return nil
+}
+
+// ValidateConvertible makes sure a quantity in fromUnitId can be expressed in toUnitId
+// for the given sku, either through the global conversions or the product ones.
+func (s *UnitService) ValidateConvertible(ctx context.Context, sku string, toUnitId int, fromUnitId int) error {
+	if toUnitId == fromUnitId {
+		return nil
+	}
+	_, ok, err := s.getProductConversionFactor(ctx, sku, toUnitId, fromUnitId)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	toUnit, err := s.GetUnitById(ctx, &toUnitId)
+	if err != nil {
+		return err
+	}
+	fromUnit, err := s.GetUnitById(ctx, &fromUnitId)
+	if err != nil {
+		return err
+	}
+	if toUnit.Dimension != fromUnit.Dimension {
+		return common.NewBadRequestFromMessage(
+			fmt.Sprintf("Cannot convert %s to %s for %s without a product conversion", fromUnit.Name, toUnit.Name, sku),
+		)
+	}
+	return common.NewBadRequestFromMessage(
+		fmt.Sprintf("No conversion defined from %s to %s", fromUnit.Name, toUnit.Name),
+	)
+}
+
+func (s *UnitService) validateConversionConsistency(ctx context.Context, conversion UnitConversion) error {
+	factor, ok := s.getConversionFactor(*conversion.ToUnitId, *conversion.FromUnitId)
+	if !ok || isConsistentFactor(factor, conversion.ConversionFactor) {
+		return nil
+	}
+	common.LoggerFromCtx(ctx).Warn(
+		"rejecting inconsistent unit conversion",
+		zap.Float64("expected_factor", factor),
+		zap.Float64("conversion_factor", conversion.ConversionFactor),
+	)
+	return common.NewBadRequestFromMessage(
+		fmt.Sprintf("Conversion factor conflicts with existing conversions, expected %g", factor),
+	)
+}
+
+func (s *UnitService) addConversionToMap(conversion UnitConversion) {
+	key := s.GetUnitConversionKey(*conversion.ToUnitId, *conversion.FromUnitId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unitConversionsMap[key] = conversion
+	s.resetConversionGraph()
+}
+
+// resetConversionGraph must be called while holding mu
+func (s *UnitService) resetConversionGraph() {
+	s.conversionGraph = nil
+	s.resolvedFactorsMap = make(map[string]float64)
+}
+
+func (s *UnitService) getConversionFactor(toUnitId int, fromUnitId int) (float64, bool) {
+	key := s.GetUnitConversionKey(toUnitId, fromUnitId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if conversion, ok := s.unitConversionsMap[key]; ok {
+		return conversion.ConversionFactor, true
+	}
+	if factor, ok := s.resolvedFactorsMap[key]; ok {
+		return factor, true
+	}
+	if s.conversionGraph == nil {
+		s.conversionGraph = newConversionGraph(s.unitConversionsMap)
+	}
+	factor, ok := s.conversionGraph.findFactor(fromUnitId, toUnitId)
+	if !ok {
+		return 0, false
+	}
+	s.resolvedFactorsMap[key] = factor
+	return factor, true
+}
+
 func (s *UnitService) ConvertUnit(ctx context.Context, input ConvertUnitInput) (ConvertUnitOutput, error) {
 	if (input.ToUnitId == nil && input.FromUnitId == nil) || input.Quantity == 0 {
 		return ConvertUnitOutput{}, common.NewBadRequestFromMessage("Invalid unit conversion input")
@@ -103,6 +255,9 @@ func (s *UnitService) ConvertUnit(ctx context.Context, input ConvertUnitInput) (
 	if *input.ToUnitId == *input.FromUnitId {
 		return s.getSameUnitOutput(ctx, *input.ToUnitId, input.Quantity)
 	}
+	if input.Sku != "" {
+		return s.convertUsingProductConversions(ctx, input)
+	}
 	return s.convertUsingMap(ctx, input)
 }
 
@@ -115,28 +270,167 @@ func (s *UnitService) getSameUnitOutput(ctx context.Context, unitId int, quantit
 }
 
 func (s *UnitService) convertUsingMap(ctx context.Context, input ConvertUnitInput) (ConvertUnitOutput, error) {
-	key := s.GetUnitConversionKey(*input.ToUnitId, *input.FromUnitId)
-	unitConversion, ok := s.unitConversionsMap[key]
+	factor, ok := s.getConversionFactor(*input.ToUnitId, *input.FromUnitId)
 	if !ok {
 		return s.convertUsingDatabase(ctx, input)
 	}
 	common.LoggerFromCtx(ctx).Info("converting using cached unit conversions map")
-	newQty := input.Quantity * unitConversion.ConversionFactor
-	newUnit, err := s.GetUnitById(ctx, unitConversion.ToUnitId)
+	newQty := input.Quantity * factor
+	newUnit, err := s.GetUnitById(ctx, input.ToUnitId)
 	if err != nil {
 		return ConvertUnitOutput{}, err
 	}
 	return ConvertUnitOutput{Unit: newUnit, Quantity: newQty}, nil
 }
 
+func (s *UnitService) convertUsingProductConversions(ctx context.Context, input ConvertUnitInput) (ConvertUnitOutput, error) {
+	factor, ok, err := s.getProductConversionFactor(ctx, input.Sku, *input.ToUnitId, *input.FromUnitId)
+	if err != nil {
+		return ConvertUnitOutput{}, err
+	}
+	if !ok {
+		return s.convertUsingMap(ctx, input)
+	}
+	common.LoggerFromCtx(ctx).Info("converting using product unit conversions", zap.String("sku", input.Sku))
+	newUnit, err := s.GetUnitById(ctx, input.ToUnitId)
+	if err != nil {
+		return ConvertUnitOutput{}, err
+	}
+	return ConvertUnitOutput{Unit: newUnit, Quantity: input.Quantity * factor}, nil
+}
+
+func (s *UnitService) getProductConversionFactor(ctx context.Context, sku string, toUnitId int, fromUnitId int) (float64, bool, error) {
+	productConversions, err := s.getProductConversionsMap(ctx, sku)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(productConversions) == 0 {
+		factor, ok := s.getConversionFactor(toUnitId, fromUnitId)
+		return factor, ok, nil
+	}
+	key := getProductConversionKey(sku, s.GetUnitConversionKey(toUnitId, fromUnitId))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if factor, ok := s.resolvedFactorsMap[key]; ok {
+		return factor, true, nil
+	}
+	graph := newConversionGraph(s.mergeProductConversions(productConversions))
+	factor, ok := graph.findFactor(fromUnitId, toUnitId)
+	if !ok {
+		return 0, false, nil
+	}
+	s.resolvedFactorsMap[key] = factor
+	return factor, true, nil
+}
+
+// mergeProductConversions must be called while holding mu
+func (s *UnitService) mergeProductConversions(productConversions map[string]UnitConversion) map[string]UnitConversion {
+	merged := make(map[string]UnitConversion, len(s.unitConversionsMap)+len(productConversions))
+	for key, conversion := range s.unitConversionsMap {
+		merged[key] = conversion
+	}
+	for key, conversion := range productConversions {
+		// drop the global reverse so the inverse is derived from the product factor
+		delete(merged, s.GetUnitConversionKey(*conversion.FromUnitId, *conversion.ToUnitId))
+		merged[key] = conversion
+	}
+	return merged
+}
+
+func (s *UnitService) getProductConversionsMap(ctx context.Context, sku string) (map[string]UnitConversion, error) {
+	s.mu.RLock()
+	productConversions, ok := s.productConversionsMap[sku]
+	s.mu.RUnlock()
+	if ok {
+		return productConversions, nil
+	}
+	productConversions, err := s.queryProductConversionsMap(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.productConversionsMap[sku] = productConversions
+	s.mu.Unlock()
+	return productConversions, nil
+}
+
+func (s *UnitService) queryProductConversionsMap(ctx context.Context, sku string) (map[string]UnitConversion, error) {
+	conversions, err := s.repo.GetProductUnitConversions(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	productConversions := make(map[string]UnitConversion)
+	for _, conversion := range conversions {
+		key := s.GetUnitConversionKey(*conversion.ToUnitId, *conversion.FromUnitId)
+		productConversions[key] = conversion
+	}
+	return productConversions, nil
+}
+
+func (s *UnitService) CreateProductConversion(ctx context.Context, conversion ProductUnitConversion) error {
+	validationErr := ValidateProductUnitConversion(conversion)
+	if validationErr != nil {
+		return validationErr
+	}
+	if err := s.addProductConversion(ctx, conversion); err != nil {
+		return err
+	}
+	s.publishInvalidation(ctx)
+	return nil
+}
+
+// addProductConversion holds mu from reading the cached conversions until the new one is cached
+// so concurrent requests never validate against the same conversions
+func (s *UnitService) addProductConversion(ctx context.Context, conversion ProductUnitConversion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	productConversions, ok := s.productConversionsMap[conversion.Sku]
+	if !ok {
+		var err error
+		if productConversions, err = s.queryProductConversionsMap(ctx, conversion.Sku); err != nil {
+			return err
+		}
+	}
+	factor, ok := newConversionGraph(productConversions).findFactor(*conversion.FromUnitId, *conversion.ToUnitId)
+	if ok && !isConsistentFactor(factor, conversion.ConversionFactor) {
+		return common.NewBadRequestFromMessage(
+			fmt.Sprintf("Conversion factor conflicts with existing product conversions, expected %g", factor),
+		)
+	}
+	if err := s.repo.AddProductUnitConversion(ctx, conversion); err != nil {
+		return err
+	}
+	key := s.GetUnitConversionKey(*conversion.ToUnitId, *conversion.FromUnitId)
+	common.LoggerFromCtx(ctx).Info(
+		"adding product unit conversion to cached map",
+		zap.String("sku", conversion.Sku),
+		zap.String("key", key),
+	)
+	updatedConversions := make(map[string]UnitConversion, len(productConversions)+1)
+	for existingKey, existing := range productConversions {
+		updatedConversions[existingKey] = existing
+	}
+	updatedConversions[key] = conversion.UnitConversion
+	s.productConversionsMap[conversion.Sku] = updatedConversions
+	s.resetConversionGraph()
+	return nil
+}
+
+func (s *UnitService) GetProductConversions(ctx context.Context, sku string) ([]UnitConversion, error) {
+	return s.repo.GetProductUnitConversions(ctx, sku)
+}
+
+func getProductConversionKey(sku string, key string) string {
+	return sku + ":" + key
+}
+
 func (s *UnitService) convertUsingDatabase(ctx context.Context, input ConvertUnitInput) (ConvertUnitOutput, error) {
 	common.LoggerFromCtx(ctx).Info("converting using database; cache miss")
 	unitConversion, err := s.repo.GetUnitConversionByUnitId(ctx, input.ToUnitId, input.FromUnitId)
 	if err != nil {
 		return ConvertUnitOutput{}, err
 	}
-	key := s.GetUnitConversionKey(*input.ToUnitId, *input.FromUnitId)
-	s.unitConversionsMap[key] = unitConversion
+	s.addConversionToMap(unitConversion)
 	newQty := input.Quantity * unitConversion.ConversionFactor
 	newUnit, err := s.GetUnitById(ctx, unitConversion.ToUnitId)
 	if err != nil {
@@ -152,7 +446,9 @@ func (s *UnitService) GetUnitById(ctx context.Context, id *int) (Unit, error) {
 	if *id == 0 {
 		return Unit{}, common.NewBadRequestFromMessage("Invalid unit id")
 	}
+	s.mu.RLock()
 	unit := s.unitsMap[*id]
+	s.mu.RUnlock()
 	if unit.Id != nil {
 		common.LoggerFromCtx(ctx).Info(
 			"unit found in cached map",
@@ -165,7 +461,9 @@ func (s *UnitService) GetUnitById(ctx context.Context, id *int) (Unit, error) {
 	if err != nil {
 		return Unit{}, err
 	}
+	s.mu.Lock()
 	s.unitsMap[*id] = unit
+	s.mu.Unlock()
 	return unit, nil
 }
 
@@ -179,10 +477,14 @@ func (s *UnitService) SetupUnitConversionsMap(ctx context.Context) error {
 		key := s.GetUnitConversionKey(*unitConversion.ToUnitId, *unitConversion.FromUnitId)
 		unitConversionsMap[key] = unitConversion
 	}
+	s.mu.Lock()
 	s.unitConversionsMap = unitConversionsMap
+	s.productConversionsMap = make(map[string]map[string]UnitConversion)
+	s.resetConversionGraph()
+	s.mu.Unlock()
 	common.LoggerFromCtx(ctx).Info(
 		"unit conversions map setup",
-		zap.Any("unit_conversions_map", s.unitConversionsMap),
+		zap.Any("unit_conversions_map", unitConversionsMap),
 	)
 	return nil
 }
@@ -196,25 +498,39 @@ func (s *UnitService) SetupUnitsMap(ctx context.Context) error {
 	for _, unit := range units {
 		unitsMap[*unit.Id] = unit
 	}
+	s.mu.Lock()
 	s.unitsMap = unitsMap
+	s.mu.Unlock()
 	common.LoggerFromCtx(ctx).Info(
 		"units map setup",
-		zap.Any("units_map", s.unitsMap),
+		zap.Any("units_map", unitsMap),
 	)
 	return nil
 }
 
 func (s *UnitService) InitiateAll(ctx context.Context) error {
-	s.initiateAllUnits(ctx)
-	s.initiateAllUnitConversions(ctx)
-	s.SetupUnitsMap(ctx)
-	s.SetupUnitConversionsMap(ctx)
-	return nil
+	if err := s.initiateAllUnits(ctx); err != nil {
+		return err
+	}
+	if err := s.initiateAllUnitConversions(ctx); err != nil {
+		return err
+	}
+	if err := s.SetupUnitsMap(ctx); err != nil {
+		return err
+	}
+	return s.SetupUnitConversionsMap(ctx)
 }
 
+// seeding can run more than once so records that already exist are not errors
 func (s *UnitService) initiateAllUnits(ctx context.Context) error {
 	for _, unit := range initialUnits {
-		s.CreateUnit(ctx, unit)
+		if _, err := s.repo.GetUnitFromName(ctx, unit.Name); err == nil {
+			continue
+		}
+		if err := s.CreateUnit(ctx, unit); err != nil && !zimutils.IsDuplicateError(err) {
+			common.GetLogger().Error("failed to create initial unit", zap.String("unit", unit.Name), zap.Error(err))
+			return err
+		}
 	}
 	return nil
 }
@@ -224,11 +540,15 @@ func (s *UnitService) initiateAllUnitConversions(ctx context.Context) error {
 		toUnit, err := s.repo.GetUnitFromName(ctx, input.ToUnitName)
 		if err != nil {
 			common.GetLogger().Error("failed to get unit from name", zap.String("unit", input.ToUnitName), zap.Error(err))
-			continue
+			return err
 		}
 		fromUnit, err := s.repo.GetUnitFromName(ctx, input.FromUnitName)
 		if err != nil {
 			common.GetLogger().Error("failed to get unit from name", zap.String("unit", input.FromUnitName), zap.Error(err))
+			return err
+		}
+		// derivable conversions are skipped so seeding can run more than once
+		if _, ok := s.getConversionFactor(*toUnit.Id, *fromUnit.Id); ok {
 			continue
 		}
 		unitConversion := UnitConversion{
@@ -236,7 +556,10 @@ func (s *UnitService) initiateAllUnitConversions(ctx context.Context) error {
 			FromUnitId:       fromUnit.Id,
 			ConversionFactor: input.ConversionFactor,
 		}
-		s.CreateConversion(ctx, unitConversion)
+		if err := s.CreateConversion(ctx, unitConversion); err != nil && !zimutils.IsDuplicateError(err) {
+			common.GetLogger().Error("failed to create initial unit conversion", zap.Error(err))
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowConversionRepository struct {
+	IUnitRepository
+	mu          sync.Mutex
+	conversions []UnitConversion
+}
+
+func (r *slowConversionRepository) GetProductUnitConversions(ctx context.Context, sku string) ([]UnitConversion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]UnitConversion{}, r.conversions...), nil
+}
+
+func (r *slowConversionRepository) AddProductUnitConversion(ctx context.Context, conversion ProductUnitConversion) error {
+	time.Sleep(20 * time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conversions = append(r.conversions, conversion.UnitConversion)
+	return nil
+}
+
+type noopInvalidationService struct{}
+
+func (noopInvalidationService) Publish(ctx context.Context, channel string) error {
+	return nil
+}
+
+func (noopInvalidationService) Subscribe(ctx context.Context, channel string, onInvalidate func(ctx context.Context)) {
+}
+
+func TestCreateProductConversion_RejectsConcurrentConflictingFactors(t *testing.T) {
+	repo := &slowConversionRepository{}
+	service := NewUnitService(repo, noopInvalidationService{})
+	factors := []float64{1000, 10}
+	errs := make([]error, len(factors))
+	var wg sync.WaitGroup
+	for i, factor := range factors {
+		wg.Add(1)
+		go func(i int, factor float64) {
+			defer wg.Done()
+			errs[i] = service.CreateProductConversion(context.Background(), ProductUnitConversion{
+				Sku:            "SKU-000000001",
+				UnitConversion: UnitConversion{ToUnitId: intPtr(2), FromUnitId: intPtr(1), ConversionFactor: factor},
+			})
+		}(i, factor)
+	}
+	wg.Wait()
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+	assert.Len(t, repo.conversions, 1)
+}
+
+type seedUnitRepository struct {
+	IUnitRepository
+	createErr error
+}
+
+func (r *seedUnitRepository) GetUnitFromName(ctx context.Context, name string) (Unit, error) {
+	return Unit{}, common.NewNotFoundError("Unit not found")
+}
+
+func (r *seedUnitRepository) CreateUnit(ctx context.Context, unit Unit) (int, error) {
+	return 0, r.createErr
+}
+
+func TestInitiateAllUnits_IgnoresOnlyDuplicates(t *testing.T) {
+	duplicate := &seedUnitRepository{createErr: common.NewBadRequestError("Failed to translate unit", "DUPLICATE")}
+	assert.NoError(t, NewUnitService(duplicate, noopInvalidationService{}).(*UnitService).initiateAllUnits(context.Background()))
+
+	failing := &seedUnitRepository{createErr: common.NewInternalServerError()}
+	assert.Error(t, NewUnitService(failing, noopInvalidationService{}).(*UnitService).initiateAllUnits(context.Background()))
+}
@@ -3,7 +3,10 @@ package unit
 import "github.com/nayefradwi/zanobia_inventory_manager/common"
 
 func ValidateUnitConversion(conversion UnitConversion) error {
-	if conversion.ToUnitId == conversion.FromUnitId {
+	if conversion.ToUnitId == nil || conversion.FromUnitId == nil {
+		return common.NewBadRequestFromMessage("Unit and conversion unit are required")
+	}
+	if *conversion.ToUnitId == *conversion.FromUnitId {
 		return common.NewBadRequestFromMessage("Unit and conversion unit cannot be the same")
 	}
 	if conversion.ConversionFactor <= 0 {
@@ -12,6 +15,13 @@ func ValidateUnitConversion(conversion UnitConversion) error {
 	return nil
 }
 
+func ValidateProductUnitConversion(conversion ProductUnitConversion) error {
+	if err := common.ValidateStringLength(conversion.Sku, "sku", 10, 36); len(err.Message) > 0 {
+		return common.NewValidationError("invalid product unit conversion input", err)
+	}
+	return ValidateUnitConversion(conversion.UnitConversion)
+}
+
 func ValidateUnit(unitInput Unit) error {
 	validationResults := make([]common.ErrorDetails, 0)
 	validationResults = append(validationResults,
@@ -29,3 +39,15 @@ func ValidateUnit(unitInput Unit) error {
 	}
 	return nil
 }
+
+func ValidateUnitDimension(dimension string) error {
+	for _, unitDimension := range unitDimensions {
+		if dimension == unitDimension {
+			return nil
+		}
+	}
+	return common.NewValidationError("invalid unit input", common.ErrorDetails{
+		Message: "dimension must be one of mass, volume, count or length",
+		Field:   "dimension",
+	})
+}
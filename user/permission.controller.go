@@ -21,15 +21,6 @@ func NewPermissionController(service IPermissionService) PermissionController {
 	}
 }
 
-func (c PermissionController) InitiateInitialPermissions(w http.ResponseWriter, r *http.Request) {
-	err := c.service.InitiateInitialPermissions(r.Context())
-	common.WriteCreatedResponse(common.EmptyResult{
-		Message: "Initial permissions created successfully",
-		Writer:  w,
-		Error:   err,
-	})
-}
-
 func (c PermissionController) CreatePermission(w http.ResponseWriter, r *http.Request) {
 	common.ParseBody[Permission](w, r.Body, func(data Permission) {
 		err := c.service.CreatePermission(r.Context(), data)
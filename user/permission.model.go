@@ -24,6 +24,11 @@ func generateInitialPermissions() []Permission {
 		{Name: "has batch control", Handle: HasBatchControlPermission},
 		{Name: "can delete product", Handle: CanDeleteProductPermission},
 		{Name: "can delete batch", Handle: CanDeleteBatchPermission},
+		{Name: "has retailer control", Handle: HasRetailerControlPermission},
+		{Name: "has retailer batch control", Handle: HasRetailerBatchControlPermission},
+		{Name: "can redate batch", Handle: CanRedateBatchPermission},
+		{Name: "can release batch", Handle: CanReleaseBatchPermission},
+		{Name: "has location control", Handle: HasLocationControlPermission},
 	}
 }
 
@@ -10,12 +10,17 @@ import (
 )
 
 const (
-	SysAdminPermissionHandle    = "sys_admin"
-	HasUserControlPermission    = "has_user_control"
-	HasProductControlPermission = "has_product_control"
-	HasBatchControlPermission   = "has_batch_control"
-	CanDeleteProductPermission  = "can_delete_product"
-	CanDeleteBatchPermission    = "can_delete_batch"
+	SysAdminPermissionHandle          = "sys_admin"
+	HasUserControlPermission          = "has_user_control"
+	HasProductControlPermission       = "has_product_control"
+	HasBatchControlPermission         = "has_batch_control"
+	CanDeleteProductPermission        = "can_delete_product"
+	CanDeleteBatchPermission          = "can_delete_batch"
+	HasRetailerControlPermission      = "has_retailer_control"
+	HasRetailerBatchControlPermission = "has_retailer_batch_control"
+	CanRedateBatchPermission          = "can_redate_batch"
+	CanReleaseBatchPermission         = "can_release_batch"
+	HasLocationControlPermission      = "has_location_control"
 )
 
 type IPermissionRepository interface {
@@ -52,9 +57,12 @@ func (r *PermissionRepository) FindByHandle(ctx context.Context, handle string)
 }
 
 func (r *PermissionRepository) InitiateAll(ctx context.Context, permissions []Permission) error {
-	sql := "INSERT INTO permissions (handle, name, description, is_secret) VALUES ($1, $2, $3, $4)"
+	sql := "INSERT INTO permissions (handle, name, description, is_secret) VALUES ($1, $2, $3, $4) ON CONFLICT (handle) DO NOTHING"
 	for _, p := range permissions {
-		r.Exec(ctx, sql, p.Handle, p.Name, p.Description, p.IsSecret)
+		if _, err := r.Exec(ctx, sql, p.Handle, p.Name, p.Description, p.IsSecret); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to initiate permission", zap.String("handle", p.Handle), zap.Error(err))
+			return common.NewInternalServerError()
+		}
 	}
 	return nil
 }
@@ -21,8 +21,7 @@ func NewPermissionService(repository IPermissionRepository) IPermissionService {
 
 func (s *PermissionService) InitiateInitialPermissions(ctx context.Context) error {
 	permissions := generateInitialPermissions()
-	s.repository.InitiateAll(ctx, permissions)
-	return nil
+	return s.repository.InitiateAll(ctx, permissions)
 }
 
 func (s *PermissionService) CreatePermission(ctx context.Context, permission Permission) error {
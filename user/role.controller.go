@@ -3,9 +3,14 @@ package user
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 )
 
+type RoleAssignmentInput struct {
+	RoleId int `json:"roleId"`
+}
+
 type RoleController struct {
 	service IRoleService
 }
@@ -35,3 +40,60 @@ func (c RoleController) GetRoles(w http.ResponseWriter, r *http.Request) {
 		Error:  err,
 	})
 }
+
+func (c RoleController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	common.ParseBody[Role](w, r.Body, func(role Role) {
+		role.Id = &id
+		err := c.service.UpdateRole(r.Context(), role)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Writer:  w,
+			Message: "role updated successfully",
+			Error:   err,
+		})
+	})
+}
+
+func (c RoleController) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	err := c.service.DeleteRole(r.Context(), id)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Writer:  w,
+		Message: "role deleted successfully",
+		Error:   err,
+	})
+}
+
+func (c RoleController) RemovePermissionFromRole(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	handle := chi.URLParam(r, PermissionHandleParam)
+	err := c.service.RemovePermissionFromRole(r.Context(), id, handle)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Writer:  w,
+		Message: "permission removed from role successfully",
+		Error:   err,
+	})
+}
+
+func (c RoleController) AssignRoleToUser(w http.ResponseWriter, r *http.Request) {
+	userId := common.GetIntURLParam(r, "id")
+	common.ParseBody[RoleAssignmentInput](w, r.Body, func(input RoleAssignmentInput) {
+		err := c.service.AssignRoleToUser(r.Context(), userId, input.RoleId)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Writer:  w,
+			Message: "role assigned successfully",
+			Error:   err,
+		})
+	})
+}
+
+func (c RoleController) UnassignRoleFromUser(w http.ResponseWriter, r *http.Request) {
+	userId := common.GetIntURLParam(r, "id")
+	roleId := common.GetIntURLParam(r, "roleId")
+	err := c.service.UnassignRoleFromUser(r.Context(), userId, roleId)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Writer:  w,
+		Message: "role unassigned successfully",
+		Error:   err,
+	})
+}
@@ -13,6 +13,11 @@ import (
 type IRoleRepository interface {
 	CreateRole(ctx context.Context, role Role) error
 	GetRoles(ctx context.Context) ([]Role, error)
+	UpdateRole(ctx context.Context, role Role) error
+	DeleteRole(ctx context.Context, id int) error
+	RemovePermissionFromRole(ctx context.Context, id int, permissionHandle string) error
+	AssignRoleToUser(ctx context.Context, userId int, roleId int) error
+	UnassignRoleFromUser(ctx context.Context, userId int, roleId int) error
 }
 
 type RoleRepository struct {
@@ -93,3 +98,78 @@ func (r *RoleRepository) GetRoles(ctx context.Context) ([]Role, error) {
 	}
 	return roles, nil
 }
+
+// UpdateRole replaces the name, description and the permissions of the role
+func (r *RoleRepository) UpdateRole(ctx context.Context, role Role) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		sql := "UPDATE roles SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3"
+		c, err := tx.Exec(ctx, sql, role.Name, role.Description, role.Id)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to update role", zap.Error(err))
+			return common.NewBadRequestError("Failed to update role", zimutils.GetErrorCodeFromError(err))
+		}
+		if c.RowsAffected() == 0 {
+			return common.NewNotFoundError("Role not found")
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM role_permissions WHERE role_id = $1", role.Id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to clear role permissions", zap.Error(err))
+			return common.NewInternalServerError()
+		}
+		return r._addPermissionsToRole(ctx, tx, *role.Id, role.PermissionHandles)
+	})
+}
+
+func (r *RoleRepository) DeleteRole(ctx context.Context, id int) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		// role_permissions comes from the baseline schema without ON DELETE CASCADE, unlike user_roles
+		if _, err := tx.Exec(ctx, "DELETE FROM role_permissions WHERE role_id = $1", id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to delete role permissions", zap.Error(err))
+			return common.NewInternalServerError()
+		}
+		c, err := tx.Exec(ctx, "DELETE FROM roles WHERE id = $1", id)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to delete role", zap.Error(err))
+			return common.NewInternalServerError()
+		}
+		if c.RowsAffected() == 0 {
+			return common.NewNotFoundError("Role not found")
+		}
+		return nil
+	})
+}
+
+func (r *RoleRepository) RemovePermissionFromRole(ctx context.Context, id int, permissionHandle string) error {
+	sql := "DELETE FROM role_permissions WHERE role_id = $1 AND permission_handle = $2"
+	c, err := r.Exec(ctx, sql, id, permissionHandle)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to remove permission from role", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("Role does not have this permission")
+	}
+	return nil
+}
+
+func (r *RoleRepository) AssignRoleToUser(ctx context.Context, userId int, roleId int) error {
+	sql := "INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT (user_id, role_id) DO NOTHING"
+	_, err := r.Exec(ctx, sql, userId, roleId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to assign role to user", zap.Error(err))
+		return common.NewBadRequestError("Failed to assign role to user", zimutils.GetErrorCodeFromError(err))
+	}
+	return nil
+}
+
+func (r *RoleRepository) UnassignRoleFromUser(ctx context.Context, userId int, roleId int) error {
+	sql := "DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2"
+	c, err := r.Exec(ctx, sql, userId, roleId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to unassign role from user", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("User does not have this role")
+	}
+	return nil
+}
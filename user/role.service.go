@@ -1,10 +1,19 @@
 package user
 
-import "context"
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
 
 type IRoleService interface {
 	CreateRole(ctx context.Context, role Role) error
 	GetRoles(ctx context.Context) ([]Role, error)
+	UpdateRole(ctx context.Context, role Role) error
+	DeleteRole(ctx context.Context, id int) error
+	RemovePermissionFromRole(ctx context.Context, id int, permissionHandle string) error
+	AssignRoleToUser(ctx context.Context, userId int, roleId int) error
+	UnassignRoleFromUser(ctx context.Context, userId int, roleId int) error
 }
 type RoleService struct {
 	repository IRoleRepository
@@ -31,3 +40,33 @@ func (s *RoleService) CreateRole(ctx context.Context, role Role) error {
 func (s *RoleService) GetRoles(ctx context.Context) ([]Role, error) {
 	return s.repository.GetRoles(ctx)
 }
+
+func (s *RoleService) UpdateRole(ctx context.Context, role Role) error {
+	if role.Id == nil || *role.Id <= 0 {
+		return common.NewBadRequestFromMessage("Invalid role id")
+	}
+	validationErr := ValidateRole(role)
+	if validationErr != nil {
+		return validationErr
+	}
+	return s.repository.UpdateRole(ctx, role)
+}
+
+func (s *RoleService) DeleteRole(ctx context.Context, id int) error {
+	return s.repository.DeleteRole(ctx, id)
+}
+
+func (s *RoleService) RemovePermissionFromRole(ctx context.Context, id int, permissionHandle string) error {
+	return s.repository.RemovePermissionFromRole(ctx, id, permissionHandle)
+}
+
+func (s *RoleService) AssignRoleToUser(ctx context.Context, userId int, roleId int) error {
+	if userId == 0 || roleId == 0 {
+		return common.NewBadRequestFromMessage("Invalid user or role id")
+	}
+	return s.repository.AssignRoleToUser(ctx, userId, roleId)
+}
+
+func (s *RoleService) UnassignRoleFromUser(ctx context.Context, userId int, roleId int) error {
+	return s.repository.UnassignRoleFromUser(ctx, userId, roleId)
+}
@@ -0,0 +1,70 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+type ServiceAccountController struct {
+	service IServiceAccountService
+}
+
+func NewServiceAccountController(service IServiceAccountService) ServiceAccountController {
+	return ServiceAccountController{
+		service: service,
+	}
+}
+
+func (c ServiceAccountController) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[ServiceAccountInput](w, r.Body, func(input ServiceAccountInput) {
+		issuedKey, err := c.service.CreateServiceAccount(r.Context(), input)
+		common.WriteResponse(common.Result[IssuedApiKey]{
+			Writer: w,
+			Error:  err,
+			Data:   issuedKey,
+		})
+	})
+}
+
+func (c ServiceAccountController) GetServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := c.service.GetServiceAccounts(r.Context())
+	common.WriteResponse(common.Result[[]ServiceAccount]{
+		Writer: w,
+		Error:  err,
+		Data:   accounts,
+	})
+}
+
+func (c ServiceAccountController) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	issuedKey, err := c.service.CreateApiKey(r.Context(), id)
+	common.WriteResponse(common.Result[IssuedApiKey]{
+		Writer: w,
+		Error:  err,
+		Data:   issuedKey,
+	})
+}
+
+func (c ServiceAccountController) RotateApiKeys(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	common.ParseBody[ApiKeyRotationInput](w, r.Body, func(input ApiKeyRotationInput) {
+		issuedKey, err := c.service.RotateApiKeys(r.Context(), id, input)
+		common.WriteResponse(common.Result[IssuedApiKey]{
+			Writer: w,
+			Error:  err,
+			Data:   issuedKey,
+		})
+	})
+}
+
+func (c ServiceAccountController) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	id := common.GetIntURLParam(r, "id")
+	keyId := common.GetIntURLParam(r, "keyId")
+	err := c.service.RevokeApiKey(r.Context(), id, keyId)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Writer:  w,
+		Message: "api key revoked successfully",
+		Error:   err,
+	})
+}
@@ -0,0 +1,45 @@
+package user
+
+import "time"
+
+type ServiceAccountInput struct {
+	Name              string   `json:"name"`
+	PermissionHandles []string `json:"permissionHandles"`
+	WarehouseId       *int     `json:"warehouseId,omitempty"`
+	RetailerId        *int     `json:"retailerId,omitempty"`
+}
+
+type ServiceAccount struct {
+	Id       int      `json:"id"`
+	Name     string   `json:"name"`
+	IsActive bool     `json:"isActive"`
+	ApiKeys  []ApiKey `json:"apiKeys"`
+}
+
+type ApiKey struct {
+	Id         int        `json:"id"`
+	Prefix     string     `json:"prefix"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// IssuedApiKey is the only time the plain key is returned, only its hash is stored
+type IssuedApiKey struct {
+	ServiceAccountId int    `json:"serviceAccountId"`
+	Id               int    `json:"id"`
+	Prefix           string `json:"prefix"`
+	Key              string `json:"key"`
+}
+
+type ApiKeyRotationInput struct {
+	// GracePeriodMinutes keeps the previous keys working while integrations switch over
+	GracePeriodMinutes int `json:"gracePeriodMinutes"`
+}
+
+type activeApiKey struct {
+	Id     int
+	UserId int
+	Hash   string
+}
@@ -0,0 +1,178 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	zimutils "github.com/nayefradwi/zanobia_inventory_manager/zim_utils"
+	"go.uber.org/zap"
+)
+
+type IServiceAccountRepository interface {
+	CreateServiceAccount(ctx context.Context, tx pgx.Tx, input ServiceAccountInput) (int, error)
+	GetServiceAccounts(ctx context.Context) ([]ServiceAccount, error)
+	CreateApiKey(ctx context.Context, tx pgx.Tx, serviceAccountId int, prefix, hash string) (int, error)
+	ExpireApiKeys(ctx context.Context, serviceAccountId int, gracePeriod time.Duration, exceptId int) error
+	RevokeApiKey(ctx context.Context, serviceAccountId, keyId int) error
+	GetActiveApiKey(ctx context.Context, prefix string) (activeApiKey, error)
+	TouchApiKey(ctx context.Context, keyId int) error
+}
+
+type ServiceAccountRepository struct {
+	*pgxpool.Pool
+}
+
+func NewServiceAccountRepository(dbPool *pgxpool.Pool) IServiceAccountRepository {
+	return &ServiceAccountRepository{Pool: dbPool}
+}
+
+// CreateServiceAccount runs on the transaction of the caller so the account is only kept together with its first key
+func (r *ServiceAccountRepository) CreateServiceAccount(ctx context.Context, tx pgx.Tx, input ServiceAccountInput) (int, error) {
+	var id int
+	sql := `INSERT INTO users (first_name, last_name, is_active, is_service_account) VALUES ($1, '', true, true) RETURNING id`
+	if err := tx.QueryRow(ctx, sql, input.Name).Scan(&id); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to create service account", zap.Error(err))
+		return 0, common.NewBadRequestError("Failed to create service account", zimutils.GetErrorCodeFromError(err))
+	}
+	permissionSql := "INSERT INTO user_permissions (user_id, permission_handle) VALUES ($1, $2)"
+	for _, handle := range input.PermissionHandles {
+		if _, err := tx.Exec(ctx, permissionSql, id, handle); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to add permission to service account", zap.Error(err))
+			return 0, common.NewBadRequestError("Failed to add permissions to service account", zimutils.GetErrorCodeFromError(err))
+		}
+	}
+	if input.WarehouseId != nil {
+		sql := `INSERT INTO user_warehouses (warehouse_id, user_id) VALUES ($1, $2)`
+		if _, err := tx.Exec(ctx, sql, *input.WarehouseId, id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to bind service account to warehouse", zap.Error(err))
+			return 0, common.NewBadRequestFromMessage("Failed to bind service account to warehouse")
+		}
+	}
+	if input.RetailerId != nil {
+		sql := `INSERT INTO user_retailers (retailer_id, user_id) VALUES ($1, $2)`
+		if _, err := tx.Exec(ctx, sql, *input.RetailerId, id); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to bind service account to retailer", zap.Error(err))
+			return 0, common.NewBadRequestFromMessage("Failed to bind service account to retailer")
+		}
+	}
+	return id, nil
+}
+
+func (r *ServiceAccountRepository) GetServiceAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	sql := `
+	SELECT users.id, users.first_name, users.is_active,
+	api_keys.id, api_keys.prefix, api_keys.last_used_at, api_keys.expires_at, api_keys.revoked_at, api_keys.created_at
+	FROM users
+	left join api_keys on api_keys.user_id = users.id
+	WHERE users.is_service_account = true
+	ORDER BY users.id, api_keys.id
+	`
+	rows, err := r.Query(ctx, sql)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get service accounts", zap.Error(err))
+		return nil, common.NewInternalServerError()
+	}
+	defer rows.Close()
+	accounts := make([]ServiceAccount, 0)
+	for rows.Next() {
+		var account ServiceAccount
+		var keyId *int
+		var prefix *string
+		var createdAt *time.Time
+		var key ApiKey
+		err := rows.Scan(
+			&account.Id, &account.Name, &account.IsActive,
+			&keyId, &prefix, &key.LastUsedAt, &key.ExpiresAt, &key.RevokedAt, &createdAt,
+		)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan service account", zap.Error(err))
+			return nil, common.NewInternalServerError()
+		}
+		if len(accounts) == 0 || accounts[len(accounts)-1].Id != account.Id {
+			account.ApiKeys = make([]ApiKey, 0)
+			accounts = append(accounts, account)
+		}
+		if keyId != nil {
+			key.Id, key.Prefix, key.CreatedAt = *keyId, *prefix, *createdAt
+			last := &accounts[len(accounts)-1]
+			last.ApiKeys = append(last.ApiKeys, key)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *ServiceAccountRepository) CreateApiKey(ctx context.Context, tx pgx.Tx, serviceAccountId int, prefix, hash string) (int, error) {
+	sql := `INSERT INTO api_keys (user_id, prefix, hash)
+	SELECT id, $2, $3 FROM users WHERE id = $1 AND is_service_account = true AND is_active = true
+	RETURNING id`
+	var id int
+	err := tx.QueryRow(ctx, sql, serviceAccountId, prefix, hash).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, common.NewNotFoundError("Service account not found")
+	}
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to create api key", zap.Error(err))
+		return 0, common.NewInternalServerError()
+	}
+	return id, nil
+}
+
+// ExpireApiKeys cuts every other key of the account down to the grace period, keys expiring sooner are left alone
+func (r *ServiceAccountRepository) ExpireApiKeys(ctx context.Context, serviceAccountId int, gracePeriod time.Duration, exceptId int) error {
+	op := common.GetOperator(ctx, r.Pool)
+	sql := `UPDATE api_keys SET expires_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second'
+	WHERE user_id = $1 AND id <> $3 AND revoked_at IS NULL
+	AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP + $2 * INTERVAL '1 second')`
+	if _, err := op.Exec(ctx, sql, serviceAccountId, int(gracePeriod.Seconds()), exceptId); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to expire api keys", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	return nil
+}
+
+func (r *ServiceAccountRepository) RevokeApiKey(ctx context.Context, serviceAccountId, keyId int) error {
+	sql := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	c, err := r.Exec(ctx, sql, keyId, serviceAccountId)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to revoke api key", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("Api key not found")
+	}
+	return nil
+}
+
+func (r *ServiceAccountRepository) GetActiveApiKey(ctx context.Context, prefix string) (activeApiKey, error) {
+	sql := `
+	SELECT api_keys.id, api_keys.user_id, api_keys.hash FROM api_keys
+	join users on users.id = api_keys.user_id
+	WHERE api_keys.prefix = $1 AND api_keys.revoked_at IS NULL
+	AND (api_keys.expires_at IS NULL OR api_keys.expires_at > CURRENT_TIMESTAMP)
+	AND users.is_active = true AND users.is_service_account = true
+	`
+	var key activeApiKey
+	err := r.QueryRow(ctx, sql, prefix).Scan(&key.Id, &key.UserId, &key.Hash)
+	if err == pgx.ErrNoRows {
+		return activeApiKey{}, common.NewUnAuthorizedError("Invalid api key")
+	}
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get api key", zap.Error(err))
+		return activeApiKey{}, common.NewInternalServerError()
+	}
+	return key, nil
+}
+
+// TouchApiKey only writes once a minute so busy integrations don't update the row on every request
+func (r *ServiceAccountRepository) TouchApiKey(ctx context.Context, keyId int) error {
+	sql := `UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`
+	if _, err := r.Exec(ctx, sql, keyId); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to update api key last use", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package user
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"go.uber.org/zap"
+)
+
+type IServiceAccountService interface {
+	CreateServiceAccount(ctx context.Context, input ServiceAccountInput) (IssuedApiKey, error)
+	GetServiceAccounts(ctx context.Context) ([]ServiceAccount, error)
+	CreateApiKey(ctx context.Context, serviceAccountId int) (IssuedApiKey, error)
+	RotateApiKeys(ctx context.Context, serviceAccountId int, input ApiKeyRotationInput) (IssuedApiKey, error)
+	RevokeApiKey(ctx context.Context, serviceAccountId, keyId int) error
+	Authenticate(ctx context.Context, prefix, secret string) (int, error)
+}
+
+type ServiceAccountService struct {
+	repository IServiceAccountRepository
+	db         common.TxBeginner
+}
+
+func NewServiceAccountService(repository IServiceAccountRepository) IServiceAccountService {
+	return &ServiceAccountService{
+		repository: repository,
+		db:         repository.(*ServiceAccountRepository).Pool,
+	}
+}
+
+func (s *ServiceAccountService) CreateServiceAccount(ctx context.Context, input ServiceAccountInput) (IssuedApiKey, error) {
+	if err := ValidateServiceAccount(input); err != nil {
+		return IssuedApiKey{}, err
+	}
+	var issuedKey IssuedApiKey
+	err := common.RunWithTransaction(ctx, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		id, err := s.repository.CreateServiceAccount(ctx, tx, input)
+		if err != nil {
+			return err
+		}
+		issuedKey, err = s.createApiKey(ctx, tx, id)
+		return err
+	})
+	return issuedKey, err
+}
+
+func (s *ServiceAccountService) GetServiceAccounts(ctx context.Context) ([]ServiceAccount, error) {
+	return s.repository.GetServiceAccounts(ctx)
+}
+
+func (s *ServiceAccountService) CreateApiKey(ctx context.Context, serviceAccountId int) (IssuedApiKey, error) {
+	var issuedKey IssuedApiKey
+	err := common.RunWithTransaction(ctx, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		issuedKey, err = s.createApiKey(ctx, tx, serviceAccountId)
+		return err
+	})
+	return issuedKey, err
+}
+
+func (s *ServiceAccountService) createApiKey(ctx context.Context, tx pgx.Tx, serviceAccountId int) (IssuedApiKey, error) {
+	key, prefix, secret, err := common.GenerateApiKey()
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to generate api key", zap.Error(err))
+		return IssuedApiKey{}, common.NewInternalServerError()
+	}
+	hash, err := common.Hash(secret)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to hash api key", zap.Error(err))
+		return IssuedApiKey{}, common.NewInternalServerError()
+	}
+	id, err := s.repository.CreateApiKey(ctx, tx, serviceAccountId, prefix, hash)
+	if err != nil {
+		return IssuedApiKey{}, err
+	}
+	return IssuedApiKey{ServiceAccountId: serviceAccountId, Id: id, Prefix: prefix, Key: key}, nil
+}
+
+// RotateApiKeys issues a new key and lets the previous ones expire after the grace period
+func (s *ServiceAccountService) RotateApiKeys(ctx context.Context, serviceAccountId int, input ApiKeyRotationInput) (IssuedApiKey, error) {
+	if input.GracePeriodMinutes < 0 {
+		return IssuedApiKey{}, common.NewBadRequestFromMessage("grace period can't be negative")
+	}
+	var issuedKey IssuedApiKey
+	err := common.RunWithTransaction(ctx, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		issuedKey, err = s.createApiKey(ctx, tx, serviceAccountId)
+		if err != nil {
+			return err
+		}
+		gracePeriod := time.Duration(input.GracePeriodMinutes) * time.Minute
+		return s.repository.ExpireApiKeys(ctx, serviceAccountId, gracePeriod, issuedKey.Id)
+	})
+	return issuedKey, err
+}
+
+func (s *ServiceAccountService) RevokeApiKey(ctx context.Context, serviceAccountId, keyId int) error {
+	return s.repository.RevokeApiKey(ctx, serviceAccountId, keyId)
+}
+
+func (s *ServiceAccountService) Authenticate(ctx context.Context, prefix, secret string) (int, error) {
+	key, err := s.repository.GetActiveApiKey(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	if !common.CompareHash(secret, key.Hash) {
+		return 0, common.NewUnAuthorizedError("Invalid api key")
+	}
+	// failing to record the last use should not block the integration
+	s.repository.TouchApiKey(ctx, key.Id)
+	return key.UserId, nil
+}
+
+func ValidateServiceAccount(input ServiceAccountInput) error {
+	errors := make([]common.ErrorDetails, 0)
+	if !regexp.MustCompile(`^[A-Za-z0-9 _-]{3,50}$`).MatchString(input.Name) {
+		errors = append(errors, common.ErrorDetails{
+			Message: "service account name must be between 3 and 50 letters, numbers, spaces, dashes or underscores",
+			Field:   "name",
+		})
+	}
+	for _, handle := range input.PermissionHandles {
+		if handle == SysAdminPermissionHandle {
+			errors = append(errors, common.ErrorDetails{
+				Message: "service accounts can't be system admins",
+				Field:   "permissionHandles",
+			})
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid service account input", errors...)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type fakeTxBeginner struct {
+	tx *fakeTx
+}
+
+func (db fakeTxBeginner) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return db.tx, nil
+}
+
+type failingApiKeyRepository struct {
+	IServiceAccountRepository
+	accountTx pgx.Tx
+	apiKeyTx  pgx.Tx
+}
+
+func (r *failingApiKeyRepository) CreateServiceAccount(ctx context.Context, tx pgx.Tx, input ServiceAccountInput) (int, error) {
+	r.accountTx = tx
+	return 7, nil
+}
+
+func (r *failingApiKeyRepository) CreateApiKey(ctx context.Context, tx pgx.Tx, serviceAccountId int, prefix, hash string) (int, error) {
+	r.apiKeyTx = tx
+	return 0, common.NewNotFoundError("Service account not found")
+}
+
+func TestCreateServiceAccount_RollsBackWhenApiKeyFails(t *testing.T) {
+	tx := &fakeTx{}
+	repository := &failingApiKeyRepository{}
+	service := &ServiceAccountService{repository: repository, db: fakeTxBeginner{tx: tx}}
+
+	_, err := service.CreateServiceAccount(context.Background(), ServiceAccountInput{Name: "integration"})
+	assert.Error(t, err)
+	assert.Same(t, tx, repository.accountTx)
+	assert.Same(t, tx, repository.apiKeyTx)
+	assert.False(t, tx.committed)
+	assert.True(t, tx.rolledBack)
+}
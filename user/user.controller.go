@@ -3,6 +3,8 @@ package user
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 )
 
@@ -16,15 +18,6 @@ func NewUserController(service IUserService) UserController {
 	}
 }
 
-func (c UserController) InitiateSysAdminUser(w http.ResponseWriter, r *http.Request) {
-	result := common.EmptyResult{
-		Writer:  w,
-		Message: "System admin user created successfully",
-		Error:   c.service.InitiateSystemAdmin(r.Context()),
-	}
-	common.WriteCreatedResponse(result)
-}
-
 func (c UserController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	common.ParseBody[UserInput](w, r.Body, func(data UserInput) {
 		err := c.service.Create(r.Context(), data)
@@ -56,6 +49,28 @@ func (c UserController) LoginUser(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (c UserController) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[common.RefreshTokenInput](w, r.Body, func(data common.RefreshTokenInput) {
+		token, err := c.service.RefreshToken(r.Context(), data.RefreshToken)
+		common.WriteResponse(common.Result[common.Token]{
+			Writer: w,
+			Data:   token,
+			Error:  err,
+		})
+	})
+}
+
+func (c UserController) Logout(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[common.RefreshTokenInput](w, r.Body, func(data common.RefreshTokenInput) {
+		err := c.service.Logout(r.Context(), data.RefreshToken)
+		common.WriteEmptyResponse(common.EmptyResult{
+			Writer:  w,
+			Error:   err,
+			Message: "Logged out successfully",
+		})
+	})
+}
+
 func (c UserController) GetUserByContext(w http.ResponseWriter, r *http.Request) {
 	user, err := c.service.GetUserByContext(r.Context())
 	common.WriteResponse(common.Result[User]{
@@ -76,3 +91,14 @@ func (c UserController) BanUser(w http.ResponseWriter, r *http.Request) {
 		})
 	})
 }
+
+func (c UserController) RemovePermission(w http.ResponseWriter, r *http.Request) {
+	userId := common.GetIntURLParam(r, "id")
+	handle := chi.URLParam(r, PermissionHandleParam)
+	err := c.service.RemovePermission(r.Context(), userId, handle)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Writer:  w,
+		Error:   err,
+		Message: "Permission removed successfully",
+	})
+}
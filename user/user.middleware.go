@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	"github.com/nayefradwi/zanobia_inventory_manager/warehouse"
 	"go.uber.org/zap"
 )
 
@@ -80,6 +81,99 @@ func (m *UserMiddleware) HasPermissions(permissions ...string) func(next http.Ha
 	return middleware
 }
 
+// HasWarehousePermissions also accepts permissions granted only inside the warehouse of the request,
+// it trusts the warehouse header so it must only be used after RequireWarehouse
+func (m *UserMiddleware) HasWarehousePermissions(permissions ...string) func(next http.Handler) http.Handler {
+	middleware := func(next http.Handler) http.Handler {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user := GetUserFromContext(ctx)
+			if user.Id == 0 {
+				err := common.NewUnAuthorizedError("Invalid user")
+				common.WriteResponseFromError(w, err)
+				return
+			}
+			if user.HasPermission(SysAdminPermissionHandle) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			warehouseId := warehouse.GetWarehouseId(ctx)
+			for _, permission := range permissions {
+				if !user.HasPermissionInWarehouse(permission, warehouseId) {
+					err := common.NewForbiddenError("User does not have permission", permission)
+					common.WriteResponseFromError(w, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+		return handler
+	}
+	return middleware
+}
+
+// RequireWarehouse rejects requests whose warehouse header is missing or belongs to a warehouse the user is not part of
+func (m *UserMiddleware) RequireWarehouse(next http.Handler) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		warehouseId := warehouse.GetWarehouseId(ctx)
+		if warehouseId == 0 {
+			err := common.NewBadRequestError("Missing or invalid "+warehouse.WarehouseIdHeader+" header", "warehouse_required")
+			common.WriteResponseFromError(w, err)
+			return
+		}
+		user := GetUserFromContext(ctx)
+		if user.Id == 0 {
+			err := common.NewUnAuthorizedError("Invalid user")
+			common.WriteResponseFromError(w, err)
+			return
+		}
+		if user.HasPermission(SysAdminPermissionHandle) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := user.GetWarehouse(warehouseId); !ok {
+			err := common.NewForbiddenError("User does not belong to this warehouse", "warehouse_forbidden")
+			common.WriteResponseFromError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	return handler
+}
+
+// RequireRetailerAccess checks the retailer in the id url param, permissions are
+// checked inside that retailer and with none given being assigned to it is enough
+func (m *UserMiddleware) RequireRetailerAccess(permissions ...string) func(next http.Handler) http.Handler {
+	middleware := func(next http.Handler) http.Handler {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user := GetUserFromContext(ctx)
+			if user.Id == 0 {
+				err := common.NewUnAuthorizedError("Invalid user")
+				common.WriteResponseFromError(w, err)
+				return
+			}
+			retailerId := common.GetIntURLParam(r, "id")
+			if !user.CanAccessRetailer(retailerId) {
+				err := common.NewForbiddenError("User does not belong to this retailer", "retailer_forbidden")
+				common.WriteResponseFromError(w, err)
+				return
+			}
+			for _, permission := range permissions {
+				if !user.HasPermissionInRetailer(permission, retailerId) {
+					err := common.NewForbiddenError("User does not have permission", permission)
+					common.WriteResponseFromError(w, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+		return handler
+	}
+	return middleware
+}
+
 func GetUserFromContext(ctx context.Context) User {
 	user := ctx.Value(common.UserKey{})
 	if user != nil {
@@ -18,9 +18,15 @@ type User struct {
 	IsActive    bool                       `json:"isActive"`
 	Hash        *string                    `json:"hash,omitempty"`
 	Warehouses  []warehouse.Warehouse      `json:"warehouses,omitempty"`
+	Retailers   []UserRetailer             `json:"retailers,omitempty"`
 	Permissions map[string]PermissionClaim `json:"permissions,omitempty"`
 }
 
+type UserRetailer struct {
+	RetailerId  int      `json:"retailerId"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
 type UserLoginInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -30,3 +36,76 @@ func (u User) HasPermission(permissionHandle string) bool {
 	_, ok := u.Permissions[permissionHandle]
 	return ok
 }
+
+func (u User) GetWarehouse(warehouseId int) (warehouse.Warehouse, bool) {
+	for _, w := range u.Warehouses {
+		if w.Id != nil && *w.Id == warehouseId {
+			return w, true
+		}
+	}
+	return warehouse.Warehouse{}, false
+}
+
+// CanAccessRetailer is true for managers with global retailer control and for staff assigned to the retailer
+func (u User) CanAccessRetailer(retailerId int) bool {
+	if u.HasPermission(SysAdminPermissionHandle) || u.HasPermission(HasRetailerControlPermission) {
+		return true
+	}
+	_, ok := u.GetRetailer(retailerId)
+	return ok
+}
+
+func (u User) GetRetailer(retailerId int) (UserRetailer, bool) {
+	for _, r := range u.Retailers {
+		if r.RetailerId == retailerId {
+			return r, true
+		}
+	}
+	return UserRetailer{}, false
+}
+
+// HasPermissionInRetailer accepts global retailer control, the permission granted globally or only inside the given retailer
+func (u User) HasPermissionInRetailer(permissionHandle string, retailerId int) bool {
+	if u.HasPermission(SysAdminPermissionHandle) || u.HasPermission(HasRetailerControlPermission) {
+		return true
+	}
+	r, ok := u.GetRetailer(retailerId)
+	if !ok {
+		return false
+	}
+	if u.HasPermission(permissionHandle) {
+		return true
+	}
+	for _, permission := range r.Permissions {
+		if permission == permissionHandle {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExactPermissionInRetailer accepts only the permission itself, granted globally or inside the given retailer
+func (u User) HasExactPermissionInRetailer(permissionHandle string, retailerId int) bool {
+	if u.HasPermission(permissionHandle) {
+		return true
+	}
+	r, ok := u.GetRetailer(retailerId)
+	if !ok {
+		return false
+	}
+	for _, permission := range r.Permissions {
+		if permission == permissionHandle {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermissionInWarehouse accepts permissions granted globally or only inside the given warehouse
+func (u User) HasPermissionInWarehouse(permissionHandle string, warehouseId int) bool {
+	if u.HasPermission(permissionHandle) {
+		return true
+	}
+	w, ok := u.GetWarehouse(warehouseId)
+	return ok && w.HasPermission(permissionHandle)
+}
@@ -16,10 +16,21 @@ type IUserRepository interface {
 	Create(ctx context.Context, user UserInput) error
 	GetAllUsers(ctx context.Context) ([]User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	DoesUserExistWithEmail(ctx context.Context, email string) (bool, error)
 	GetUserById(ctx context.Context, id int) (User, error)
 	BanUser(ctx context.Context, id int) error
+	UpdatePassword(ctx context.Context, email string, hash string) (int, error)
+	RemovePermission(ctx context.Context, userId int, permissionHandle string) error
 }
 
+// effectivePermissionsSql merges the permissions granted directly with the ones granted by roles
+const effectivePermissionsSql = `
+	select user_id, permission_handle from user_permissions
+	union
+	select user_roles.user_id, role_permissions.permission_handle from user_roles
+	join role_permissions on role_permissions.role_id = user_roles.role_id
+	`
+
 type UserRepository struct {
 	*pgxpool.Pool
 }
@@ -95,8 +106,8 @@ func (s *UserRepository) GetUserByEmail(ctx context.Context, email string) (User
 	select users.id, users.email, users.first_name, users.last_name, users.password, 
 	permissions.handle, permissions.name as permission_name, permissions.description,
 	warehouses.id, warehouses.name as warehouse_name, warehouses.lat, warehouses.lng from users
-	left join user_permissions ON user_permissions.user_id = users.id
-	left join permissions ON user_permissions.permission_handle = permissions.handle
+	left join (` + effectivePermissionsSql + `) effective_permissions ON effective_permissions.user_id = users.id
+	left join permissions ON effective_permissions.permission_handle = permissions.handle
 	left join user_warehouses on user_warehouses.user_id = users.id
 	left join warehouses on warehouses.id = user_warehouses.warehouse_id
 	where email = $1 and users.is_active = true;
@@ -107,7 +118,15 @@ func (s *UserRepository) GetUserByEmail(ctx context.Context, email string) (User
 		return User{}, common.NewInternalServerError()
 	}
 	defer rows.Close()
-	return createUserFromRows(rows)
+	user, err := createUserFromRows(rows)
+	if err != nil {
+		return User{}, err
+	}
+	user, err = s.setWarehousePermissions(ctx, user)
+	if err != nil {
+		return User{}, err
+	}
+	return s.setRetailers(ctx, user)
 }
 
 func (s *UserRepository) GetUserById(ctx context.Context, id int) (User, error) {
@@ -115,8 +134,8 @@ func (s *UserRepository) GetUserById(ctx context.Context, id int) (User, error)
 	select users.id, users.email, users.first_name, users.last_name, users.password, 
 	permissions.handle, permissions.name as permission_name, permissions.description,
 	warehouses.id, warehouses.name as warehouse_name, warehouses.lat, warehouses.lng from users
-	left join user_permissions ON user_permissions.user_id = users.id
-	left join permissions ON user_permissions.permission_handle = permissions.handle
+	left join (` + effectivePermissionsSql + `) effective_permissions ON effective_permissions.user_id = users.id
+	left join permissions ON effective_permissions.permission_handle = permissions.handle
 	left join user_warehouses on user_warehouses.user_id = users.id
 	left join warehouses on warehouses.id = user_warehouses.warehouse_id
 	where users.id = $1 and users.is_active = true;
@@ -127,12 +146,84 @@ func (s *UserRepository) GetUserById(ctx context.Context, id int) (User, error)
 		return User{}, common.NewInternalServerError()
 	}
 	defer rows.Close()
-	return createUserFromRows(rows)
+	user, err := createUserFromRows(rows)
+	if err != nil {
+		return User{}, err
+	}
+	user, err = s.setWarehousePermissions(ctx, user)
+	if err != nil {
+		return User{}, err
+	}
+	return s.setRetailers(ctx, user)
+}
+
+func (s *UserRepository) setWarehousePermissions(ctx context.Context, user User) (User, error) {
+	if user.Id == 0 || len(user.Warehouses) == 0 {
+		return user, nil
+	}
+	sql := `SELECT warehouse_id, permission_handle FROM user_warehouse_permissions WHERE user_id = $1`
+	rows, err := s.Query(ctx, sql, user.Id)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get user warehouse permissions", zap.Error(err))
+		return User{}, common.NewInternalServerError()
+	}
+	defer rows.Close()
+	permissions := make(map[int][]string)
+	for rows.Next() {
+		var warehouseId int
+		var handle string
+		if err := rows.Scan(&warehouseId, &handle); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan user warehouse permission", zap.Error(err))
+			return User{}, common.NewInternalServerError()
+		}
+		permissions[warehouseId] = append(permissions[warehouseId], handle)
+	}
+	for i, warehouse := range user.Warehouses {
+		user.Warehouses[i].Permissions = permissions[*warehouse.Id]
+	}
+	return user, nil
+}
+
+func (s *UserRepository) setRetailers(ctx context.Context, user User) (User, error) {
+	if user.Id == 0 {
+		return user, nil
+	}
+	sql := `
+	SELECT ur.retailer_id, urp.permission_handle FROM user_retailers ur
+	left join user_retailer_permissions urp on urp.user_id = ur.user_id and urp.retailer_id = ur.retailer_id
+	WHERE ur.user_id = $1
+	ORDER BY ur.retailer_id
+	`
+	rows, err := s.Query(ctx, sql, user.Id)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to get user retailers", zap.Error(err))
+		return User{}, common.NewInternalServerError()
+	}
+	defer rows.Close()
+	retailers := make([]UserRetailer, 0)
+	for rows.Next() {
+		var retailerId int
+		var handle pgtype.Varchar
+		if err := rows.Scan(&retailerId, &handle); err != nil {
+			common.LoggerFromCtx(ctx).Error("failed to scan user retailer", zap.Error(err))
+			return User{}, common.NewInternalServerError()
+		}
+		if len(retailers) == 0 || retailers[len(retailers)-1].RetailerId != retailerId {
+			retailers = append(retailers, UserRetailer{RetailerId: retailerId})
+		}
+		if handle.Status != pgtype.Null {
+			last := &retailers[len(retailers)-1]
+			last.Permissions = append(last.Permissions, handle.String)
+		}
+	}
+	user.Retailers = retailers
+	return user, nil
 }
 
 func createUserFromRows(rows pgx.Rows) (User, error) {
 	permissionsMap := make(map[string]PermissionClaim)
 	warehousesSlice := make([]warehouse.Warehouse, 0)
+	seenWarehouses := make(map[int]bool)
 	var user User
 	var hash *string
 	for rows.Next() {
@@ -158,8 +249,9 @@ func createUserFromRows(rows pgx.Rows) (User, error) {
 			permissionsMap[permission.Handle] = permission
 		}
 
-		if warehouseId.Status != pgtype.Null {
+		if warehouseId.Status != pgtype.Null && !seenWarehouses[int(warehouseId.Int)] {
 			id := int(warehouseId.Int)
+			seenWarehouses[id] = true
 			warehouse := warehouse.Warehouse{
 				Id:   &id,
 				Name: warehouseName.String,
@@ -176,6 +268,17 @@ func createUserFromRows(rows pgx.Rows) (User, error) {
 	return user, nil
 }
 
+// DoesUserExistWithEmail also finds banned users since the email stays taken
+func (r *UserRepository) DoesUserExistWithEmail(ctx context.Context, email string) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)"
+	var exists bool
+	if err := r.QueryRow(ctx, sql, email).Scan(&exists); err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to check user email", zap.Error(err))
+		return false, common.NewInternalServerError()
+	}
+	return exists, nil
+}
+
 func (r *UserRepository) BanUser(ctx context.Context, id int) error {
 	sql := "UPDATE users SET is_active = false WHERE id = $1"
 	_, err := r.Exec(ctx, sql, id)
@@ -185,3 +288,32 @@ func (r *UserRepository) BanUser(ctx context.Context, id int) error {
 	}
 	return nil
 }
+
+// UpdatePassword returns the id of the user so its tokens can be revoked
+func (r *UserRepository) UpdatePassword(ctx context.Context, email string, hash string) (int, error) {
+	sql := "UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE email = $2 RETURNING id"
+	op := common.GetOperator(ctx, r.Pool)
+	var id int
+	err := op.QueryRow(ctx, sql, hash, email).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, common.NewNotFoundError("User with this email is not found")
+	}
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to update password", zap.Error(err))
+		return 0, common.NewInternalServerError()
+	}
+	return id, nil
+}
+
+func (r *UserRepository) RemovePermission(ctx context.Context, userId int, permissionHandle string) error {
+	sql := "DELETE FROM user_permissions WHERE user_id = $1 AND permission_handle = $2"
+	c, err := r.Exec(ctx, sql, userId, permissionHandle)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to remove permission from user", zap.Error(err))
+		return common.NewInternalServerError()
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("User does not have this permission")
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 	"go.uber.org/zap"
 )
@@ -10,17 +11,23 @@ import (
 type IUserService interface {
 	Create(ctx context.Context, user UserInput) error
 	InitiateSystemAdmin(ctx context.Context) error
+	CreateSystemAdmin(ctx context.Context, user UserInput) error
+	ResetPassword(ctx context.Context, email string, password string) error
 	GetAllUsers(ctx context.Context) ([]User, error)
 	LoginUser(ctx context.Context, input UserLoginInput) (common.Token, error)
+	RefreshToken(ctx context.Context, refreshToken string) (common.Token, error)
+	Logout(ctx context.Context, refreshToken string) error
 	GetUserById(ctx context.Context, id int) (User, error)
 	GetUserByContext(ctx context.Context) (User, error)
 	BanUser(ctx context.Context, id int) error
+	RemovePermission(ctx context.Context, userId int, permissionHandle string) error
 }
 
 type UserServiceInput struct {
-	Repository       IUserRepository
-	SysAdminEmail    string
-	SysAdminPassword string
+	Repository             IUserRepository
+	TokenRevocationService common.ITokenRevocationService
+	SysAdminEmail          string
+	SysAdminPassword       string
 }
 
 type UserService struct {
@@ -51,15 +58,47 @@ func (s *UserService) Create(ctx context.Context, user UserInput) error {
 	return nil
 }
 
+// InitiateSystemAdmin creates the configured system admin unless it already exists
 func (s *UserService) InitiateSystemAdmin(ctx context.Context) error {
+	exists, err := s.Repository.DoesUserExistWithEmail(ctx, s.SysAdminEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		common.LoggerFromCtx(ctx).Info("system admin already exists", zap.String("email", s.SysAdminEmail))
+		return nil
+	}
 	userInput := UserInput{
-		Email:             s.SysAdminEmail,
-		Password:          s.SysAdminPassword,
-		FirstName:         "System",
-		LastName:          "Admin",
-		PermissionHandles: []string{SysAdminPermissionHandle},
+		Email:     s.SysAdminEmail,
+		Password:  s.SysAdminPassword,
+		FirstName: "System",
+		LastName:  "Admin",
+	}
+	return s.CreateSystemAdmin(ctx, userInput)
+}
+
+func (s *UserService) CreateSystemAdmin(ctx context.Context, user UserInput) error {
+	user.PermissionHandles = []string{SysAdminPermissionHandle}
+	return s.Create(ctx, user)
+}
+
+func (s *UserService) ResetPassword(ctx context.Context, email string, password string) error {
+	if validationResult := ValidatePassword(password); len(validationResult.Message) > 0 {
+		return common.NewValidationError("invalid password", validationResult)
+	}
+	hashPassword, hashError := common.Hash(password)
+	if hashError != nil {
+		common.LoggerFromCtx(ctx).Error("failed to hash", zap.Error(hashError))
+		return common.NewInternalServerError()
 	}
-	return s.Create(ctx, userInput)
+	// the old tokens are revoked in the same transaction so the password is never changed while they stay valid
+	return common.RunWithTransaction(ctx, s.Repository.(*UserRepository).Pool, func(ctx context.Context, tx pgx.Tx) error {
+		userId, err := s.Repository.UpdatePassword(ctx, email, hashPassword)
+		if err != nil {
+			return err
+		}
+		return s.TokenRevocationService.RevokeAllForUser(ctx, userId)
+	})
 }
 
 func (s *UserService) GetAllUsers(ctx context.Context) ([]User, error) {
@@ -77,6 +116,64 @@ func (s *UserService) LoginUser(ctx context.Context, input UserLoginInput) (comm
 	if !match {
 		return common.Token{}, common.NewBadRequestError("Password is incorrect", "password_incorrect")
 	}
+	return s.generateUserToken(ctx, user)
+}
+
+// RefreshToken rotates the refresh token, using an already rotated one revokes every token of the user
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (common.Token, error) {
+	tokenInfo, err := common.DecodeRefreshToken(refreshToken)
+	if err != nil {
+		return common.Token{}, err
+	}
+	revoked, err := s.TokenRevocationService.IsRevoked(ctx, tokenInfo)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("failed to check refresh token", zap.Error(err))
+		return common.Token{}, common.NewInternalServerError()
+	}
+	if !revoked {
+		isFirstUse, err := s.TokenRevocationService.Revoke(ctx, tokenInfo)
+		if err != nil {
+			return common.Token{}, err
+		}
+		revoked = !isFirstUse
+	}
+	if revoked {
+		common.LoggerFromCtx(ctx).Warn("refresh token reused, revoking all tokens", zap.Int("userId", tokenInfo.UserId))
+		if err := s.TokenRevocationService.RevokeAllForUser(ctx, tokenInfo.UserId); err != nil {
+			return common.Token{}, err
+		}
+		return common.Token{}, common.NewUnAuthorizedError("Invalid refresh token")
+	}
+	user, err := s.Repository.GetUserById(ctx, tokenInfo.UserId)
+	if err != nil {
+		return common.Token{}, err
+	}
+	if user.Id == 0 {
+		return common.Token{}, common.NewUnAuthorizedError("Invalid user")
+	}
+	return s.generateUserToken(ctx, user)
+}
+
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	accessTokenInfo, ok := common.GetTokenInfo(common.GetClaimsFromContext(ctx))
+	if !ok {
+		return common.NewUnAuthorizedError("Invalid token")
+	}
+	if _, err := s.TokenRevocationService.Revoke(ctx, accessTokenInfo); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+	refreshTokenInfo, err := common.DecodeRefreshToken(refreshToken)
+	if err != nil || refreshTokenInfo.UserId != accessTokenInfo.UserId {
+		return common.NewBadRequestFromMessage("Invalid refresh token")
+	}
+	_, err = s.TokenRevocationService.Revoke(ctx, refreshTokenInfo)
+	return err
+}
+
+func (s *UserService) generateUserToken(ctx context.Context, user User) (common.Token, error) {
 	user.Hash = nil
 	user.Email = nil
 	userClaim, err := common.StructToMap(user)
@@ -112,5 +209,16 @@ func (s *UserService) BanUser(ctx context.Context, id int) error {
 	if currentUser.Id == id {
 		return common.NewBadRequestError("You can't ban yourself", "ban_self")
 	}
-	return s.Repository.BanUser(ctx, id)
+	if err := s.Repository.BanUser(ctx, id); err != nil {
+		return err
+	}
+	return s.TokenRevocationService.RevokeAllForUser(ctx, id)
+}
+
+func (s *UserService) RemovePermission(ctx context.Context, userId int, permissionHandle string) error {
+	currentUser := GetUserFromContext(ctx)
+	if currentUser.Id == userId && permissionHandle == SysAdminPermissionHandle {
+		return common.NewBadRequestError("You can't remove your own admin permission", "remove_own_admin")
+	}
+	return s.Repository.RemovePermission(ctx, userId, permissionHandle)
 }
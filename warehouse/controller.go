@@ -3,6 +3,8 @@ package warehouse
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 )
 
@@ -66,3 +68,26 @@ func (c WarehouseController) UpdateWarehouse(w http.ResponseWriter, r *http.Requ
 		})
 	})
 }
+
+func (c WarehouseController) AddWarehousePermissions(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[WarehousePermissionInput](w, r.Body, func(input WarehousePermissionInput) {
+		err := c.service.AddWarehousePermissions(r.Context(), input)
+		common.WriteCreatedResponse(common.EmptyResult{
+			Error:   err,
+			Message: "warehouse permissions added successfully",
+			Writer:  w,
+		})
+	})
+}
+
+func (c WarehouseController) RemoveWarehousePermission(w http.ResponseWriter, r *http.Request) {
+	warehouseId := common.GetIntURLParam(r, "id")
+	userId := common.GetIntURLParam(r, "userId")
+	handle := chi.URLParam(r, "permissionHandle")
+	err := c.service.RemoveWarehousePermission(r.Context(), warehouseId, userId, handle)
+	common.WriteEmptyResponse(common.EmptyResult{
+		Error:   err,
+		Message: "warehouse permission removed successfully",
+		Writer:  w,
+	})
+}
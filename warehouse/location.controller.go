@@ -0,0 +1,33 @@
+package warehouse
+
+import (
+	"net/http"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+func (c WarehouseController) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	common.ParseBody[WarehouseLocationInput](w, r.Body, func(input WarehouseLocationInput) {
+		id, err := c.service.CreateLocation(r.Context(), input)
+		common.WriteResponse(common.Result[WarehouseLocation]{
+			Error:  err,
+			Writer: w,
+			Data: WarehouseLocation{
+				Id:               &id,
+				ParentId:         input.ParentId,
+				Kind:             input.Kind,
+				Code:             input.Code,
+				TemperatureClass: input.TemperatureClass,
+			},
+		})
+	})
+}
+
+func (c WarehouseController) GetLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := c.service.GetLocations(r.Context())
+	common.WriteResponse(common.Result[[]WarehouseLocation]{
+		Error:  err,
+		Writer: w,
+		Data:   locations,
+	})
+}
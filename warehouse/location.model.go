@@ -0,0 +1,86 @@
+package warehouse
+
+const (
+	LocationKindZone  = "zone"
+	LocationKindAisle = "aisle"
+	LocationKindBin   = "bin"
+)
+
+const (
+	TemperatureClassAmbient = "ambient"
+	TemperatureClassChilled = "chilled"
+	TemperatureClassFrozen  = "frozen"
+)
+
+// locationParentKinds is the kind a location has to be placed under, zones have no parent
+var locationParentKinds = map[string]string{
+	LocationKindAisle: LocationKindZone,
+	LocationKindBin:   LocationKindAisle,
+}
+
+type WarehouseLocation struct {
+	Id               *int                `json:"id,omitempty"`
+	ParentId         *int                `json:"parentId,omitempty"`
+	Kind             string              `json:"kind"`
+	Code             string              `json:"code"`
+	TemperatureClass *string             `json:"temperatureClass,omitempty"`
+	Children         []WarehouseLocation `json:"children,omitempty"`
+}
+
+type WarehouseLocationInput struct {
+	ParentId         *int    `json:"parentId,omitempty"`
+	Kind             string  `json:"kind"`
+	Code             string  `json:"code"`
+	TemperatureClass *string `json:"temperatureClass,omitempty"`
+}
+
+func IsTemperatureClass(value string) bool {
+	switch value {
+	case TemperatureClassAmbient, TemperatureClassChilled, TemperatureClassFrozen:
+		return true
+	}
+	return false
+}
+
+// GetLocationTemperatureClass takes the path of a location starting from the location itself,
+// the closest class set on the path applies and locations without one are ambient
+func GetLocationTemperatureClass(path []WarehouseLocation) string {
+	for _, location := range path {
+		if location.TemperatureClass != nil {
+			return *location.TemperatureClass
+		}
+	}
+	return TemperatureClassAmbient
+}
+
+// CanStoreInLocation only allows goods into a location of the exact class they are kept at,
+// frozen goods thaw in a chiller and chilled goods freeze in a freezer
+func CanStoreInLocation(storageClass string, path []WarehouseLocation) bool {
+	if storageClass == "" {
+		storageClass = TemperatureClassAmbient
+	}
+	return GetLocationTemperatureClass(path) == storageClass
+}
+
+// buildLocationTree nests the locations under their parents the same way categories are nested
+func buildLocationTree(locations []WarehouseLocation) []WarehouseLocation {
+	childrenLookup := make(map[int][]WarehouseLocation)
+	roots := make([]WarehouseLocation, 0)
+	for _, location := range locations {
+		if location.ParentId == nil {
+			roots = append(roots, location)
+			continue
+		}
+		childrenLookup[*location.ParentId] = append(childrenLookup[*location.ParentId], location)
+	}
+	return attachLocationChildren(roots, childrenLookup)
+}
+
+func attachLocationChildren(locations []WarehouseLocation, childrenLookup map[int][]WarehouseLocation) []WarehouseLocation {
+	for i, location := range locations {
+		if children, ok := childrenLookup[*location.Id]; ok {
+			locations[i].Children = attachLocationChildren(children, childrenLookup)
+		}
+	}
+	return locations
+}
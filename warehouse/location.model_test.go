@@ -0,0 +1,48 @@
+package warehouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLocationTemperatureClass(t *testing.T) {
+	frozen, chilled := TemperatureClassFrozen, TemperatureClassChilled
+	bin := WarehouseLocation{Kind: LocationKindBin}
+	aisle := WarehouseLocation{Kind: LocationKindAisle, TemperatureClass: &frozen}
+	zone := WarehouseLocation{Kind: LocationKindZone, TemperatureClass: &chilled}
+	assert.Equal(t, TemperatureClassFrozen, GetLocationTemperatureClass([]WarehouseLocation{bin, aisle, zone}))
+	assert.Equal(t, TemperatureClassChilled, GetLocationTemperatureClass([]WarehouseLocation{bin, zone}))
+	assert.Equal(t, TemperatureClassAmbient, GetLocationTemperatureClass([]WarehouseLocation{bin}))
+}
+
+func TestCanStoreInLocation(t *testing.T) {
+	chilled := TemperatureClassChilled
+	path := []WarehouseLocation{{Kind: LocationKindBin}, {Kind: LocationKindZone, TemperatureClass: &chilled}}
+	assert.True(t, CanStoreInLocation(TemperatureClassChilled, path))
+	assert.False(t, CanStoreInLocation(TemperatureClassFrozen, path))
+	assert.False(t, CanStoreInLocation(TemperatureClassAmbient, path))
+	assert.True(t, CanStoreInLocation("", []WarehouseLocation{{Kind: LocationKindBin}}))
+}
+
+func TestValidateWarehouseLocationInput(t *testing.T) {
+	parentId := 1
+	assert.NoError(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: LocationKindZone, Code: "A"}))
+	assert.NoError(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: LocationKindBin, Code: "A-01-01", ParentId: &parentId}))
+	assert.Error(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: LocationKindZone, Code: "A", ParentId: &parentId}))
+	assert.Error(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: LocationKindAisle, Code: "A-01"}))
+	assert.Error(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: "shelf", Code: "A-01"}))
+	hot := "hot"
+	assert.Error(t, ValidateWarehouseLocationInput(WarehouseLocationInput{Kind: LocationKindZone, Code: "A", TemperatureClass: &hot}))
+}
+
+func TestBuildLocationTree(t *testing.T) {
+	zoneId, aisleId, binId := 1, 2, 3
+	tree := buildLocationTree([]WarehouseLocation{
+		{Id: &binId, ParentId: &aisleId, Kind: LocationKindBin},
+		{Id: &zoneId, Kind: LocationKindZone},
+		{Id: &aisleId, ParentId: &zoneId, Kind: LocationKindAisle},
+	})
+	assert.Len(t, tree, 1)
+	assert.Equal(t, binId, *tree[0].Children[0].Children[0].Id)
+}
@@ -0,0 +1,110 @@
+package warehouse
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+	zimutils "github.com/nayefradwi/zanobia_inventory_manager/zim_utils"
+	"go.uber.org/zap"
+)
+
+// locationPathSql selects a location and its ancestors starting from the location, $1 is the location
+const locationPathSql = `
+with recursive path as (
+	select id, parent_id, kind, code, temperature_class, warehouse_id, 0 as depth
+	from warehouse_locations where id = $1
+	union all
+	select l.id, l.parent_id, l.kind, l.code, l.temperature_class, l.warehouse_id, p.depth + 1
+	from warehouse_locations l join path p on l.id = p.parent_id
+)
+select id, parent_id, kind, code, temperature_class from path where warehouse_id = $2 order by depth
+`
+
+// locationSubtreeSql selects the ids of a location and everything placed in it, $1 is the root
+const locationSubtreeSql = `
+with recursive subtree as (
+	select id from warehouse_locations where id = $1 and warehouse_id = $2
+	union all
+	select l.id from warehouse_locations l join subtree s on l.parent_id = s.id
+)
+select id from subtree
+`
+
+func (r *WarehouseRepository) CreateLocation(ctx context.Context, input WarehouseLocationInput) (int, error) {
+	sql := `
+	INSERT INTO warehouse_locations (warehouse_id, parent_id, kind, code, temperature_class)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	op := common.GetOperator(ctx, r.Pool)
+	var id int
+	err := op.QueryRow(ctx, sql, GetWarehouseId(ctx), input.ParentId, input.Kind, input.Code, input.TemperatureClass).Scan(&id)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to create location", zap.Error(err))
+		return 0, common.NewBadRequestError("Failed to create location", zimutils.GetErrorCodeFromError(err))
+	}
+	return id, nil
+}
+
+func (r *WarehouseRepository) GetLocations(ctx context.Context) ([]WarehouseLocation, error) {
+	sql := `
+	SELECT id, parent_id, kind, code, temperature_class FROM warehouse_locations
+	WHERE warehouse_id = $1 ORDER BY code
+	`
+	return r.queryLocations(ctx, sql, GetWarehouseId(ctx))
+}
+
+// GetLocationPath returns the location followed by its parents up to the zone
+func (r *WarehouseRepository) GetLocationPath(ctx context.Context, id int) ([]WarehouseLocation, error) {
+	path, err := r.queryLocations(ctx, locationPathSql, id, GetWarehouseId(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, common.NewNotFoundError("location not found")
+	}
+	return path, nil
+}
+
+func (r *WarehouseRepository) GetLocationSubtreeIds(ctx context.Context, id int) ([]int, error) {
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, locationSubtreeSql, id, GetWarehouseId(ctx))
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to get location subtree", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get locations")
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var locationId int
+		if err := rows.Scan(&locationId); err != nil {
+			common.LoggerFromCtx(ctx).Error("Failed to scan location id", zap.Error(err))
+			return nil, common.NewBadRequestFromMessage("Failed to get locations")
+		}
+		ids = append(ids, locationId)
+	}
+	if len(ids) == 0 {
+		return nil, common.NewNotFoundError("location not found")
+	}
+	return ids, nil
+}
+
+func (r *WarehouseRepository) queryLocations(ctx context.Context, sql string, args ...interface{}) ([]WarehouseLocation, error) {
+	op := common.GetOperator(ctx, r.Pool)
+	rows, err := op.Query(ctx, sql, args...)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to get locations", zap.Error(err))
+		return nil, common.NewBadRequestFromMessage("Failed to get locations")
+	}
+	defer rows.Close()
+	locations := make([]WarehouseLocation, 0)
+	for rows.Next() {
+		var location WarehouseLocation
+		err := rows.Scan(&location.Id, &location.ParentId, &location.Kind, &location.Code, &location.TemperatureClass)
+		if err != nil {
+			common.LoggerFromCtx(ctx).Error("Failed to scan location", zap.Error(err))
+			return nil, common.NewBadRequestFromMessage("Failed to get locations")
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
@@ -0,0 +1,40 @@
+package warehouse
+
+import (
+	"context"
+
+	"github.com/nayefradwi/zanobia_inventory_manager/common"
+)
+
+func (s *WarehouseService) CreateLocation(ctx context.Context, input WarehouseLocationInput) (int, error) {
+	if err := ValidateWarehouseLocationInput(input); err != nil {
+		return 0, err
+	}
+	if input.ParentId != nil {
+		path, err := s.repo.GetLocationPath(ctx, *input.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		if parentKind := locationParentKinds[input.Kind]; path[0].Kind != parentKind {
+			return 0, common.NewBadRequestError("a "+input.Kind+" has to be placed in a "+parentKind, "invalid_location_parent")
+		}
+	}
+	return s.repo.CreateLocation(ctx, input)
+}
+
+func (s *WarehouseService) GetLocations(ctx context.Context) ([]WarehouseLocation, error) {
+	locations, err := s.repo.GetLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildLocationTree(locations), nil
+}
+
+// GetLocationSubtreeIds returns the location with every location placed in it, used to filter by a zone or aisle
+func (s *WarehouseService) GetLocationSubtreeIds(ctx context.Context, id int) ([]int, error) {
+	return s.repo.GetLocationSubtreeIds(ctx, id)
+}
+
+func (s *WarehouseService) GetLocationPath(ctx context.Context, id int) ([]WarehouseLocation, error) {
+	return s.repo.GetLocationPath(ctx, id)
+}
@@ -1,13 +1,30 @@
 package warehouse
 
 type Warehouse struct {
-	Id   *int     `json:"id,omitempty"`
-	Name string   `json:"name"`
-	Lat  *float64 `json:"lat"`
-	Lng  *float64 `json:"lng"`
+	Id          *int     `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Lat         *float64 `json:"lat"`
+	Lng         *float64 `json:"lng"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// HasPermission only covers permissions granted inside this warehouse
+func (w Warehouse) HasPermission(permissionHandle string) bool {
+	for _, permission := range w.Permissions {
+		if permission == permissionHandle {
+			return true
+		}
+	}
+	return false
 }
 
 type WarehouseUserInput struct {
 	WarehouseId int `json:"warehouseId"`
 	UserId      int `json:"userId"`
 }
+
+type WarehousePermissionInput struct {
+	WarehouseId       int      `json:"warehouseId"`
+	UserId            int      `json:"userId"`
+	PermissionHandles []string `json:"permissionHandles"`
+}
@@ -3,6 +3,7 @@ package warehouse
 import (
 	"context"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/nayefradwi/zanobia_inventory_manager/common"
 	"go.uber.org/zap"
@@ -14,6 +15,12 @@ type IWarehouseRepository interface {
 	AddUserToWarehouse(ctx context.Context, input WarehouseUserInput) error
 	GetWarehouseById(ctx context.Context, warehouseId, userId int) (Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse Warehouse) error
+	AddWarehousePermissions(ctx context.Context, input WarehousePermissionInput) error
+	RemoveWarehousePermission(ctx context.Context, warehouseId, userId int, permissionHandle string) error
+	CreateLocation(ctx context.Context, input WarehouseLocationInput) (int, error)
+	GetLocations(ctx context.Context) ([]WarehouseLocation, error)
+	GetLocationPath(ctx context.Context, id int) ([]WarehouseLocation, error)
+	GetLocationSubtreeIds(ctx context.Context, id int) ([]int, error)
 }
 
 type WarehouseRepository struct {
@@ -97,3 +104,30 @@ func (r *WarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse War
 	}
 	return nil
 }
+
+func (r *WarehouseRepository) AddWarehousePermissions(ctx context.Context, input WarehousePermissionInput) error {
+	return common.RunWithTransaction(ctx, r.Pool, func(ctx context.Context, tx pgx.Tx) error {
+		sql := `INSERT INTO user_warehouse_permissions (user_id, warehouse_id, permission_handle) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, warehouse_id, permission_handle) DO NOTHING`
+		for _, handle := range input.PermissionHandles {
+			if _, err := tx.Exec(ctx, sql, input.UserId, input.WarehouseId, handle); err != nil {
+				common.LoggerFromCtx(ctx).Error("Failed to add warehouse permission", zap.Error(err))
+				return common.NewBadRequestFromMessage("Failed to add warehouse permissions, the user must belong to the warehouse")
+			}
+		}
+		return nil
+	})
+}
+
+func (r *WarehouseRepository) RemoveWarehousePermission(ctx context.Context, warehouseId, userId int, permissionHandle string) error {
+	sql := `DELETE FROM user_warehouse_permissions WHERE warehouse_id = $1 AND user_id = $2 AND permission_handle = $3`
+	c, err := r.Exec(ctx, sql, warehouseId, userId, permissionHandle)
+	if err != nil {
+		common.LoggerFromCtx(ctx).Error("Failed to remove warehouse permission", zap.Error(err))
+		return common.NewBadRequestFromMessage("Failed to remove warehouse permission")
+	}
+	if c.RowsAffected() == 0 {
+		return common.NewNotFoundError("User does not have this permission in the warehouse")
+	}
+	return nil
+}
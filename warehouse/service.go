@@ -13,6 +13,12 @@ type IWarehouseService interface {
 	GetMyCurrentWarehouse(ctx context.Context) (Warehouse, error)
 	GetWarehouseById(ctx context.Context, warehouseId, userId int) (Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse Warehouse) error
+	AddWarehousePermissions(ctx context.Context, input WarehousePermissionInput) error
+	RemoveWarehousePermission(ctx context.Context, warehouseId, userId int, permissionHandle string) error
+	CreateLocation(ctx context.Context, input WarehouseLocationInput) (int, error)
+	GetLocations(ctx context.Context) ([]WarehouseLocation, error)
+	GetLocationPath(ctx context.Context, id int) ([]WarehouseLocation, error)
+	GetLocationSubtreeIds(ctx context.Context, id int) ([]int, error)
 }
 
 type WarehouseService struct {
@@ -57,3 +63,14 @@ func (s *WarehouseService) UpdateWarehouse(ctx context.Context, warehouse Wareho
 	}
 	return s.repo.UpdateWarehouse(ctx, warehouse)
 }
+
+func (s *WarehouseService) AddWarehousePermissions(ctx context.Context, input WarehousePermissionInput) error {
+	if input.WarehouseId <= 0 || input.UserId <= 0 || len(input.PermissionHandles) == 0 {
+		return common.NewBadRequestFromMessage("warehouse id, user id and permission handles are required")
+	}
+	return s.repo.AddWarehousePermissions(ctx, input)
+}
+
+func (s *WarehouseService) RemoveWarehousePermission(ctx context.Context, warehouseId, userId int, permissionHandle string) error {
+	return s.repo.RemoveWarehousePermission(ctx, warehouseId, userId, permissionHandle)
+}
@@ -24,6 +24,53 @@ func ValidateWarehouse(warehouse Warehouse) error {
 	return nil
 }
 
+func ValidateWarehouseLocationInput(input WarehouseLocationInput) error {
+	validationResults := []common.ErrorDetails{
+		common.ValidateStringLength(input.Code, "code", 1, 32),
+	}
+	parentKind, ok := locationParentKinds[input.Kind]
+	switch {
+	case input.Kind != LocationKindZone && !ok:
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: "kind must be one of zone, aisle or bin",
+			Field:   "kind",
+		})
+	case input.Kind == LocationKindZone && input.ParentId != nil:
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: "a zone can not have a parent",
+			Field:   "parentId",
+		})
+	case ok && input.ParentId == nil:
+		validationResults = append(validationResults, common.ErrorDetails{
+			Message: "a " + input.Kind + " has to be placed in a " + parentKind,
+			Field:   "parentId",
+		})
+	}
+	if input.TemperatureClass != nil {
+		validationResults = append(validationResults, ValidateTemperatureClass(*input.TemperatureClass, "temperatureClass"))
+	}
+	errors := make([]common.ErrorDetails, 0)
+	for _, result := range validationResults {
+		if len(result.Message) > 0 {
+			errors = append(errors, result)
+		}
+	}
+	if len(errors) > 0 {
+		return common.NewValidationError("invalid location input", errors...)
+	}
+	return nil
+}
+
+func ValidateTemperatureClass(value string, field string) common.ErrorDetails {
+	if !IsTemperatureClass(value) {
+		return common.ErrorDetails{
+			Message: field + " must be one of ambient, chilled or frozen",
+			Field:   field,
+		}
+	}
+	return common.ErrorDetails{}
+}
+
 func ValidateName(firstName string) common.ErrorDetails {
 	if !isNameValid(firstName) {
 		return common.ErrorDetails{
@@ -23,3 +23,12 @@ func GetErrorCodeFromError(err error) string {
 		return common.UNKNOWN_ERROR_CODE
 	}
 }
+
+// IsDuplicateError is true for unique violations, also after they were mapped to an api error
+func IsDuplicateError(err error) bool {
+	var apiErr *common.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == duplicateErrorCode
+	}
+	return GetErrorCodeFromError(err) == duplicateErrorCode
+}